@@ -8,6 +8,7 @@ import (
 	"boonkosang/internal/usecase"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"strconv"
 	"time"
@@ -49,6 +50,7 @@ func main() {
 	userRepo := postgres.NewUserRepository(db)
 	jwtSecret := getEnv("JWT_SECRET", "your_default_secret")
 	jwtExpiration := getEnvAsDuration("JWT_EXPIRATION", 15*time.Minute)
+	app.Use(rest.Authenticate(jwtSecret))
 	userUseCase := usecase.NewUserUsecase(userRepo, jwtSecret, jwtExpiration)
 	UserHandler := rest.NewUserHandler(userUseCase)
 	UserHandler.UserRoutes(app)
@@ -78,7 +80,17 @@ func main() {
 	JobHandler := rest.NewJobHandler(jobUseCase)
 	JobHandler.JobRoutes(app)
 
-	boqRepo := postgres.NewBOQRepository(db)
+	boqRepo := postgres.NewBOQRepository(db,
+		postgres.WithMaxJobsPerBOQ(getEnvAsInt("BOQ_MAX_JOBS", postgres.DefaultMaxJobsPerBOQ)),
+		postgres.WithLogger(slog.Default()),
+	)
+	app.Get("/healthz", func(c *fiber.Ctx) error {
+		if err := boqRepo.Ping(c.Context()); err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).SendString(err.Error())
+		}
+		return c.SendString("OK")
+	})
+
 	boqUseCase := usecase.NewBOQUsecase(boqRepo, projectRepo)
 	BOQHandler := rest.NewBOQHandler(boqUseCase)
 	BOQHandler.BOQRoutes(app)
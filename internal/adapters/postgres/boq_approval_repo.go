@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// SubmitForApproval starts an approval chain for a draft BOQ. Approvers act
+// in the order given; any previous chain for the BOQ is replaced.
+func (r *boqRepository) SubmitForApproval(ctx context.Context, boqID uuid.UUID, req requests.SubmitBOQApprovalRequest) error {
+	if len(req.ApproverIDs) == 0 {
+		return fmt.Errorf("%w: at least one approver is required", repositories.ErrInvalidInput)
+	}
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM boq_approval_request WHERE boq_id = $1`, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to clear previous approval requests: %w", err)
+		}
+
+		query := `
+        INSERT INTO boq_approval_request (approval_id, boq_id, approver_id, step, status)
+        VALUES ($1, $2, $3, $4, $5)`
+
+		for i, approverID := range req.ApproverIDs {
+			_, err = tx.ExecContext(ctx, query, uuid.New(), boqID, approverID, i+1, models.BOQApprovalPending)
+			if err != nil {
+				return fmt.Errorf("failed to create approval request: %w", err)
+			}
+		}
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionSubmit, map[string][]uuid.UUID{"approver_ids": req.ApproverIDs}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// DecideApproval records the approver's decision on the step currently
+// awaiting them. A rejection stops the chain.
+func (r *boqRepository) DecideApproval(ctx context.Context, boqID uuid.UUID, userID uuid.UUID, req requests.BOQApprovalDecisionRequest) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var current models.BOQApprovalRequest
+		currentQuery := `
+        SELECT * FROM boq_approval_request
+        WHERE boq_id = $1 AND status = $2
+        ORDER BY step
+        LIMIT 1
+        FOR UPDATE`
+
+		err := tx.GetContext(ctx, &current, currentQuery, boqID, models.BOQApprovalPending)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return repositories.ErrNoPendingApproval
+			}
+			return fmt.Errorf("failed to get pending approval: %w", err)
+		}
+
+		if current.ApproverID != userID {
+			return repositories.ErrNotAwaitingApprover
+		}
+
+		var rejected int
+		rejectedQuery := `SELECT COUNT(*) FROM boq_approval_request WHERE boq_id = $1 AND status = $2`
+		err = tx.GetContext(ctx, &rejected, rejectedQuery, boqID, models.BOQApprovalRejected)
+		if err != nil {
+			return fmt.Errorf("failed to check approval chain: %w", err)
+		}
+
+		if rejected > 0 {
+			return repositories.ErrApprovalRejected
+		}
+
+		decision := models.BOQApprovalRejected
+		if req.Approved {
+			decision = models.BOQApprovalApproved
+		}
+
+		updateQuery := `
+        UPDATE boq_approval_request
+        SET status = $1, comment = NULLIF($2, ''), decided_at = CURRENT_TIMESTAMP
+        WHERE approval_id = $3`
+
+		_, err = tx.ExecContext(ctx, updateQuery, decision, req.Comment, current.ApprovalID)
+		if err != nil {
+			return fmt.Errorf("failed to record approval decision: %w", err)
+		}
+
+		diff := map[string]interface{}{"step": current.Step, "decision": decision, "comment": req.Comment}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionDecision, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetPendingApprovalsForUser returns the draft BOQs whose current approval
+// step is assigned to the user, oldest submission first.
+func (r *boqRepository) GetPendingApprovalsForUser(ctx context.Context, userID uuid.UUID) ([]responses.PendingApprovalResponse, error) {
+	query := `
+        WITH current_step AS (
+            SELECT DISTINCT ON (boq_id) *
+            FROM boq_approval_request
+            WHERE status = 'pending'
+            ORDER BY boq_id, step
+        )
+        SELECT
+            cs.approval_id,
+            b.boq_id,
+            p.project_id,
+            p.name as project_name,
+            COALESCE(b.total_cost, 0) as total_cost,
+            cs.step,
+            cs.submitted_at
+        FROM current_step cs
+        JOIN boq b ON b.boq_id = cs.boq_id
+        JOIN project p ON p.project_id = b.project_id
+        WHERE cs.approver_id = $1
+        AND b.status = 'draft'
+        AND NOT EXISTS (
+            SELECT 1 FROM boq_approval_request rejected
+            WHERE rejected.boq_id = cs.boq_id AND rejected.status = 'rejected'
+        )
+        ORDER BY cs.submitted_at, b.boq_id`
+
+	pending := []responses.PendingApprovalResponse{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &pending, query, userID); err != nil {
+			return fmt.Errorf("failed to get pending approvals: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
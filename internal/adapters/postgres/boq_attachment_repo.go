@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func (r *boqRepository) AddBOQAttachment(ctx context.Context, boqID uuid.UUID, req requests.BOQAttachmentRequest) (*responses.BOQAttachmentResponse, error) {
+	if !req.Type.IsValid() {
+		return nil, fmt.Errorf("%w: invalid attachment type: %s", repositories.ErrInvalidInput, req.Type)
+	}
+	if strings.TrimSpace(req.Reference) == "" {
+		return nil, fmt.Errorf("%w: attachment reference is required", repositories.ErrInvalidInput)
+	}
+
+	attachment := models.BOQAttachment{
+		AttachmentID:   uuid.New(),
+		BOQID:          boqID,
+		AttachmentType: req.Type,
+		Reference:      strings.TrimSpace(req.Reference),
+		CreatedAt:      time.Now(),
+	}
+
+	query := `
+        INSERT INTO boq_attachment (
+            attachment_id, boq_id, job_id, attachment_type, reference, created_at
+        ) VALUES (
+            :attachment_id, :boq_id, :job_id, :attachment_type, :reference, :created_at
+        )`
+
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if req.JobID != nil {
+			var exists bool
+			checkJobQuery := `
+            SELECT EXISTS (
+                SELECT 1 FROM boq_job
+                WHERE boq_id = $1 AND job_id = $2 AND deleted_at IS NULL
+            )`
+			err := tx.GetContext(ctx, &exists, checkJobQuery, boqID, *req.JobID)
+			if err != nil {
+				return fmt.Errorf("failed to check job existence: %w", err)
+			}
+			if !exists {
+				return repositories.ErrJobNotInBOQ
+			}
+			attachment.JobID = uuid.NullUUID{UUID: *req.JobID, Valid: true}
+		}
+
+		_, err := tx.NamedExecContext(ctx, query, attachment)
+		if err != nil {
+			return fmt.Errorf("failed to add BOQ attachment: %w", err)
+		}
+
+		diff := map[string]interface{}{"attachment_id": attachment.AttachmentID, "type": attachment.AttachmentType, "reference": attachment.Reference}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionAddAttachment, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toBOQAttachmentResponse(attachment)
+	return &response, nil
+}
+
+func (r *boqRepository) RemoveBOQAttachment(ctx context.Context, boqID uuid.UUID, attachmentID uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+        DELETE FROM boq_attachment
+        WHERE boq_id = $1 AND attachment_id = $2`
+
+		result, err := tx.ExecContext(ctx, query, boqID, attachmentID)
+		if err != nil {
+			return fmt.Errorf("failed to remove BOQ attachment: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return repositories.ErrAttachmentNotFound
+		}
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionDelAttachment, map[string]uuid.UUID{"attachment_id": attachmentID}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *boqRepository) ListBOQAttachments(ctx context.Context, boqID uuid.UUID) ([]responses.BOQAttachmentResponse, error) {
+	var attachments []responses.BOQAttachmentResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		attachments, err = r.listBOQAttachments(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *boqRepository) listBOQAttachments(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) ([]responses.BOQAttachmentResponse, error) {
+	query := `
+        SELECT attachment_id, boq_id, job_id, attachment_type, reference, created_at
+        FROM boq_attachment
+        WHERE boq_id = $1
+        ORDER BY created_at`
+
+	var attachments []models.BOQAttachment
+	err := sqlx.SelectContext(ctx, q, &attachments, query, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list BOQ attachments: %w", err)
+	}
+
+	result := make([]responses.BOQAttachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		result[i] = toBOQAttachmentResponse(attachment)
+	}
+
+	return result, nil
+}
+
+func toBOQAttachmentResponse(attachment models.BOQAttachment) responses.BOQAttachmentResponse {
+	response := responses.BOQAttachmentResponse{
+		AttachmentID: attachment.AttachmentID,
+		BOQID:        attachment.BOQID,
+		Type:         attachment.AttachmentType,
+		Reference:    attachment.Reference,
+		CreatedAt:    attachment.CreatedAt,
+	}
+	if attachment.JobID.Valid {
+		jobID := attachment.JobID.UUID
+		response.JobID = &jobID
+	}
+	return response
+}
+
+// groupBOQAttachments splits attachments into BOQ-level references and
+// references scoped to a single job.
+func groupBOQAttachments(attachments []responses.BOQAttachmentResponse) responses.BOQAttachments {
+	groups := responses.BOQAttachments{
+		BOQ:  []responses.BOQAttachmentResponse{},
+		Jobs: map[uuid.UUID][]responses.BOQAttachmentResponse{},
+	}
+	for _, attachment := range attachments {
+		if attachment.JobID == nil {
+			groups.BOQ = append(groups.BOQ, attachment)
+			continue
+		}
+		groups.Jobs[*attachment.JobID] = append(groups.Jobs[*attachment.JobID], attachment)
+	}
+	return groups
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/responses"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	auditActionCleanPriceLogs = "clean_price_logs"
+	auditActionApprove        = "approve"
+	auditActionClone          = "clone"
+	auditActionStatusChange   = "status_change"
+	auditActionLock           = "lock"
+	auditActionMoveJob        = "move_job"
+	auditActionCreate         = "create"
+	auditActionAddJob         = "add_job"
+	auditActionUpdateJob      = "update_job"
+	auditActionDeleteJob      = "delete_job"
+	auditActionClearJobs      = "clear_jobs"
+	auditActionRestoreJob     = "restore_job"
+	auditActionPriceUpdate    = "price_update"
+	auditActionRoundRates     = "round_rates"
+	auditActionGeneralCost    = "general_cost_update"
+	auditActionPreliminaries  = "preliminaries_update"
+	auditActionSubmit         = "submit_for_approval"
+	auditActionDecision       = "approval_decision"
+	auditActionAddAttachment  = "add_attachment"
+	auditActionDelAttachment  = "remove_attachment"
+	auditActionAddMilestone   = "add_milestone"
+	auditActionDelMilestone   = "remove_milestone"
+)
+
+// writeBOQAudit records a mutation of the BOQ. It takes the caller's
+// transaction so the audit row commits or rolls back with the change.
+func writeBOQAudit(ctx context.Context, tx *sqlx.Tx, boqID uuid.UUID, action string, diff interface{}) error {
+	payload, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit diff: %w", err)
+	}
+
+	var actor uuid.NullUUID
+	if userID, ok := repositories.ActorFromContext(ctx); ok {
+		actor = uuid.NullUUID{UUID: userID, Valid: true}
+	}
+
+	query := `
+        INSERT INTO boq_audit (audit_id, boq_id, action, actor_id, diff)
+        VALUES ($1, $2, $3, $4, $5)`
+
+	_, err = tx.ExecContext(ctx, query, uuid.New(), boqID, action, actor, payload)
+	if err != nil {
+		return fmt.Errorf("failed to write BOQ audit: %w", err)
+	}
+
+	return nil
+}
+
+// GetBOQAudit returns the audit trail of a BOQ, oldest entry first.
+func (r *boqRepository) GetBOQAudit(ctx context.Context, boqID uuid.UUID) ([]responses.BOQAuditEntry, error) {
+	query := `
+        SELECT audit_id, action, actor_id, diff, created_at
+        FROM boq_audit
+        WHERE boq_id = $1
+        ORDER BY created_at, audit_id`
+
+	entries := []responses.BOQAuditEntry{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if err := tx.SelectContext(ctx, &entries, query, boqID); err != nil {
+			return fmt.Errorf("failed to get BOQ audit: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
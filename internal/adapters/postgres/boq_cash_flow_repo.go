@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func (r *boqRepository) SetBOQJobSchedule(ctx context.Context, boqID uuid.UUID, req requests.BOQJobScheduleRequest) error {
+	if req.StartOffsetDays < 0 {
+		return fmt.Errorf("%w: start offset cannot be negative", repositories.ErrInvalidInput)
+	}
+	if req.DurationDays <= 0 {
+		return fmt.Errorf("%w: duration must be a positive number of days", repositories.ErrInvalidInput)
+	}
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+        UPDATE boq_job
+        SET start_offset_days = $1, duration_days = $2
+        WHERE boq_id = $3 AND job_id = $4 AND deleted_at IS NULL`
+
+		result, err := tx.ExecContext(ctx, query, req.StartOffsetDays, req.DurationDays, boqID, req.JobID)
+		if err != nil {
+			return fmt.Errorf("failed to update job schedule: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return repositories.ErrJobNotInBOQ
+		}
+
+		if err := touchBOQ(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		diff := map[string]interface{}{"job_id": req.JobID, "start_offset_days": req.StartOffsetDays, "duration_days": req.DurationDays}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionUpdateJob, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetBOQCashFlowCurve spreads each job's cost evenly over its planned days and
+// buckets the spend by calendar month from req.StartDate. Unscheduled jobs are
+// either spread over the whole scheduled span or lumped on the first day.
+func (r *boqRepository) GetBOQCashFlowCurve(ctx context.Context, boqID uuid.UUID, req requests.CashFlowCurveRequest) (*responses.CashFlowCurveResponse, error) {
+	mode := req.UnscheduledMode
+	if mode == "" {
+		mode = models.CashFlowUnscheduledSpread
+	}
+	if mode != models.CashFlowUnscheduledSpread && mode != models.CashFlowUnscheduledLump {
+		return nil, fmt.Errorf("%w: unknown unscheduled mode %q", repositories.ErrInvalidInput, mode)
+	}
+	if req.StartDate.IsZero() {
+		return nil, fmt.Errorf("%w: start date is required", repositories.ErrInvalidInput)
+	}
+
+	var result *responses.CashFlowCurveResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.getBOQCashFlowCurve(ctx, tx, boqID, req, mode)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) getBOQCashFlowCurve(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID, req requests.CashFlowCurveRequest, mode models.CashFlowUnscheduledMode) (*responses.CashFlowCurveResponse, error) {
+	if _, err := getBOQByID(ctx, q, boqID); err != nil {
+		return nil, err
+	}
+
+	costs, err := r.getBOQJobCosts(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	type JobSchedule struct {
+		JobID           uuid.UUID     `db:"job_id"`
+		StartOffsetDays sql.NullInt32 `db:"start_offset_days"`
+		DurationDays    sql.NullInt32 `db:"duration_days"`
+	}
+
+	scheduleQuery := `
+        SELECT job_id, start_offset_days, duration_days
+        FROM boq_job
+        WHERE boq_id = $1 AND deleted_at IS NULL`
+
+	var schedules []JobSchedule
+	err = sqlx.SelectContext(ctx, q, &schedules, scheduleQuery, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job schedules: %w", err)
+	}
+
+	scheduleByJob := make(map[uuid.UUID]JobSchedule, len(schedules))
+	projectDays := 1
+	for _, schedule := range schedules {
+		if !schedule.DurationDays.Valid || schedule.DurationDays.Int32 <= 0 {
+			continue
+		}
+		scheduleByJob[schedule.JobID] = schedule
+		end := int(schedule.StartOffsetDays.Int32 + schedule.DurationDays.Int32)
+		if end > projectDays {
+			projectDays = end
+		}
+	}
+
+	curve := &responses.CashFlowCurveResponse{
+		BOQID:           boqID,
+		StartDate:       req.StartDate,
+		UnscheduledMode: mode,
+		Points:          []responses.CashFlowPointResponse{},
+	}
+
+	spendByMonth := map[string]float64{}
+	addSpend := func(offset, duration int, amount float64) {
+		daily := amount / float64(duration)
+		for day := offset; day < offset+duration; day++ {
+			month := req.StartDate.AddDate(0, 0, day).Format("2006-01")
+			spendByMonth[month] += daily
+		}
+	}
+
+	for _, cost := range costs {
+		curve.TotalCost += cost.Total()
+		total := cost.Total().Float64()
+
+		schedule, ok := scheduleByJob[cost.JobID]
+		switch {
+		case ok:
+			addSpend(int(schedule.StartOffsetDays.Int32), int(schedule.DurationDays.Int32), total)
+		case mode == models.CashFlowUnscheduledLump:
+			curve.UnscheduledJobs++
+			addSpend(0, 1, total)
+		default:
+			curve.UnscheduledJobs++
+			addSpend(0, projectDays, total)
+		}
+	}
+
+	months := make([]string, 0, len(spendByMonth))
+	for month := range spendByMonth {
+		months = append(months, month)
+	}
+	sort.Strings(months)
+
+	// Daily spend is spread in floats; each month's spend is the step in the
+	// rounded running total, so the points add up to TotalCost exactly.
+	var cumulative float64
+	var previous models.Money
+	for _, month := range months {
+		cumulative += spendByMonth[month]
+		running := models.MoneyFromFloat(cumulative)
+		curve.Points = append(curve.Points, responses.CashFlowPointResponse{
+			Month:           month,
+			Spend:           running - previous,
+			CumulativeSpend: running,
+		})
+		previous = running
+	}
+
+	return curve, nil
+}
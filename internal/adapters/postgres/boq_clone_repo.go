@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// CloneBOQScaled copies the source BOQ into a new draft BOQ for the target
+// project with every job quantity multiplied by factor. Price-log quantities
+// are per unit of job, so material totals scale with the job. When
+// resetPrices is set the copied price logs start unpriced.
+func (r *boqRepository) CloneBOQScaled(ctx context.Context, sourceBOQID, targetProjectID uuid.UUID, factor float64, resetPrices bool) (uuid.UUID, error) {
+	if factor <= 0 {
+		return uuid.Nil, fmt.Errorf("%w: scale factor must be positive", repositories.ErrInvalidInput)
+	}
+
+	var result uuid.UUID
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		targetBOQID, err := r.cloneBOQ(ctx, tx, sourceBOQID, targetProjectID, factor, resetPrices, false)
+		if err != nil {
+			return err
+		}
+
+		result = targetBOQID
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return result, nil
+}
+
+// CloneBOQ copies the source BOQ's jobs and price logs into a new draft BOQ
+// for the target project. A target project that already has a non-empty BOQ
+// is refused unless overwrite is set, in which case that BOQ's jobs and price
+// logs are replaced and it goes back to draft.
+func (r *boqRepository) CloneBOQ(ctx context.Context, sourceBOQID, targetProjectID uuid.UUID, overwrite bool) (uuid.UUID, error) {
+	var result uuid.UUID
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		targetBOQID, err := r.cloneBOQ(ctx, tx, sourceBOQID, targetProjectID, 1, false, overwrite)
+		if err != nil {
+			return err
+		}
+
+		result = targetBOQID
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return result, nil
+}
+
+// cloneBOQ creates the target project's draft BOQ, or reuses it when it is
+// an empty draft or overwrite is set, and copies the source jobs and price
+// logs into it. The copy always starts in draft.
+func (r *boqRepository) cloneBOQ(ctx context.Context, tx *sqlx.Tx, sourceBOQID, targetProjectID uuid.UUID, factor float64, resetPrices, overwrite bool) (uuid.UUID, error) {
+	type SourceBOQ struct {
+		SellingGeneralCost models.NullMoney `db:"selling_general_cost"`
+		Currency           string           `db:"currency"`
+	}
+
+	var source SourceBOQ
+	sourceQuery := `SELECT selling_general_cost, currency FROM boq WHERE boq_id = $1`
+	err := tx.GetContext(ctx, &source, sourceQuery, sourceBOQID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, fmt.Errorf("source %w", repositories.ErrBOQNotFound)
+		}
+		return uuid.Nil, fmt.Errorf("failed to get source BOQ: %w", err)
+	}
+
+	var projectExists bool
+	err = tx.GetContext(ctx, &projectExists, `SELECT EXISTS (SELECT 1 FROM project WHERE project_id = $1)`, targetProjectID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to check target project: %w", err)
+	}
+	if !projectExists {
+		return uuid.Nil, fmt.Errorf("target %w", repositories.ErrProjectNotFound)
+	}
+
+	type TargetBOQ struct {
+		BOQID    uuid.UUID `db:"boq_id"`
+		Status   string    `db:"status"`
+		JobCount int       `db:"job_count"`
+	}
+
+	var target TargetBOQ
+	targetQuery := `
+        SELECT b.boq_id, b.status, (SELECT COUNT(*) FROM boq_job bj WHERE bj.boq_id = b.boq_id) as job_count
+        FROM boq b
+        WHERE b.project_id = $1
+        FOR UPDATE`
+
+	err = tx.GetContext(ctx, &target, targetQuery, targetProjectID)
+	switch {
+	case err == sql.ErrNoRows:
+		if err := checkProjectAcceptsBOQ(ctx, tx, targetProjectID); err != nil {
+			return uuid.Nil, err
+		}
+
+		createQuery := `
+            INSERT INTO boq (project_id, status, selling_general_cost, currency)
+            VALUES ($1, 'draft', $2, $3)
+            RETURNING boq_id`
+
+		err = tx.GetContext(ctx, &target.BOQID, createQuery, targetProjectID, source.SellingGeneralCost, source.Currency)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to create target BOQ: %w", err)
+		}
+	case err != nil:
+		return uuid.Nil, fmt.Errorf("failed to get target BOQ: %w", err)
+	case target.BOQID == sourceBOQID:
+		return uuid.Nil, fmt.Errorf("%w: cannot clone a BOQ into itself", repositories.ErrInvalidInput)
+	case target.Status == string(models.BOQStatusLocked):
+		return uuid.Nil, fmt.Errorf("target project's %w", repositories.ErrBOQLocked)
+	case (target.Status != "draft" || target.JobCount > 0) && !overwrite:
+		return uuid.Nil, fmt.Errorf("target %w", repositories.ErrBOQExists)
+	default:
+		if overwrite {
+			// Replace the target's lines outright; the soft-deleted ones too,
+			// so the copied jobs do not collide with them.
+			_, err = tx.ExecContext(ctx, `DELETE FROM material_price_log WHERE boq_id = $1`, target.BOQID)
+			if err != nil {
+				return uuid.Nil, fmt.Errorf("failed to clear target price logs: %w", err)
+			}
+
+			_, err = tx.ExecContext(ctx, `DELETE FROM boq_job WHERE boq_id = $1`, target.BOQID)
+			if err != nil {
+				return uuid.Nil, fmt.Errorf("failed to clear target BOQ jobs: %w", err)
+			}
+		}
+
+		updateQuery := `
+            UPDATE boq
+            SET status = 'draft', selling_general_cost = $1, currency = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP
+            WHERE boq_id = $3`
+		_, err = tx.ExecContext(ctx, updateQuery, source.SellingGeneralCost, source.Currency, target.BOQID)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to update target BOQ: %w", err)
+		}
+	}
+
+	copyJobsQuery := `
+        INSERT INTO boq_job (boq_id, job_id, quantity, labor_cost, selling_price, is_provisional)
+        SELECT $1, job_id, quantity * $3, labor_cost, selling_price, is_provisional
+        FROM boq_job
+        WHERE boq_id = $2 AND deleted_at IS NULL`
+
+	_, err = tx.ExecContext(ctx, copyJobsQuery, target.BOQID, sourceBOQID, factor)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to copy BOQ jobs: %w", err)
+	}
+
+	copyPriceLogsQuery := `
+        INSERT INTO material_price_log (
+            material_id, boq_id, job_id, quantity, estimated_price, actual_price, supplier_id, currency, fx_rate, fx_applied, updated_at
+        )
+        SELECT
+            material_id, $1, job_id, quantity,
+            CASE WHEN $3 THEN NULL ELSE estimated_price END,
+            CASE WHEN $3 THEN NULL ELSE actual_price END,
+            supplier_id, currency, fx_rate, fx_applied, CURRENT_TIMESTAMP
+        FROM material_price_log mpl
+        WHERE boq_id = $2
+        AND EXISTS (
+            SELECT 1 FROM boq_job bj
+            WHERE bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+        )`
+
+	_, err = tx.ExecContext(ctx, copyPriceLogsQuery, target.BOQID, sourceBOQID, resetPrices)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to copy material price logs: %w", err)
+	}
+
+	diff := map[string]interface{}{
+		"source_boq_id": sourceBOQID,
+		"factor":        factor,
+		"reset_prices":  resetPrices,
+		"overwrite":     overwrite,
+	}
+	if err := writeBOQAudit(ctx, tx, target.BOQID, auditActionClone, diff); err != nil {
+		return uuid.Nil, err
+	}
+
+	return target.BOQID, nil
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/responses"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// CompareBOQs diffs the live jobs of two BOQs, matched on job_id: jobs only
+// in A, jobs only in B, and jobs in both whose quantity or labor cost
+// differ. TotalDelta is B's direct-works total minus A's.
+func (r *boqRepository) CompareBOQs(ctx context.Context, aID, bID uuid.UUID) (*responses.BOQDiffResponse, error) {
+	var result *responses.BOQDiffResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.compareBOQs(ctx, tx, aID, bID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) compareBOQs(ctx context.Context, q sqlx.QueryerContext, aID, bID uuid.UUID) (*responses.BOQDiffResponse, error) {
+	for _, boqID := range []uuid.UUID{aID, bID} {
+		if _, err := getBOQByID(ctx, q, boqID); err != nil {
+			return nil, err
+		}
+	}
+
+	aCosts, err := r.getBOQJobCosts(ctx, q, aID)
+	if err != nil {
+		return nil, err
+	}
+
+	bCosts, err := r.getBOQJobCosts(ctx, q, bID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildBOQDiff(aID, bID, aCosts, bCosts), nil
+}
+
+func buildBOQDiff(aID, bID uuid.UUID, aCosts, bCosts []boqJobCost) *responses.BOQDiffResponse {
+	diff := &responses.BOQDiffResponse{
+		BOQAID:  aID,
+		BOQBID:  bID,
+		OnlyInA: []responses.BOQDiffJobResponse{},
+		OnlyInB: []responses.BOQDiffJobResponse{},
+		Changed: []responses.BOQJobChangeResponse{},
+	}
+
+	bByJob := make(map[uuid.UUID]boqJobCost, len(bCosts))
+	for _, cost := range bCosts {
+		bByJob[cost.JobID] = cost
+	}
+
+	var delta models.Money
+	inA := make(map[uuid.UUID]bool, len(aCosts))
+	for _, a := range aCosts {
+		inA[a.JobID] = true
+		delta -= a.Total()
+
+		b, ok := bByJob[a.JobID]
+		if !ok {
+			diff.OnlyInA = append(diff.OnlyInA, boqDiffJob(a))
+			continue
+		}
+		if a.Quantity == b.Quantity && a.LaborCost == b.LaborCost {
+			continue
+		}
+
+		diff.Changed = append(diff.Changed, responses.BOQJobChangeResponse{
+			JobID:        a.JobID,
+			Name:         a.Name,
+			OldQuantity:  a.Quantity.Float64,
+			NewQuantity:  b.Quantity.Float64,
+			OldLaborCost: a.LaborCost.Money,
+			NewLaborCost: b.LaborCost.Money,
+			TotalDelta:   b.Total() - a.Total(),
+		})
+	}
+
+	for _, b := range bCosts {
+		delta += b.Total()
+		if !inA[b.JobID] {
+			diff.OnlyInB = append(diff.OnlyInB, boqDiffJob(b))
+		}
+	}
+	diff.TotalDelta = delta
+
+	return diff
+}
+
+func boqDiffJob(cost boqJobCost) responses.BOQDiffJobResponse {
+	return responses.BOQDiffJobResponse{
+		JobID:     cost.JobID,
+		Name:      cost.Name,
+		Quantity:  cost.Quantity.Float64,
+		LaborCost: cost.LaborCost.Money,
+		Total:     cost.Total(),
+	}
+}
@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func normalizeCurrency(currency string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	if !currencyCodePattern.MatchString(code) {
+		return "", fmt.Errorf("%w: invalid currency code %q", repositories.ErrInvalidInput, currency)
+	}
+	return code, nil
+}
+
+// SetMaterialPriceCurrency records the currency a material was priced in and
+// the rate used to convert it into the BOQ currency.
+func (r *boqRepository) SetMaterialPriceCurrency(ctx context.Context, boqID uuid.UUID, req requests.MaterialPriceCurrencyRequest) error {
+	currency, err := normalizeCurrency(req.Currency)
+	if err != nil {
+		return err
+	}
+	if req.FxRate <= 0 {
+		return fmt.Errorf("%w: fx rate must be a positive number", repositories.ErrInvalidInput)
+	}
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		query := `
+        UPDATE material_price_log
+        SET currency = $1, fx_rate = $2, fx_applied = TRUE, updated_at = CURRENT_TIMESTAMP
+        WHERE boq_id = $3 AND material_id = $4`
+
+		result, err := tx.ExecContext(ctx, query, currency, req.FxRate, boqID, req.MaterialID)
+		if err != nil {
+			return fmt.Errorf("failed to update material price currency: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return repositories.ErrNoPriceLogs
+		}
+
+		if err := touchBOQ(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		diff := map[string]interface{}{"material_id": req.MaterialID, "currency": currency, "fx_rate": req.FxRate}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionPriceUpdate, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetFXSensitivity recomputes the BOQ direct cost with the supplied
+// currency-to-BOQ-currency rates without persisting anything. Currencies not
+// present in rates keep the rate they were priced at.
+func (r *boqRepository) GetFXSensitivity(ctx context.Context, boqID uuid.UUID, rates map[string]float64) (*responses.FXSensitivityResponse, error) {
+	normalizedRates := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		code, err := normalizeCurrency(currency)
+		if err != nil {
+			return nil, err
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("%w: rate for %s must be a positive number", repositories.ErrInvalidInput, code)
+		}
+		normalizedRates[code] = rate
+	}
+
+	var result *responses.FXSensitivityResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.getFXSensitivity(ctx, tx, boqID, normalizedRates)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) getFXSensitivity(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID, normalizedRates map[string]float64) (*responses.FXSensitivityResponse, error) {
+	boq, err := getBOQByID(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	costs, err := r.getBOQJobCosts(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	type CurrencyCost struct {
+		Currency    string       `db:"currency"`
+		FxRate      float64      `db:"fx_rate"`
+		ForeignCost models.Money `db:"foreign_cost"`
+	}
+
+	query := `
+        SELECT
+            mpl.currency,
+            mpl.fx_rate,
+            COALESCE(SUM(COALESCE(mpl.estimated_price, 0) * COALESCE(mpl.quantity, 0) * COALESCE(bj.quantity, 0)), 0) as foreign_cost
+        FROM material_price_log mpl
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+        WHERE mpl.boq_id = $1
+        GROUP BY mpl.currency, mpl.fx_rate`
+
+	var currencyCosts []CurrencyCost
+	err = sqlx.SelectContext(ctx, q, &currencyCosts, query, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get material costs by currency: %w", err)
+	}
+
+	result := &responses.FXSensitivityResponse{
+		BOQID:        boqID,
+		BaseCurrency: boq.Currency,
+		Currencies:   []responses.FXCurrencyImpactResponse{},
+	}
+	for _, cost := range costs {
+		result.CurrentTotal += cost.Total()
+	}
+
+	impacts := map[string]*responses.FXCurrencyImpactResponse{}
+	for _, cost := range currencyCosts {
+		impact, ok := impacts[cost.Currency]
+		if !ok {
+			impact = &responses.FXCurrencyImpactResponse{Currency: cost.Currency}
+			impacts[cost.Currency] = impact
+		}
+
+		rate := cost.FxRate
+		if newRate, ok := normalizedRates[cost.Currency]; ok && cost.Currency != boq.Currency {
+			rate = newRate
+		}
+
+		impact.ForeignCost += cost.ForeignCost
+		impact.CurrentCost += cost.ForeignCost.MulQuantity(cost.FxRate)
+		impact.ProjectedRate = rate
+		impact.ProjectedCost += cost.ForeignCost.MulQuantity(rate)
+	}
+
+	for _, impact := range impacts {
+		impact.Delta = impact.ProjectedCost - impact.CurrentCost
+		result.Delta += impact.Delta
+		result.Currencies = append(result.Currencies, *impact)
+	}
+	sort.Slice(result.Currencies, func(i, j int) bool {
+		return result.Currencies[i].Currency < result.Currencies[j].Currency
+	})
+
+	result.ProjectedTotal = result.CurrentTotal + result.Delta
+
+	return result, nil
+}
+
+// GetMixedCurrencyLines returns the price-log rows priced in a currency other
+// than the BOQ's. Rows whose fx rate was never set are not converted and
+// block approval.
+func (r *boqRepository) GetMixedCurrencyLines(ctx context.Context, boqID uuid.UUID) ([]responses.MixedCurrencyLineResponse, error) {
+	var result []responses.MixedCurrencyLineResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = r.findMixedCurrencyLines(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) findMixedCurrencyLines(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) ([]responses.MixedCurrencyLineResponse, error) {
+	query := `
+        SELECT
+            mpl.mpl_id,
+            mpl.job_id,
+            j.name as job_name,
+            mpl.material_id,
+            m.name as material_name,
+            mpl.currency,
+            b.currency as boq_currency,
+            mpl.fx_rate,
+            mpl.fx_applied as converted
+        FROM material_price_log mpl
+        JOIN boq b ON b.boq_id = mpl.boq_id
+        JOIN job j ON j.job_id = mpl.job_id
+        JOIN material m ON m.material_id = mpl.material_id
+        WHERE mpl.boq_id = $1
+        AND mpl.currency <> b.currency
+        ORDER BY mpl.fx_applied, j.name, m.name`
+
+	lines := []responses.MixedCurrencyLineResponse{}
+	err := sqlx.SelectContext(ctx, q, &lines, query, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mixed currency lines: %w", err)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// exportFlushRows is how many job rows ExportBOQStream writes between flushes.
+const exportFlushRows = 100
+
+// ExportBOQ renders the BOQ as CSV with one row per job followed by the
+// preliminaries, general cost and grand total rows. Prices and totals are
+// those of GetBOQSummary, so an approved BOQ exports its approval prices.
+func (r *boqRepository) ExportBOQ(ctx context.Context, boqID uuid.UUID) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.ExportBOQStream(ctx, boqID, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExportBOQStream writes the same CSV as ExportBOQ to w, reading the jobs one
+// row at a time so large BOQs are never held in memory. Nothing is written
+// when the BOQ does not exist. The rows and the read transaction are closed
+// however the export ends, including when w fails midway.
+func (r *boqRepository) ExportBOQStream(ctx context.Context, boqID uuid.UUID, w io.Writer) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		preliminaries, err := r.getPreliminariesPercent(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+
+		generalCost, err := getGeneralCostTotal(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+
+		status, err := getBOQStatus(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+
+		prices, err := materialPriceSourceFor(ctx, tx, boqID, status)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryxContext(ctx, boqJobCostQueryFrom(prices, "bj.boq_id = $1"), boqID)
+		if err != nil {
+			return fmt.Errorf("failed to get BOQ job costs: %w", err)
+		}
+		defer rows.Close()
+
+		cw := csv.NewWriter(w)
+		flush := func() error {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return fmt.Errorf("failed to write BOQ CSV: %w", err)
+			}
+			return nil
+		}
+
+		if err := cw.Write([]string{"Job", "Unit", "Quantity", "Labor Cost", "Material Total", "Line Total"}); err != nil {
+			return fmt.Errorf("failed to write BOQ CSV: %w", err)
+		}
+
+		var directWorks models.Money
+		for written := 1; rows.Next(); written++ {
+			var cost boqJobCost
+			if err := rows.StructScan(&cost); err != nil {
+				return fmt.Errorf("failed to scan BOQ job cost: %w", err)
+			}
+
+			err := cw.Write([]string{
+				cost.Name,
+				cost.Unit,
+				strconv.FormatFloat(cost.Quantity.Float64, 'f', -1, 64),
+				formatCSVAmount(cost.LaborTotal()),
+				formatCSVAmount(cost.MaterialTotal()),
+				formatCSVAmount(cost.Total()),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to write BOQ CSV: %w", err)
+			}
+			directWorks += cost.Total()
+
+			if written%exportFlushRows == 0 {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to get BOQ job costs: %w", err)
+		}
+
+		err = cw.WriteAll([][]string{
+			{"Preliminaries", "%", strconv.FormatFloat(preliminaries, 'f', -1, 64), "", "", formatCSVAmount(directWorks.Percent(preliminaries))},
+			{"General Cost", "", "", "", "", formatCSVAmount(generalCost)},
+			{"Grand Total", "", "", "", "", formatCSVAmount(boqGrandTotal(directWorks, generalCost, preliminaries))},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write BOQ CSV: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func formatCSVAmount(amount models.Money) string {
+	return amount.String()
+}
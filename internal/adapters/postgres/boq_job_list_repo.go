@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// DefaultBOQJobsLimit is the page size used when ListBOQJobs is called
+	// without a limit.
+	DefaultBOQJobsLimit = 50
+	// MaxBOQJobsLimit caps the page size of ListBOQJobs.
+	MaxBOQJobsLimit = 200
+)
+
+// boqJobSortColumns maps the sort fields accepted by ListBOQJobs to columns.
+// Only these are interpolated into ORDER BY.
+var boqJobSortColumns = map[string]string{
+	"":           "j.name",
+	"name":       "j.name",
+	"quantity":   "bj.quantity",
+	"labor_cost": "bj.labor_cost",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListBOQJobs returns one page of the BOQ's jobs together with the number of
+// jobs matching the filter. GetBoqWithProject still returns every job.
+func (r *boqRepository) ListBOQJobs(ctx context.Context, boqID uuid.UUID, req requests.ListBOQJobsRequest) (*responses.BOQJobListResponse, error) {
+	sortColumn, ok := boqJobSortColumns[req.Sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid sort field %q", repositories.ErrInvalidInput, req.Sort)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultBOQJobsLimit
+	}
+	if limit > MaxBOQJobsLimit {
+		limit = MaxBOQJobsLimit
+	}
+
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	filter := `
+        FROM boq_job bj
+        JOIN job j ON j.job_id = bj.job_id
+        WHERE bj.boq_id = $1
+        AND bj.deleted_at IS NULL
+        AND ($2 = '' OR j.name ILIKE '%' || $2 || '%')`
+
+	name := likeEscaper.Replace(req.Name)
+
+	query := `
+        SELECT
+            j.job_id,
+            j.name,
+            COALESCE(j.description, '') as description,
+            j.unit,
+            bj.quantity,
+            COALESCE(bj.labor_cost, 0) as labor_cost` + filter + `
+        ORDER BY ` + sortColumn + `, j.job_id
+        LIMIT $3 OFFSET $4`
+
+	var total int64
+	jobs := []responses.JobResponse{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var exists bool
+		err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM boq WHERE boq_id = $1)`, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to get BOQ: %w", err)
+		}
+		if !exists {
+			return repositories.ErrBOQNotFound
+		}
+
+		err = tx.GetContext(ctx, &total, `SELECT COUNT(*) `+filter, boqID, name)
+		if err != nil {
+			return fmt.Errorf("failed to count BOQ jobs: %w", err)
+		}
+
+		err = tx.SelectContext(ctx, &jobs, query, boqID, name, limit, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list BOQ jobs: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses.BOQJobListResponse{
+		Jobs:   jobs,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/responses"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	lifecycleEventAudit    = "audit"
+	lifecycleEventSnapshot = "approval_snapshot"
+)
+
+// GetBOQLifecycle returns every recorded event of a BOQ in chronological
+// order: audited mutations and status changes together with the approval
+// snapshots, plus a check of the latest snapshot against the current lines.
+func (r *boqRepository) GetBOQLifecycle(ctx context.Context, boqID uuid.UUID) (*responses.BOQLifecycleResponse, error) {
+	var result *responses.BOQLifecycleResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.getBOQLifecycle(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) getBOQLifecycle(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (*responses.BOQLifecycleResponse, error) {
+	boq, err := getBOQByID(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	type auditRow struct {
+		Action    string          `db:"action"`
+		ActorID   uuid.NullUUID   `db:"actor_id"`
+		Diff      json.RawMessage `db:"diff"`
+		CreatedAt time.Time       `db:"created_at"`
+	}
+
+	var audits []auditRow
+	auditQuery := `
+        SELECT action, actor_id, diff, created_at
+        FROM boq_audit
+        WHERE boq_id = $1
+        ORDER BY created_at`
+
+	err = sqlx.SelectContext(ctx, q, &audits, auditQuery, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BOQ audit: %w", err)
+	}
+
+	type snapshotRow struct {
+		SnapshotID uuid.UUID    `db:"snapshot_id"`
+		TotalCost  models.Money `db:"total_cost"`
+		ApprovedAt time.Time    `db:"approved_at"`
+	}
+
+	var snapshots []snapshotRow
+	snapshotQuery := `
+        SELECT snapshot_id, total_cost, approved_at
+        FROM boq_cost_snapshot
+        WHERE boq_id = $1
+        ORDER BY approved_at`
+
+	err = sqlx.SelectContext(ctx, q, &snapshots, snapshotQuery, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BOQ cost snapshots: %w", err)
+	}
+
+	events := make([]responses.BOQLifecycleEventResponse, 0, len(audits)+len(snapshots))
+	for _, audit := range audits {
+		event := responses.BOQLifecycleEventResponse{
+			Type:       lifecycleEventAudit,
+			Action:     audit.Action,
+			Diff:       audit.Diff,
+			OccurredAt: audit.CreatedAt,
+		}
+		if audit.ActorID.Valid {
+			actorID := audit.ActorID.UUID
+			event.ActorID = &actorID
+		}
+		events = append(events, event)
+	}
+
+	for _, snapshot := range snapshots {
+		snapshotID := snapshot.SnapshotID
+		events = append(events, responses.BOQLifecycleEventResponse{
+			Type:       lifecycleEventSnapshot,
+			Action:     auditActionApprove,
+			SnapshotID: &snapshotID,
+			OccurredAt: snapshot.ApprovedAt,
+		})
+	}
+
+	// Stable so an approval audit row stays ahead of its snapshot when both
+	// carry the same transaction timestamp.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.Before(events[j].OccurredAt)
+	})
+
+	lifecycle := &responses.BOQLifecycleResponse{
+		BOQID:  boqID,
+		Status: boq.Status,
+		Events: events,
+	}
+
+	if len(snapshots) > 0 {
+		latest := snapshots[len(snapshots)-1]
+
+		composition, err := r.getBOQCostComposition(ctx, q, boqID)
+		if err != nil {
+			return nil, err
+		}
+
+		current := composition.Total()
+		difference := current - latest.TotalCost
+		lifecycle.Integrity = &responses.BOQIntegrityResponse{
+			SnapshotID:    latest.SnapshotID,
+			SnapshotTotal: latest.TotalCost,
+			CurrentTotal:  current,
+			Difference:    difference,
+			Verified:      difference == 0,
+		}
+	}
+
+	return lifecycle, nil
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// DefaultBOQsLimit is the page size used when ListBOQs is called without
+	// a limit.
+	DefaultBOQsLimit = 50
+	// MaxBOQsLimit caps the page size of ListBOQs.
+	MaxBOQsLimit = 200
+)
+
+// ListBOQs returns one page of BOQs across projects, newest first, filtered
+// by status, project and selling general cost. Jobs are not loaded; each BOQ
+// carries its job count instead.
+func (r *boqRepository) ListBOQs(ctx context.Context, req requests.ListBOQRequest) ([]responses.BOQListItemResponse, error) {
+	switch req.Status {
+	case "", models.BOQStatusDraft, models.BOQStatusApproved, models.BOQStatusLocked:
+	default:
+		return nil, fmt.Errorf("%w: unknown BOQ status %q", repositories.ErrInvalidInput, req.Status)
+	}
+
+	if req.MinGeneralCost != nil && req.MaxGeneralCost != nil && *req.MinGeneralCost > *req.MaxGeneralCost {
+		return nil, fmt.Errorf("%w: minimum general cost is greater than the maximum", repositories.ErrInvalidInput)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultBOQsLimit
+	}
+	if limit > MaxBOQsLimit {
+		limit = MaxBOQsLimit
+	}
+
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	projectIDs := make([]string, len(req.ProjectIDs))
+	for i, id := range req.ProjectIDs {
+		projectIDs[i] = id.String()
+	}
+
+	query := `
+        SELECT
+            b.boq_id,
+            b.project_id,
+            b.status,
+            b.selling_general_cost,
+            b.version,
+            b.created_at,
+            b.updated_at,
+            (
+                SELECT COUNT(*) FROM boq_job bj
+                WHERE bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
+            ) as job_count
+        FROM boq b
+        WHERE ($1 = '' OR b.status = $1)
+        AND (cardinality($2::uuid[]) = 0 OR b.project_id = ANY($2::uuid[]))
+        AND ($3::numeric IS NULL OR COALESCE(b.selling_general_cost, 0) >= $3)
+        AND ($4::numeric IS NULL OR COALESCE(b.selling_general_cost, 0) <= $4)
+        ORDER BY b.created_at DESC, b.boq_id
+        LIMIT $5 OFFSET $6`
+
+	type boqRow struct {
+		BOQID              uuid.UUID        `db:"boq_id"`
+		ProjectID          uuid.UUID        `db:"project_id"`
+		Status             models.BOQStatus `db:"status"`
+		SellingGeneralCost models.NullMoney `db:"selling_general_cost"`
+		Version            int              `db:"version"`
+		CreatedAt          time.Time        `db:"created_at"`
+		UpdatedAt          time.Time        `db:"updated_at"`
+		JobCount           int              `db:"job_count"`
+	}
+
+	var rows []boqRow
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.SelectContext(ctx, &rows, query,
+			req.Status,
+			pq.Array(projectIDs),
+			req.MinGeneralCost,
+			req.MaxGeneralCost,
+			limit,
+			offset,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to list BOQs: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	boqs := make([]responses.BOQListItemResponse, len(rows))
+	for i, row := range rows {
+		boqs[i] = responses.BOQListItemResponse{
+			ID:                 row.BOQID,
+			ProjectID:          row.ProjectID,
+			Status:             row.Status,
+			SellingGeneralCost: row.SellingGeneralCost.Money,
+			Version:            row.Version,
+			CreatedAt:          row.CreatedAt,
+			UpdatedAt:          row.UpdatedAt,
+			JobCount:           row.JobCount,
+		}
+	}
+
+	return boqs, nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// LockBOQ moves an approved BOQ to locked, after which it can no longer be
+// edited. Every material must be priced and every job must have a positive
+// quantity; otherwise the error lists what is missing.
+func (r *boqRepository) LockBOQ(ctx context.Context, boqID uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var status models.BOQStatus
+		err := tx.GetContext(ctx, &status, `SELECT status FROM boq WHERE boq_id = $1 FOR UPDATE`, boqID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return repositories.ErrBOQNotFound
+			}
+			return fmt.Errorf("failed to get BOQ status: %w", err)
+		}
+
+		if status == models.BOQStatusLocked {
+			return repositories.ErrBOQLocked
+		}
+
+		if err := status.CanTransitionTo(models.BOQStatusLocked); err != nil {
+			return err
+		}
+
+		unpricedQuery := `
+        SELECT DISTINCT m.name || ' in ' || j.name
+        FROM material_price_log mpl
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id
+        JOIN job j ON j.job_id = mpl.job_id
+        JOIN material m ON m.material_id = mpl.material_id
+        WHERE mpl.boq_id = $1
+        AND bj.deleted_at IS NULL
+        AND mpl.estimated_price IS NULL
+        ORDER BY 1`
+
+		var unpriced []string
+		if err := tx.SelectContext(ctx, &unpriced, unpricedQuery, boqID); err != nil {
+			return fmt.Errorf("failed to find unpriced materials: %w", err)
+		}
+		if len(unpriced) > 0 {
+			return fmt.Errorf("%w: unpriced materials: %s", repositories.ErrBOQNotLockable, strings.Join(unpriced, ", "))
+		}
+
+		emptyQuery := `
+        SELECT j.name
+        FROM boq_job bj
+        JOIN job j ON j.job_id = bj.job_id
+        WHERE bj.boq_id = $1
+        AND bj.deleted_at IS NULL
+        AND (bj.quantity IS NULL OR bj.quantity <= 0)
+        ORDER BY j.name`
+
+		var empty []string
+		if err := tx.SelectContext(ctx, &empty, emptyQuery, boqID); err != nil {
+			return fmt.Errorf("failed to find jobs without quantity: %w", err)
+		}
+		if len(empty) > 0 {
+			return fmt.Errorf("%w: jobs without a quantity: %s", repositories.ErrBOQNotLockable, strings.Join(empty, ", "))
+		}
+
+		_, err = tx.ExecContext(ctx, `
+        UPDATE boq
+        SET status = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE boq_id = $2`, models.BOQStatusLocked, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to lock BOQ: %w", err)
+		}
+
+		diff := map[string]models.BOQStatus{"from": status, "to": models.BOQStatusLocked}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionLock, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
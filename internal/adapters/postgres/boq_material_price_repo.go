@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// UpdateMaterialPrice sets the unit (estimated) price of one material on one
+// job of a draft BOQ.
+func (r *boqRepository) UpdateMaterialPrice(ctx context.Context, boqID, jobID uuid.UUID, materialID string, price models.Money, expectedVersion int) error {
+	if price < 0 {
+		return fmt.Errorf("%w: price must not be negative", repositories.ErrInvalidInput)
+	}
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		query := `
+        UPDATE material_price_log
+        SET estimated_price = $1,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE boq_id = $2 AND job_id = $3 AND material_id = $4
+        AND EXISTS (
+            SELECT 1 FROM boq_job bj
+            WHERE bj.boq_id = $2 AND bj.job_id = $3 AND bj.deleted_at IS NULL
+        )`
+
+		result, err := tx.ExecContext(ctx, query, price, boqID, jobID, materialID)
+		if err != nil {
+			return fmt.Errorf("failed to update material price: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return fmt.Errorf("%w: material %s is not in the price log for job %s", repositories.ErrMaterialNotInBOQ, materialID, jobID)
+		}
+
+		diff := map[string]interface{}{"job_id": jobID, "material_id": materialID, "estimated_price": price}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionPriceUpdate, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetMaterialPriceLogs lists the price log of every live job in the BOQ.
+// Quantity is per unit of job; Total is the converted cost of the material
+// across the whole job quantity, as the BOQ summary counts it.
+func (r *boqRepository) GetMaterialPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.MaterialPriceLogResponse, error) {
+	var result []responses.MaterialPriceLogResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.getMaterialPriceLogs(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) getMaterialPriceLogs(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) ([]responses.MaterialPriceLogResponse, error) {
+	if _, err := getBOQByID(ctx, q, boqID); err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT
+            mpl.job_id,
+            j.name as job_name,
+            mpl.material_id,
+            m.name as material_name,
+            m.unit,
+            COALESCE(mpl.quantity, 0) as quantity,
+            mpl.estimated_price as unit_price,
+            ` + materialLineCost + ` as total,
+            mpl.updated_at
+        FROM material_price_log mpl
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+        JOIN job j ON j.job_id = mpl.job_id
+        JOIN material m ON m.material_id = mpl.material_id
+        WHERE mpl.boq_id = $1
+        ORDER BY j.name, m.name`
+
+	logs := []responses.MaterialPriceLogResponse{}
+	err := sqlx.SelectContext(ctx, q, &logs, query, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get material price logs: %w", err)
+	}
+
+	return logs, nil
+}
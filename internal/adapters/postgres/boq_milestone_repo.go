@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// milestoneTolerance absorbs rounding when checking milestones cover the
+// total: each percentage milestone is rounded to the satang.
+const milestoneTolerance models.Money = 1
+
+func (r *boqRepository) AddBOQMilestone(ctx context.Context, boqID uuid.UUID, req requests.BOQMilestoneRequest) (*responses.BOQMilestoneResponse, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: milestone name is required", repositories.ErrInvalidInput)
+	}
+	if (req.Percentage == nil) == (req.FixedAmount == nil) {
+		return nil, fmt.Errorf("%w: milestone must have either a percentage or a fixed amount", repositories.ErrInvalidInput)
+	}
+	if req.Percentage != nil && (*req.Percentage <= 0 || *req.Percentage > 100) {
+		return nil, fmt.Errorf("%w: milestone percentage must be between 0 and 100", repositories.ErrInvalidInput)
+	}
+	if req.FixedAmount != nil && *req.FixedAmount <= 0 {
+		return nil, fmt.Errorf("%w: milestone fixed amount must be positive", repositories.ErrInvalidInput)
+	}
+	if req.TriggerDate.IsZero() {
+		return nil, fmt.Errorf("%w: milestone trigger date is required", repositories.ErrInvalidInput)
+	}
+
+	milestone := models.BOQMilestone{
+		MilestoneID: uuid.New(),
+		BOQID:       boqID,
+		Name:        strings.TrimSpace(req.Name),
+		TriggerDate: req.TriggerDate,
+	}
+	if req.Percentage != nil {
+		milestone.Percentage.Float64, milestone.Percentage.Valid = *req.Percentage, true
+	}
+	if req.FixedAmount != nil {
+		milestone.FixedAmount.Money, milestone.FixedAmount.Valid = models.MoneyFromFloat(*req.FixedAmount), true
+	}
+
+	query := `
+        INSERT INTO boq_milestone (
+            milestone_id, boq_id, name, percentage, fixed_amount, trigger_date
+        ) VALUES (
+            :milestone_id, :boq_id, :name, :percentage, :fixed_amount, :trigger_date
+        )`
+
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		_, err := tx.NamedExecContext(ctx, query, milestone)
+		if err != nil {
+			return fmt.Errorf("failed to add BOQ milestone: %w", err)
+		}
+
+		diff := map[string]interface{}{"milestone_id": milestone.MilestoneID, "name": milestone.Name}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionAddMilestone, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toBOQMilestoneResponse(milestone)
+	return &response, nil
+}
+
+func (r *boqRepository) RemoveBOQMilestone(ctx context.Context, boqID uuid.UUID, milestoneID uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `DELETE FROM boq_milestone WHERE boq_id = $1 AND milestone_id = $2`
+
+		result, err := tx.ExecContext(ctx, query, boqID, milestoneID)
+		if err != nil {
+			return fmt.Errorf("failed to remove BOQ milestone: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return repositories.ErrMilestoneNotFound
+		}
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionDelMilestone, map[string]uuid.UUID{"milestone_id": milestoneID}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetInvoiceSchedule prices each milestone against the BOQ grand total. The
+// milestones must account for exactly the grand total: percentages of the
+// total plus fixed amounts.
+func (r *boqRepository) GetInvoiceSchedule(ctx context.Context, boqID uuid.UUID) (*responses.InvoiceScheduleResponse, error) {
+	var result *responses.InvoiceScheduleResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.getInvoiceSchedule(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) getInvoiceSchedule(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (*responses.InvoiceScheduleResponse, error) {
+	if _, err := getBOQByID(ctx, q, boqID); err != nil {
+		return nil, err
+	}
+
+	grandTotal, err := r.getBOQGrandTotal(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT milestone_id, boq_id, name, percentage, fixed_amount, trigger_date
+        FROM boq_milestone
+        WHERE boq_id = $1
+        ORDER BY trigger_date, name`
+
+	var milestones []models.BOQMilestone
+	err = sqlx.SelectContext(ctx, q, &milestones, query, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BOQ milestones: %w", err)
+	}
+
+	schedule := &responses.InvoiceScheduleResponse{
+		BOQID:      boqID,
+		GrandTotal: grandTotal,
+		Milestones: make([]responses.BOQMilestoneResponse, len(milestones)),
+	}
+	if len(milestones) == 0 {
+		return schedule, nil
+	}
+
+	var cumulative models.Money
+	for i, milestone := range milestones {
+		response := toBOQMilestoneResponse(milestone)
+		if milestone.Percentage.Valid {
+			response.AmountDue = grandTotal.Percent(milestone.Percentage.Float64)
+		} else {
+			response.AmountDue = milestone.FixedAmount.Money
+		}
+		cumulative += response.AmountDue
+		response.CumulativeDue = cumulative
+		schedule.Milestones[i] = response
+	}
+
+	if difference := cumulative - grandTotal; difference > milestoneTolerance || difference < -milestoneTolerance {
+		return nil, fmt.Errorf("%w: milestones total %s, BOQ grand total %s", repositories.ErrMilestonesUnbalanced, cumulative, grandTotal)
+	}
+
+	return schedule, nil
+}
+
+func toBOQMilestoneResponse(milestone models.BOQMilestone) responses.BOQMilestoneResponse {
+	response := responses.BOQMilestoneResponse{
+		MilestoneID: milestone.MilestoneID,
+		Name:        milestone.Name,
+		TriggerDate: milestone.TriggerDate,
+	}
+	if milestone.Percentage.Valid {
+		percentage := milestone.Percentage.Float64
+		response.Percentage = &percentage
+	}
+	if milestone.FixedAmount.Valid {
+		amount := milestone.FixedAmount.Money
+		response.FixedAmount = &amount
+	}
+	return response
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// MoveBOQJob moves a job with its quantity, labor cost and material price
+// logs from one draft BOQ to another. The destination must not already have
+// the job, live or soft-deleted.
+func (r *boqRepository) MoveBOQJob(ctx context.Context, fromBOQID, toBOQID, jobID uuid.UUID) error {
+	if fromBOQID == toBOQID {
+		return fmt.Errorf("%w: cannot move a job within the same BOQ", repositories.ErrInvalidInput)
+	}
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Lock both rows in one statement, ordered by id, so two opposite
+		// moves cannot deadlock.
+		type BOQStatusRow struct {
+			BOQID  uuid.UUID        `db:"boq_id"`
+			Status models.BOQStatus `db:"status"`
+		}
+
+		var rows []BOQStatusRow
+		statusQuery := `
+        SELECT boq_id, status
+        FROM boq
+        WHERE boq_id IN ($1, $2)
+        ORDER BY boq_id
+        FOR UPDATE`
+
+		if err := tx.SelectContext(ctx, &rows, statusQuery, fromBOQID, toBOQID); err != nil {
+			return fmt.Errorf("failed to get BOQ status: %w", err)
+		}
+
+		statuses := make(map[uuid.UUID]models.BOQStatus, len(rows))
+		for _, row := range rows {
+			statuses[row.BOQID] = row.Status
+		}
+
+		for _, side := range []struct {
+			name  string
+			boqID uuid.UUID
+		}{{"source", fromBOQID}, {"target", toBOQID}} {
+			status, ok := statuses[side.boqID]
+			switch {
+			case !ok:
+				return fmt.Errorf("%s %w", side.name, repositories.ErrBOQNotFound)
+			case status == models.BOQStatusLocked:
+				return fmt.Errorf("%s %w", side.name, repositories.ErrBOQLocked)
+			case !status.IsEditable():
+				return fmt.Errorf("%w: can only move jobs between BOQs in draft status", repositories.ErrBOQNotDraft)
+			}
+		}
+
+		var inTarget bool
+		err := tx.GetContext(ctx, &inTarget, `SELECT EXISTS (SELECT 1 FROM boq_job WHERE boq_id = $1 AND job_id = $2)`, toBOQID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to check target BOQ jobs: %w", err)
+		}
+		if inTarget {
+			return fmt.Errorf("target %w", repositories.ErrJobAlreadyInBOQ)
+		}
+
+		if err := r.checkJobLimit(ctx, tx, toBOQID, 1); err != nil {
+			return err
+		}
+
+		copyJobQuery := `
+        INSERT INTO boq_job (boq_id, job_id, quantity, labor_cost, selling_price, is_provisional)
+        SELECT $1, job_id, quantity, labor_cost, selling_price, is_provisional
+        FROM boq_job
+        WHERE boq_id = $2 AND job_id = $3 AND deleted_at IS NULL`
+
+		result, err := tx.ExecContext(ctx, copyJobQuery, toBOQID, fromBOQID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to copy job to target BOQ: %w", err)
+		}
+
+		copied, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if copied == 0 {
+			return fmt.Errorf("source %w", repositories.ErrJobNotInBOQ)
+		}
+
+		copyPriceLogsQuery := `
+        INSERT INTO material_price_log (
+            material_id, boq_id, job_id, quantity, estimated_price, actual_price, supplier_id, currency, fx_rate, fx_applied, updated_at
+        )
+        SELECT
+            material_id, $1, job_id, quantity, estimated_price, actual_price,
+            supplier_id, currency, fx_rate, fx_applied, CURRENT_TIMESTAMP
+        FROM material_price_log
+        WHERE boq_id = $2 AND job_id = $3`
+
+		_, err = tx.ExecContext(ctx, copyPriceLogsQuery, toBOQID, fromBOQID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to copy material price logs: %w", err)
+		}
+
+		// Delete the source price logs first (foreign key constraint)
+		_, err = tx.ExecContext(ctx, `DELETE FROM material_price_log WHERE boq_id = $1 AND job_id = $2`, fromBOQID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to delete source price logs: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM boq_job WHERE boq_id = $1 AND job_id = $2`, fromBOQID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to delete job from source BOQ: %w", err)
+		}
+
+		for _, boqID := range []uuid.UUID{fromBOQID, toBOQID} {
+			if err := touchBOQ(ctx, tx, boqID); err != nil {
+				return err
+			}
+		}
+
+		diff := map[string]uuid.UUID{"job_id": jobID, "from_boq_id": fromBOQID, "to_boq_id": toBOQID}
+		for _, boqID := range []uuid.UUID{fromBOQID, toBOQID} {
+			if err := writeBOQAudit(ctx, tx, boqID, auditActionMoveJob, diff); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// SetPreliminariesPercent sets the percentage of the direct-works subtotal
+// charged as preliminaries. The amount itself is never stored; it is derived
+// whenever totals are read so it follows changes to the direct works.
+func (r *boqRepository) SetPreliminariesPercent(ctx context.Context, boqID uuid.UUID, req requests.PreliminariesRequest) error {
+	if req.Percent != nil && (*req.Percent < 0 || *req.Percent > 100) {
+		return fmt.Errorf("%w: preliminaries percentage must be between 0 and 100", repositories.ErrInvalidInput)
+	}
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE boq SET preliminaries_percent = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE boq_id = $2`, req.Percent, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to update preliminaries percentage: %w", err)
+		}
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionPreliminaries, map[string]*float64{"percent": req.Percent}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetPreliminaries returns the preliminaries section computed from the
+// current direct-works subtotal.
+func (r *boqRepository) GetPreliminaries(ctx context.Context, boqID uuid.UUID) (*responses.PreliminariesResponse, error) {
+	var result *responses.PreliminariesResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.getPreliminaries(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) getPreliminaries(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (*responses.PreliminariesResponse, error) {
+	percent, err := r.getPreliminariesPercent(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	costs, err := r.getBOQJobCosts(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtotal models.Money
+	for _, cost := range costs {
+		subtotal += cost.Total()
+	}
+
+	return &responses.PreliminariesResponse{
+		BOQID: boqID,
+		PreliminariesDTO: responses.PreliminariesDTO{
+			Percent:             percent,
+			DirectWorksSubtotal: subtotal,
+			Amount:              subtotal.Percent(percent),
+		},
+	}, nil
+}
+
+func (r *boqRepository) getPreliminariesPercent(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (float64, error) {
+	var percent sql.NullFloat64
+	err := sqlx.GetContext(ctx, q, &percent, `SELECT preliminaries_percent FROM boq WHERE boq_id = $1`, boqID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, repositories.ErrBOQNotFound
+		}
+		return 0, fmt.Errorf("failed to get preliminaries percentage: %w", err)
+	}
+
+	return percent.Float64, nil
+}
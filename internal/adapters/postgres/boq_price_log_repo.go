@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/responses"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// orphanedPriceLogsQuery selects price-log rows whose (job, material) pair no
+// longer exists in the job's material template, or whose job has no boq_job
+// row at all. Soft-deleted jobs keep their row, so their logs are not orphans.
+const orphanedPriceLogsQuery = `
+        SELECT
+            mpl.mpl_id,
+            mpl.job_id,
+            j.name as job_name,
+            mpl.material_id,
+            m.name as material_name,
+            mpl.quantity,
+            mpl.estimated_price
+        FROM material_price_log mpl
+        JOIN job j ON j.job_id = mpl.job_id
+        JOIN material m ON m.material_id = mpl.material_id
+        WHERE mpl.boq_id = $1
+        AND (
+            NOT EXISTS (
+                SELECT 1 FROM job_material jm
+                WHERE jm.job_id = mpl.job_id
+                AND jm.material_id = mpl.material_id
+            )
+            OR NOT EXISTS (
+                SELECT 1 FROM boq_job bj
+                WHERE bj.boq_id = mpl.boq_id
+                AND bj.job_id = mpl.job_id
+            )
+        )
+        ORDER BY j.name, m.name`
+
+func (r *boqRepository) GetOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error) {
+	var result []responses.OrphanedPriceLogResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = r.findOrphanedPriceLogs(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) findOrphanedPriceLogs(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error) {
+	orphans := []responses.OrphanedPriceLogResponse{}
+	err := sqlx.SelectContext(ctx, q, &orphans, orphanedPriceLogsQuery, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orphaned price logs: %w", err)
+	}
+
+	return orphans, nil
+}
+
+// CleanOrphanedPriceLogs deletes the rows GetOrphanedPriceLogs reports and
+// returns them. Only draft BOQs can be cleaned.
+func (r *boqRepository) CleanOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error) {
+	var result []responses.OrphanedPriceLogResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		orphans, err := r.findOrphanedPriceLogs(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+
+		if len(orphans) == 0 {
+			result = orphans
+			return nil
+		}
+
+		mplIDs := make([]uuid.UUID, len(orphans))
+		for i, orphan := range orphans {
+			mplIDs[i] = orphan.MplID
+		}
+
+		query, args, err := sqlx.In(`DELETE FROM material_price_log WHERE boq_id = ? AND mpl_id IN (?)`, boqID, mplIDs)
+		if err != nil {
+			return fmt.Errorf("failed to build delete query: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, tx.Rebind(query), args...)
+		if err != nil {
+			return fmt.Errorf("failed to delete orphaned price logs: %w", err)
+		}
+
+		err = writeBOQAudit(ctx, tx, boqID, auditActionCleanPriceLogs, map[string]interface{}{
+			"removed": orphans,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = orphans
+		if err := touchBOQ(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PruneOrphanedPriceLogs is CleanOrphanedPriceLogs for maintenance runs: it
+// returns only how many rows were removed, and a BOQ that is not in draft is
+// skipped with nothing removed instead of failing the run. A missing BOQ is
+// still an error.
+func (r *boqRepository) PruneOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) (int, error) {
+	removed, err := r.CleanOrphanedPriceLogs(ctx, boqID)
+	if errors.Is(err, repositories.ErrBOQNotDraft) || errors.Is(err, repositories.ErrBOQLocked) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return len(removed), nil
+}
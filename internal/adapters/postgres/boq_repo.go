@@ -7,238 +7,937 @@ import (
 	"boonkosang/internal/responses"
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// DefaultMaxJobsPerBOQ bounds how many jobs a single BOQ may hold unless the
+// caller carries repositories.WithBOQSizeOverride.
+const DefaultMaxJobsPerBOQ = 2000
+
+// DefaultQueryTimeout bounds each statement run in a BOQ transaction.
+const DefaultQueryTimeout = 5 * time.Second
+
+// DefaultTxAttempts is how many times a BOQ transaction is run before a
+// serialization failure or deadlock is returned to the caller.
+const DefaultTxAttempts = 3
+
 type boqRepository struct {
-	db *sqlx.DB
+	db           *sqlx.DB
+	maxJobs      int
+	logger       *slog.Logger
+	queryTimeout time.Duration
+	txAttempts   int
+	retryBackoff time.Duration
+}
+
+type BOQRepositoryOption func(*boqRepository)
+
+// WithMaxJobsPerBOQ overrides DefaultMaxJobsPerBOQ. Non-positive values are ignored.
+func WithMaxJobsPerBOQ(maxJobs int) BOQRepositoryOption {
+	return func(r *boqRepository) {
+		if maxJobs > 0 {
+			r.maxJobs = maxJobs
+		}
+	}
+}
+
+// WithLogger sets the logger used for per-request debug logging. By default
+// nothing is logged.
+func WithLogger(logger *slog.Logger) BOQRepositoryOption {
+	return func(r *boqRepository) {
+		if logger != nil {
+			r.logger = logger
+		}
+	}
+}
+
+// WithQueryTimeout overrides DefaultQueryTimeout. Non-positive values are ignored.
+func WithQueryTimeout(timeout time.Duration) BOQRepositoryOption {
+	return func(r *boqRepository) {
+		if timeout > 0 {
+			r.queryTimeout = timeout
+		}
+	}
+}
+
+// WithTxAttempts overrides DefaultTxAttempts. Values below one are ignored;
+// one disables retries.
+func WithTxAttempts(attempts int) BOQRepositoryOption {
+	return func(r *boqRepository) {
+		if attempts > 0 {
+			r.txAttempts = attempts
+		}
+	}
+}
+
+// WithRetryBackoff sets the wait before the first retry of a transaction.
+// Each further retry waits one more step. Negative values are ignored.
+func WithRetryBackoff(backoff time.Duration) BOQRepositoryOption {
+	return func(r *boqRepository) {
+		if backoff >= 0 {
+			r.retryBackoff = backoff
+		}
+	}
+}
+
+func NewBOQRepository(db *sqlx.DB, opts ...BOQRepositoryOption) repositories.BOQRepository {
+	r := &boqRepository{
+		db:           db,
+		maxJobs:      DefaultMaxJobsPerBOQ,
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		queryTimeout: DefaultQueryTimeout,
+		txAttempts:   DefaultTxAttempts,
+		retryBackoff: 20 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-func NewBOQRepository(db *sqlx.DB) repositories.BOQRepository {
-	return &boqRepository{
-		db: db,
+// pingTimeout bounds Ping so a readiness probe fails fast on a dead database.
+const pingTimeout = 2 * time.Second
+
+// Ping checks that the database answers a trivial query. It does not open a
+// transaction.
+func (r *boqRepository) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	var one int
+	if err := r.db.GetContext(ctx, &one, `SELECT 1`); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
 	}
+
+	return nil
+}
+
+// checkJobLimit fails with ErrBOQTooLarge when adding more jobs would
+// push the BOQ over the configured limit.
+func (r *boqRepository) checkJobLimit(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID, adding int) error {
+	if repositories.BOQSizeOverride(ctx) {
+		return nil
+	}
+
+	var count int
+	err := sqlx.GetContext(ctx, q, &count, `SELECT COUNT(*) FROM boq_job WHERE boq_id = $1 AND deleted_at IS NULL`, boqID)
+	if err != nil {
+		return fmt.Errorf("failed to count BOQ jobs: %w", err)
+	}
+
+	if count+adding > r.maxJobs {
+		return fmt.Errorf("%w: limit is %d jobs", repositories.ErrBOQTooLarge, r.maxJobs)
+	}
+
+	return nil
 }
 
 func (r *boqRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BOQ, error) {
+	var result *models.BOQ
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = getBOQByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getBOQByID is GetByID on q, for reads made inside a transaction.
+func getBOQByID(ctx context.Context, q sqlx.QueryerContext, id uuid.UUID) (*models.BOQ, error) {
 	var boq models.BOQ
 	query := `SELECT * FROM boq WHERE boq_id = $1`
-	err := r.db.GetContext(ctx, &boq, query, id)
+	err := sqlx.GetContext(ctx, q, &boq, query, id)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrBOQNotFound
+		}
 		return nil, fmt.Errorf("failed to get BOQ: %w", err)
 	}
 
 	return &boq, nil
 }
 
-func (r *boqRepository) Approve(ctx context.Context, boqID uuid.UUID) error {
-	// Start transaction
-	tx, err := r.db.BeginTxx(ctx, nil)
+// requireDraft locks the BOQ row for the rest of the transaction and fails
+// unless the BOQ is a draft: ErrBOQLocked for a locked BOQ, ErrBOQNotDraft
+// naming the current status otherwise. Every mutating method calls it before
+// changing anything.
+func requireDraft(ctx context.Context, tx *sqlx.Tx, boqID uuid.UUID) error {
+	var status models.BOQStatus
+	err := tx.GetContext(ctx, &status, `SELECT status FROM boq WHERE boq_id = $1 FOR UPDATE`, boqID)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		if err == sql.ErrNoRows {
+			return repositories.ErrBOQNotFound
+		}
+		return fmt.Errorf("failed to get BOQ status: %w", err)
+	}
 
+	if status == models.BOQStatusLocked {
+		return repositories.ErrBOQLocked
 	}
 
-	// Check BOQ status
-	var status string
-	checkStatusQuery := `SELECT status FROM boq WHERE boq_id = $1`
-	err = tx.GetContext(ctx, &status, checkStatusQuery, boqID)
+	if !status.IsEditable() {
+		return fmt.Errorf("%w: current status is %s", repositories.ErrBOQNotDraft, status)
+	}
+
+	return nil
+}
+
+// touchBOQ increments the version and bumps updated_at of a BOQ after one of
+// its lines changed, for edits that are not checked against a version the
+// client read. Clients holding the old version then fail with ErrStaleBOQ.
+func touchBOQ(ctx context.Context, q sqlx.ExecerContext, boqID uuid.UUID) error {
+	_, err := q.ExecContext(ctx, `UPDATE boq SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE boq_id = $1`, boqID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("boq not found")
-		}
-		return fmt.Errorf("failed to get BOQ status: %w", err)
+		return fmt.Errorf("failed to update BOQ version: %w", err)
 	}
 
-	if status != "draft" {
-		return errors.New("can only approve BOQ in draft status")
+	return nil
+}
 
+// advanceBOQVersion increments the version of a BOQ row already locked by
+// tx and bumps its updated_at. It fails with ErrStaleBOQ when the stored
+// version is no longer the one the caller read, so the edit is rolled back
+// instead of overwriting another.
+func advanceBOQVersion(ctx context.Context, tx *sqlx.Tx, boqID uuid.UUID, expectedVersion int) error {
+	result, err := tx.ExecContext(ctx, `UPDATE boq SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE boq_id = $1 AND version = $2`, boqID, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("failed to update BOQ version: %w", err)
 	}
 
-	// Update BOQ status
-	updateQuery := `UPDATE boq SET status = 'approved' WHERE boq_id = $1`
-	_, err = tx.ExecContext(ctx, updateQuery, boqID)
+	rows, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to update BOQ status: %w", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if rows == 0 {
+		return fmt.Errorf("%w: version %d is out of date", repositories.ErrStaleBOQ, expectedVersion)
 	}
 
 	return nil
 }
 
+func (r *boqRepository) Approve(ctx context.Context, boqID uuid.UUID, req requests.ApproveBOQRequest, expectedVersion int) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Lock the row so concurrent approvals are serialized
+		var status models.BOQStatus
+		checkStatusQuery := `SELECT status FROM boq WHERE boq_id = $1 FOR UPDATE`
+		err := tx.GetContext(ctx, &status, checkStatusQuery, boqID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return repositories.ErrBOQNotFound
+			}
+			return fmt.Errorf("failed to get BOQ status: %w", err)
+		}
+
+		if err := status.CanTransitionTo(models.BOQStatusApproved); err != nil {
+			return err
+		}
+
+		if req.EnforceRequiredJobs {
+			check, err := r.checkRequiredJobs(ctx, tx, boqID)
+			if err != nil {
+				return err
+			}
+			if !check.Complete {
+				names := make([]string, len(check.MissingJobs))
+				for i, job := range check.MissingJobs {
+					names[i] = job.Name
+				}
+				return fmt.Errorf("%w: %s", repositories.ErrMissingRequiredJobs, strings.Join(names, ", "))
+			}
+		}
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		mixed, err := r.findMixedCurrencyLines(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+		var unconverted []string
+		for _, line := range mixed {
+			if !line.Converted {
+				unconverted = append(unconverted, fmt.Sprintf("%s in %s (%s)", line.MaterialName, line.JobName, line.Currency))
+			}
+		}
+		if len(unconverted) > 0 {
+			return fmt.Errorf("%w: %s", repositories.ErrMixedCurrency, strings.Join(unconverted, ", "))
+		}
+
+		if req.MaxMarkupPercent != nil {
+			exceeding, err := r.findLinesExceedingMarkupCap(ctx, tx, boqID, *req.MaxMarkupPercent)
+			if err != nil {
+				return err
+			}
+			if len(exceeding) > 0 {
+				names := make([]string, len(exceeding))
+				for i, job := range exceeding {
+					names[i] = fmt.Sprintf("%s (%.2f%%)", job.Name, job.MarkupPercent)
+				}
+				return fmt.Errorf("%w: %.2f%% cap exceeded by %s", repositories.ErrMarkupCapExceeded, *req.MaxMarkupPercent, strings.Join(names, ", "))
+			}
+		}
+
+		if req.MaxProvisionalPercent != nil {
+			share, err := r.getProvisionalShare(ctx, tx, boqID)
+			if err != nil {
+				return err
+			}
+			if share.SharePercent > *req.MaxProvisionalPercent {
+				names := make([]string, len(share.Items))
+				for i, item := range share.Items {
+					names[i] = item.Name
+				}
+				return fmt.Errorf("%w: %.2f%% exceeds %.2f%% (%s)", repositories.ErrProvisionalExceedsCap,
+					share.SharePercent, *req.MaxProvisionalPercent, strings.Join(names, ", "))
+			}
+		}
+
+		// Update BOQ status
+		updateQuery := `UPDATE boq SET status = 'approved' WHERE boq_id = $1`
+		_, err = tx.ExecContext(ctx, updateQuery, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to update BOQ status: %w", err)
+		}
+
+		if err := r.writeBOQCostSnapshot(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if err := writeMaterialPriceSnapshot(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionApprove, map[string]models.BOQStatus{"from": status, "to": models.BOQStatusApproved}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// UpdateBOQStatus moves a BOQ to newStatus when the state machine allows it.
+// Approval goes through Approve so its checks and snapshot still apply.
+func (r *boqRepository) UpdateBOQStatus(ctx context.Context, boqID uuid.UUID, newStatus models.BOQStatus, expectedVersion int) error {
+	if newStatus == models.BOQStatusApproved {
+		return r.Approve(ctx, boqID, requests.ApproveBOQRequest{}, expectedVersion)
+	}
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Lock the row so concurrent transitions from the same status are serialized
+		var status models.BOQStatus
+		err := tx.GetContext(ctx, &status, `SELECT status FROM boq WHERE boq_id = $1 FOR UPDATE`, boqID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return repositories.ErrBOQNotFound
+			}
+			return fmt.Errorf("failed to get BOQ status: %w", err)
+		}
+
+		if err := status.CanTransitionTo(newStatus); err != nil {
+			return err
+		}
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE boq SET status = $1 WHERE boq_id = $2`, newStatus, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to update BOQ status: %w", err)
+		}
+
+		diff := map[string]models.BOQStatus{"from": status, "to": newStatus}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionStatusChange, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
 func (r *boqRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID) (*models.BOQ, error) {
 	var boq models.BOQ
 	query := `SELECT * FROM boq WHERE project_id = $1`
-	err := r.db.GetContext(ctx, &boq, query, projectID)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &boq, query, projectID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return repositories.ErrBOQNotFound
+			}
+			return fmt.Errorf("failed to get BOQ: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get BOQ: %w", err)
+		return nil, err
 	}
 
 	return &boq, nil
 }
 
 func (r *boqRepository) GetBoqWithProject(ctx context.Context, projectID uuid.UUID) (*responses.BOQResponse, error) {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
+	var result *responses.BOQResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var data models.BOQ
 
-	var data models.BOQ
-
-	boqQuery := `
-        SELECT  boq_id, project_id, status, selling_general_cost
+		boqQuery := `
+        SELECT  boq_id, project_id, status, selling_general_cost, version, created_at, updated_at
 		FROM Boq
 		WHERE project_id = $1`
 
-	err = tx.GetContext(ctx, &data, boqQuery, projectID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			// Create new BOQ if it doesn't exist
-			createBOQQuery := `
-                INSERT INTO Boq (project_id, status, selling_general_cost) 
-                VALUES (:project_id, 'draft', NULL) 
-                RETURNING boq_id, project_id, status, selling_general_cost`
-
-			row, err := r.db.NamedQueryContext(ctx, createBOQQuery, map[string]interface{}{
-				"project_id": projectID,
+		err := tx.GetContext(ctx, &data, boqQuery, projectID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return repositories.ErrBOQNotFound
+			}
+			return fmt.Errorf("failed to check BOQ existence: %w", err)
+		}
+
+		// Convert to response struct
+		response := &responses.BOQResponse{
+			ID:                 data.BOQID,
+			ProjectID:          data.ProjectID,
+			Status:             data.Status,
+			SellingGeneralCost: data.SellingGeneralCost.Money,
+			Version:            data.Version,
+			CreatedAt:          data.CreatedAt,
+			UpdatedAt:          data.UpdatedAt,
+		}
+
+		jobsQuery := `
+   SELECT DISTINCT
+	j.*, bj.quantity, bj.labor_cost
+FROM job j
+JOIN boq_job bj ON j.job_id = bj.job_id
+WHERE bj.boq_id = $1 AND bj.deleted_at IS NULL
+`
+
+		// Legacy lines may have no quantity or labor cost; both read as zero.
+		type BoqJobData struct {
+			JobID       uuid.UUID        `db:"job_id"`
+			Name        string           `db:"name"`
+			Description sql.NullString   `db:"description"`
+			Unit        string           `db:"unit"`
+			Quantity    sql.NullFloat64  `db:"quantity"`
+			LaborCost   models.NullMoney `db:"labor_cost"`
+		}
+
+		var jobs []BoqJobData
+
+		err = tx.SelectContext(ctx, &jobs, jobsQuery, data.BOQID)
+		if err != nil {
+			return fmt.Errorf("failed to get jobs: %w", err)
+		}
+
+		materials, err := r.getBOQJobMaterials(ctx, tx, data.BOQID)
+		if err != nil {
+			return err
+		}
+
+		jobForResponse := []responses.JobResponse{}
+		for _, job := range jobs {
+			jobMaterials, ok := materials[job.JobID]
+			if !ok {
+				jobMaterials = []responses.JobMaterialItem{}
+			}
+
+			jobForResponse = append(jobForResponse, responses.JobResponse{
+				JobID:       job.JobID,
+				Name:        job.Name,
+				Description: job.Description.String,
+				Unit:        job.Unit,
+				Quantity:    job.Quantity.Float64,
+				LaborCost:   job.LaborCost.Money,
+				Materials:   jobMaterials,
 			})
+		}
+
+		response.Jobs = jobForResponse
+
+		attachments, err := r.listBOQAttachments(ctx, tx, data.BOQID)
+		if err != nil {
+			return err
+		}
+		response.Attachments = groupBOQAttachments(attachments)
+
+		r.logger.DebugContext(ctx, "fetched BOQ with project",
+			slog.String("project_id", projectID.String()),
+			slog.String("boq_id", data.BOQID.String()),
+			slog.Int("jobs", len(response.Jobs)),
+		)
+
+		result = response
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateBOQForProject creates the draft BOQ of an existing project. A project
+// has at most one BOQ. Without a general cost in opts the BOQ starts with the
+// project's default_general_cost, which may be NULL.
+func (r *boqRepository) CreateBOQForProject(ctx context.Context, projectID uuid.UUID, opts requests.CreateBOQOptions) (*models.BOQ, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	status := opts.Status
+	if status == "" {
+		status = models.BOQStatusDraft
+	}
+
+	var result *models.BOQ
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := checkProjectAcceptsBOQ(ctx, tx, projectID); err != nil {
+			return err
+		}
+
+		var exists bool
+		err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM boq WHERE project_id = $1)`, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to check BOQ existence: %w", err)
+		}
+		if exists {
+			return repositories.ErrBOQExists
+		}
+
+		generalCost := opts.SellingGeneralCost
+		if generalCost == nil {
+			var projectDefault sql.NullFloat64
+			err = tx.GetContext(ctx, &projectDefault, `SELECT default_general_cost FROM project WHERE project_id = $1`, projectID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create new BOQ: %w", err)
+				return fmt.Errorf("failed to get project default general cost: %w", err)
 			}
+			if projectDefault.Valid {
+				generalCost = &projectDefault.Float64
+			}
+		}
 
-			if row.Next() {
-				err = row.StructScan(&data)
-				if err != nil {
-					return nil, fmt.Errorf("failed to scan BOQ: %w", err)
-				}
+		var boq models.BOQ
+		createBOQQuery := `
+        INSERT INTO boq (project_id, status, selling_general_cost)
+        VALUES ($1, $2, $3)
+        RETURNING boq_id, project_id, status, selling_general_cost, version, created_at, updated_at`
+
+		err = tx.GetContext(ctx, &boq, createBOQQuery, projectID, status, generalCost)
+		if err != nil {
+			return fmt.Errorf("failed to create new BOQ: %w", err)
+		}
+
+		diff := map[string]interface{}{"project_id": projectID, "selling_general_cost": generalCost}
+		if err := writeBOQAudit(ctx, tx, boq.BOQID, auditActionCreate, diff); err != nil {
+			return err
+		}
+
+		result = &boq
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// checkProjectAcceptsBOQ locks the project row and fails with
+// ErrProjectNotFound or ErrProjectClosed when no BOQ may be created for it.
+// The lock keeps concurrent creates for the same project from both passing.
+func checkProjectAcceptsBOQ(ctx context.Context, tx *sqlx.Tx, projectID uuid.UUID) error {
+	var status models.ProjectStatus
+	err := tx.GetContext(ctx, &status, `SELECT status FROM project WHERE project_id = $1 FOR UPDATE`, projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return repositories.ErrProjectNotFound
+		}
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if status == models.ProjectStatusCompleted || status == models.ProjectStatusCancelled {
+		return fmt.Errorf("%w: status is %s", repositories.ErrProjectClosed, status)
+	}
+
+	return nil
+}
+
+func (r *boqRepository) AddBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) error {
+	_, err := r.AddBOQJobResult(ctx, boqID, req, expectedVersion)
+	return err
+}
+
+// AddBOQJobResult adds a job like AddBOQJob and returns the created line, so
+// callers can show it without fetching the BOQ again.
+func (r *boqRepository) AddBOQJobResult(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) (*responses.BOQJobResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var result *responses.BOQJobResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// The row lock serializes concurrent adds to the same BOQ
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		created, err := r.insertBOQJob(ctx, tx, boqID, req)
+		if err != nil {
+			return err
+		}
+
+		diff := map[string]interface{}{"job_id": req.JobID, "quantity": created.Quantity, "labor_cost": created.LaborCost}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionAddJob, diff); err != nil {
+			return err
+		}
+
+		result = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AddBOQJobs adds several jobs to a draft BOQ in one transaction and reports
+// each job's cost contribution with the BOQ grand total after it, so imports
+// can show progress. The status is checked once, the jobs go in with a single
+// multi-row insert and their material_price_log rows with one batched insert.
+// Any invalid job rolls back the whole batch with an error naming its job_id.
+func (r *boqRepository) AddBOQJobs(ctx context.Context, boqID uuid.UUID, reqs []requests.BOQJobRequest, expectedVersion int) (*responses.BOQJobsImportResponse, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: at least one job is required", repositories.ErrInvalidInput)
+	}
+
+	jobIDs := make([]string, len(reqs))
+	quantities := make([]float64, len(reqs))
+	laborCosts := make([]float64, len(reqs))
+	seen := make(map[uuid.UUID]bool, len(reqs))
+	for i, req := range reqs {
+		if err := req.Validate(); err != nil {
+			return nil, fmt.Errorf("job %s: %w", req.JobID, err)
+		}
+		if seen[req.JobID] {
+			return nil, fmt.Errorf("%w: job %s is listed more than once", repositories.ErrInvalidInput, req.JobID)
+		}
+		seen[req.JobID] = true
+
+		jobIDs[i] = req.JobID.String()
+		quantities[i] = req.Quantity
+		laborCosts[i] = req.LaborCost
+	}
+
+	var result *responses.BOQJobsImportResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		live, err := r.checkBatchJobs(ctx, tx, boqID, reqs, jobIDs)
+		if err != nil {
+			return err
+		}
+
+		// Revived soft-deleted jobs count against the limit like new ones
+		if err := r.checkJobLimit(ctx, tx, boqID, len(reqs)-live); err != nil {
+			return err
+		}
+
+		// The grand total is rebuilt from the direct works after each job, the
+		// same way boqGrandTotal builds it, so rounding cannot drift.
+		costs, err := r.getBOQJobCosts(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+
+		var runningDirect models.Money
+		for _, cost := range costs {
+			runningDirect += cost.Total()
+		}
+
+		generalCost, err := getGeneralCostTotal(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+
+		preliminaries, err := r.getPreliminariesPercent(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+
+		runningTotal := boqGrandTotal(runningDirect, generalCost, preliminaries)
+
+		// Upserted jobs already count in the starting total, so they only
+		// contribute the difference from their old cost.
+		oldCosts, err := r.getBatchJobCosts(ctx, tx, boqID, jobIDs)
+		if err != nil {
+			return err
+		}
+
+		// checkBatchJobs has rejected every conflict that was not asked to upsert,
+		// and the BOQ row lock keeps other writers out until commit.
+		insertJobsQuery := `
+        INSERT INTO boq_job (boq_id, job_id, quantity, labor_cost)
+        SELECT $1, j.job_id, j.quantity, j.labor_cost
+        FROM unnest($2::uuid[], $3::numeric[], $4::numeric[]) AS j(job_id, quantity, labor_cost)
+        ON CONFLICT (boq_id, job_id) DO UPDATE
+        SET quantity = EXCLUDED.quantity, labor_cost = EXCLUDED.labor_cost, deleted_at = NULL`
+
+		_, err = tx.ExecContext(ctx, insertJobsQuery, boqID, pq.Array(jobIDs), pq.Array(quantities), pq.Array(laborCosts))
+		if err != nil {
+			return fmt.Errorf("failed to add jobs to BOQ: %w", err)
+		}
+
+		// New price logs reuse the estimated price a material already has in this
+		// BOQ, and pairs that already have a log are skipped.
+		insertPriceLogsQuery := `
+        INSERT INTO material_price_log (
+            material_id, boq_id, job_id, quantity, estimated_price, updated_at
+        )
+        SELECT jm.material_id, $1, jm.job_id, jm.quantity, ep.estimated_price, CURRENT_TIMESTAMP
+        FROM job_material jm
+        LEFT JOIN (
+            SELECT DISTINCT ON (mpl.material_id) mpl.material_id, mpl.estimated_price
+            FROM material_price_log mpl
+            JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+            WHERE mpl.boq_id = $1 AND mpl.material_id IS NOT NULL
+            ORDER BY mpl.material_id, mpl.estimated_price NULLS LAST
+        ) ep ON ep.material_id = jm.material_id
+        WHERE jm.job_id = ANY($2::uuid[])
+        AND NOT EXISTS (
+            SELECT 1 FROM material_price_log existing
+            WHERE existing.boq_id = $1
+            AND existing.job_id = jm.job_id
+            AND existing.material_id = jm.material_id
+        )`
+
+		_, err = tx.ExecContext(ctx, insertPriceLogsQuery, boqID, pq.Array(jobIDs))
+		if err != nil {
+			return fmt.Errorf("failed to create material price logs: %w", err)
+		}
+
+		costsByJob, err := r.getBatchJobCosts(ctx, tx, boqID, jobIDs)
+		if err != nil {
+			return err
+		}
+
+		result = &responses.BOQJobsImportResponse{
+			BOQID:         boqID,
+			StartingTotal: runningTotal,
+			Jobs:          make([]responses.BOQJobContributionResponse, len(reqs)),
+		}
+		for i, req := range reqs {
+			// Upserted jobs already count in the starting total, so only the
+			// change from their old cost is added.
+			cost := costsByJob[req.JobID]
+			runningDirect += cost.Total() - oldCosts[req.JobID].Total()
+
+			previous := runningTotal
+			runningTotal = boqGrandTotal(runningDirect, generalCost, preliminaries)
+			result.Jobs[i] = responses.BOQJobContributionResponse{
+				JobID:        req.JobID,
+				Name:         cost.Name,
+				Contribution: runningTotal - previous,
+				RunningTotal: runningTotal,
 			}
+		}
+		result.FinalTotal = runningTotal
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionAddJob, map[string][]string{"job_ids": jobIDs}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			if err := row.Close(); err != nil {
-				return nil, fmt.Errorf("failed to close row: %w", err)
-			}
+	return result, nil
+}
 
-		} else {
-			return nil, fmt.Errorf("failed to check BOQ existence: %w", err)
-		}
+// getBatchJobCosts returns the live lines of the given jobs in the BOQ by
+// job_id. Jobs not in the BOQ, or soft-deleted, are missing from the map.
+func (r *boqRepository) getBatchJobCosts(ctx context.Context, tx *sqlx.Tx, boqID uuid.UUID, jobIDs []string) (map[uuid.UUID]boqJobCost, error) {
+	var costs []boqJobCost
+	err := tx.SelectContext(ctx, &costs, boqJobCostQuery("bj.boq_id = $1 AND bj.job_id = ANY($2::uuid[])"), boqID, pq.Array(jobIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BOQ job costs: %w", err)
 	}
 
-	// Convert to response struct
-	response := &responses.BOQResponse{
-		ID:                 data.BOQID, // Assuming the correct field name is BOQID
-		ProjectID:          data.ProjectID,
-		Status:             data.Status, // Assuming the correct field name is Status
-		SellingGeneralCost: data.SellingGeneralCost.Float64,
+	costsByJob := make(map[uuid.UUID]boqJobCost, len(costs))
+	for _, cost := range costs {
+		costsByJob[cost.JobID] = cost
 	}
 
-	jobsQuery := `
-   SELECT DISTINCT
-	j.*, bj.quantity, bj.labor_cost
-FROM job j
-JOIN boq_job bj ON j.job_id = bj.job_id
-WHERE bj.boq_id = $1
-`
+	return costsByJob, nil
+}
 
-	type BoqJobData struct {
-		JobID       uuid.UUID      `db:"job_id"`
-		Name        string         `db:"name"`
-		Description sql.NullString `db:"description"`
-		Unit        string         `db:"unit"`
-		Quantity    float64        `db:"quantity"`
-		LaborCost   float64        `db:"labor_cost"`
+// checkBatchJobs applies the per-job checks of insertBOQJob to a whole batch
+// with one query, naming the first offending job_id. It returns how many of
+// the jobs are live in the BOQ and will be upserted; soft-deleted jobs are
+// revived by an upsert and rejected otherwise, as in insertBOQJob.
+func (r *boqRepository) checkBatchJobs(ctx context.Context, tx *sqlx.Tx, boqID uuid.UUID, reqs []requests.BOQJobRequest, jobIDs []string) (int, error) {
+	type batchJob struct {
+		JobID   uuid.UUID `db:"job_id"`
+		Unit    string    `db:"unit"`
+		InBOQ   bool      `db:"in_boq"`
+		Deleted bool      `db:"deleted"`
 	}
 
-	var jobs []BoqJobData
-
-	err = tx.SelectContext(ctx, &jobs, jobsQuery, data.BOQID)
+	query := `
+        SELECT j.job_id, j.unit,
+            EXISTS (
+                SELECT 1 FROM boq_job bj
+                WHERE bj.boq_id = $1 AND bj.job_id = j.job_id AND bj.deleted_at IS NULL
+            ) as in_boq,
+            EXISTS (
+                SELECT 1 FROM boq_job bj
+                WHERE bj.boq_id = $1 AND bj.job_id = j.job_id AND bj.deleted_at IS NOT NULL
+            ) as deleted
+        FROM job j
+        WHERE j.job_id = ANY($2::uuid[])`
+
+	var jobs []batchJob
+	err := tx.SelectContext(ctx, &jobs, query, boqID, pq.Array(jobIDs))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get jobs: %w", err)
+		return 0, fmt.Errorf("failed to get jobs: %w", err)
 	}
 
-	var jobForResponse []responses.JobResponse
+	found := make(map[uuid.UUID]batchJob, len(jobs))
 	for _, job := range jobs {
-		jobForResponse = append(jobForResponse, responses.JobResponse{
-			JobID:       job.JobID,
-			Name:        job.Name,
-			Description: job.Description.String,
-			Unit:        job.Unit,
-			Quantity:    job.Quantity,
-			LaborCost:   job.LaborCost,
-		})
+		found[job.JobID] = job
 	}
 
-	response.Jobs = jobForResponse
+	var live int
+	for _, req := range reqs {
+		job, ok := found[req.JobID]
+		switch {
+		case !ok:
+			return 0, fmt.Errorf("job %s: %w", req.JobID, repositories.ErrJobNotFound)
+		case !models.IsCanonicalUnit(job.Unit):
+			return 0, fmt.Errorf("job %s: %w: %q, normalize the catalog first", req.JobID, repositories.ErrNonCanonicalUnit, job.Unit)
+		case job.Deleted && !req.Upsert:
+			return 0, fmt.Errorf("job %s: %w: it was deleted, restore it instead", req.JobID, repositories.ErrJobAlreadyInBOQ)
+		case job.InBOQ && !req.Upsert:
+			return 0, fmt.Errorf("job %s: %w", req.JobID, repositories.ErrJobAlreadyInBOQ)
+		case job.InBOQ:
+			live++
+		}
+	}
 
-	return response, nil
+	return live, nil
 }
 
-func (r *boqRepository) AddBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest) error {
-	// Start transaction
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Check BOQ status
-	var status string
-	checkStatusQuery := `SELECT status FROM boq WHERE boq_id = $1`
-	err = tx.GetContext(ctx, &status, checkStatusQuery, boqID)
+// insertBOQJob adds one job to a draft BOQ inside the caller's transaction,
+// creating its material_price_log rows from the job's material template. It
+// returns the stored line and how many price logs were created for it.
+func (r *boqRepository) insertBOQJob(ctx context.Context, tx *sqlx.Tx, boqID uuid.UUID, req requests.BOQJobRequest) (*responses.BOQJobResponse, error) {
+	// Confirm the job exists before touching boq_job or material_price_log.
+	// Jobs with legacy unit spellings must be normalized before they are used
+	var jobUnit string
+	err := tx.GetContext(ctx, &jobUnit, `SELECT unit FROM job WHERE job_id = $1`, req.JobID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return errors.New("boq not found")
+			return nil, repositories.ErrJobNotFound
 		}
-		return fmt.Errorf("failed to get BOQ status: %w", err)
+		return nil, fmt.Errorf("failed to get job unit: %w", err)
 	}
-
-	if status != "draft" {
-		return errors.New("can only add jobs to BOQ in draft status")
+	if !models.IsCanonicalUnit(jobUnit) {
+		return nil, fmt.Errorf("%w: %q, normalize the catalog first", repositories.ErrNonCanonicalUnit, jobUnit)
 	}
 
-	// Validate input
-	if req.Quantity <= 0 || req.LaborCost <= 0 {
-		return errors.New("quantity and labor cost must be positive numbers")
+	// Check if job already exists in BOQ, including soft-deleted rows
+	var existing struct {
+		InBOQ   bool `db:"in_boq"`
+		Deleted bool `db:"deleted"`
 	}
-
-	// Check if job already exists in BOQ
-	var exists bool
 	checkJobQuery := `
-        SELECT EXISTS (
-            SELECT 1 FROM boq_job 
-            WHERE boq_id = $1 AND job_id = $2
-        )`
-	err = tx.GetContext(ctx, &exists, checkJobQuery, boqID, req.JobID)
+        SELECT
+            EXISTS (
+                SELECT 1 FROM boq_job
+                WHERE boq_id = $1 AND job_id = $2
+            ) as in_boq,
+            EXISTS (
+                SELECT 1 FROM boq_job
+                WHERE boq_id = $1 AND job_id = $2 AND deleted_at IS NOT NULL
+            ) as deleted`
+	err = tx.GetContext(ctx, &existing, checkJobQuery, boqID, req.JobID)
 	if err != nil {
-		return fmt.Errorf("failed to check job existence: %w", err)
+		return nil, fmt.Errorf("failed to check job existence: %w", err)
 	}
-	if exists {
-		return errors.New("job already exists in this BOQ")
+	exists := existing.InBOQ
+	if exists && !req.Upsert {
+		if existing.Deleted {
+			return nil, fmt.Errorf("%w: it was deleted, restore it instead", repositories.ErrJobAlreadyInBOQ)
+		}
+		return nil, repositories.ErrJobAlreadyInBOQ
+	}
+
+	if !exists || existing.Deleted {
+		if err := r.checkJobLimit(ctx, tx, boqID, 1); err != nil {
+			return nil, err
+		}
 	}
 
-	// Insert into boq_job
+	// Insert into boq_job; the unique (boq_id, job_id) index is the final guard
 	insertBOQJobQuery := `
         INSERT INTO boq_job (
             boq_id, job_id, quantity, labor_cost
         ) VALUES (
             $1, $2, $3, $4
-        )`
+        )
+        ON CONFLICT (boq_id, job_id) DO NOTHING`
+	if req.Upsert {
+		insertBOQJobQuery = `
+        INSERT INTO boq_job (
+            boq_id, job_id, quantity, labor_cost
+        ) VALUES (
+            $1, $2, $3, $4
+        )
+        ON CONFLICT (boq_id, job_id) DO UPDATE
+        SET quantity = EXCLUDED.quantity, labor_cost = EXCLUDED.labor_cost, deleted_at = NULL`
+	}
 
-	_, err = tx.ExecContext(ctx, insertBOQJobQuery,
+	created := responses.BOQJobResponse{BOQID: boqID, JobID: req.JobID}
+	err = tx.QueryRowxContext(ctx, insertBOQJobQuery+`
+        RETURNING quantity, labor_cost`,
 		boqID,
 		req.JobID,
 		req.Quantity,
 		req.LaborCost,
-	)
+	).Scan(&created.Quantity, &created.LaborCost)
 	if err != nil {
-		return fmt.Errorf("failed to add job to BOQ: %w", err)
+		// DO NOTHING returns no row when the job went in concurrently
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrJobAlreadyInBOQ
+		}
+		return nil, fmt.Errorf("failed to add job to BOQ: %w", err)
 	}
 
 	// Get all materials for the job
@@ -255,7 +954,7 @@ func (r *boqRepository) AddBOQJob(ctx context.Context, boqID uuid.UUID, req requ
 
 	err = tx.SelectContext(ctx, &materials, materialQuery, req.JobID)
 	if err != nil {
-		return fmt.Errorf("failed to get job materials: %w", err)
+		return nil, fmt.Errorf("failed to get job materials: %w", err)
 	}
 
 	// Get existing materials in BOQ with their estimated prices
@@ -274,12 +973,13 @@ func (r *boqRepository) AddBOQJob(ctx context.Context, boqID uuid.UUID, req requ
         ON mpl.boq_id = bj.boq_id
         AND mpl.job_id = bj.job_id 
     WHERE bj.boq_id = $1
+    AND bj.deleted_at IS NULL
     AND mpl.material_id IS NOT NULL`
 
 	var existingMaterials []ExistingMaterial
 	err = tx.SelectContext(ctx, &existingMaterials, existingMaterialsQuery, boqID)
 	if err != nil {
-		return fmt.Errorf("failed to get existing materials: %w", err)
+		return nil, fmt.Errorf("failed to get existing materials: %w", err)
 	}
 
 	// Create map for quick lookup of estimated prices
@@ -289,8 +989,29 @@ func (r *boqRepository) AddBOQJob(ctx context.Context, boqID uuid.UUID, req requ
 			estimatedPrices[em.MaterialID.String] = em.EstimatedPrice
 		}
 	}
+
+	// An upserted job keeps the price logs it already has
+	logged := make(map[string]bool)
+	if exists {
+		var loggedMaterials []string
+		loggedQuery := `
+            SELECT material_id FROM material_price_log
+            WHERE boq_id = $1 AND job_id = $2 AND material_id IS NOT NULL`
+		err = tx.SelectContext(ctx, &loggedMaterials, loggedQuery, boqID, req.JobID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job price logs: %w", err)
+		}
+		for _, materialID := range loggedMaterials {
+			logged[materialID] = true
+		}
+	}
+
 	// Add material_price_log entries
 	for _, material := range materials {
+		if logged[material.MaterialID] {
+			continue
+		}
+
 		insertPriceLogQuery := `
             INSERT INTO material_price_log (
                 material_id, boq_id, job_id, quantity, estimated_price, updated_at
@@ -308,125 +1029,355 @@ func (r *boqRepository) AddBOQJob(ctx context.Context, boqID uuid.UUID, req requ
 			estimatedPrice,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to create material price log: %w", err)
+			return nil, fmt.Errorf("failed to create material price log: %w", err)
 		}
+		created.PriceLogsCreated++
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
+	return &created, nil
+}
 
-	return nil
+func (r *boqRepository) UpdateBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) error {
+	return r.UpdateBOQJobQuantity(ctx, boqID, req.JobID, req.Quantity, req.LaborCost, expectedVersion)
 }
 
-func (r *boqRepository) UpdateBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest) error {
-	jobID := req.JobID
-	// Start transaction
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+// UpdateBOQJobQuantity changes the quantity and labor cost of a job in a
+// draft BOQ. Price-log quantities are stored per unit of job, so material
+// totals follow the new quantity without rewriting the logs.
+func (r *boqRepository) UpdateBOQJobQuantity(ctx context.Context, boqID, jobID uuid.UUID, quantity float64, laborCost float64, expectedVersion int) error {
+	if quantity <= 0 {
+		return fmt.Errorf("%w: quantity must be a positive number", repositories.ErrInvalidInput)
+	}
+	if laborCost < 0 {
+		return fmt.Errorf("%w: labor cost must not be negative", repositories.ErrInvalidInput)
 	}
-	defer tx.Rollback()
 
-	// Check BOQ status
-	var status string
-	checkStatusQuery := `SELECT status FROM boq WHERE boq_id = $1`
-	err = tx.GetContext(ctx, &status, checkStatusQuery, boqID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("boq not found")
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
 		}
-		return fmt.Errorf("failed to get BOQ status: %w", err)
-	}
 
-	if status != "draft" {
-		return errors.New("can only update jobs in BOQ in draft status")
-	}
+		updateBOQJobQuery := `
+        UPDATE boq_job
+        SET quantity = $1, labor_cost = $2
+        WHERE boq_id = $3 AND job_id = $4 AND deleted_at IS NULL`
 
-	// Update BOQ job
-	updateBOQJobQuery := `
-		UPDATE boq_job
-		SET quantity = $1, labor_cost = $2
-		WHERE boq_id = $3 AND job_id = $4`
+		result, err := tx.ExecContext(ctx, updateBOQJobQuery, quantity, laborCost, boqID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to update job in BOQ: %w", err)
+		}
 
-	_, err = tx.ExecContext(ctx, updateBOQJobQuery, req.Quantity, req.LaborCost, boqID, jobID)
-	if err != nil {
-		return fmt.Errorf("failed to update job in BOQ: %w", err)
-	}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
+		if rows == 0 {
+			return repositories.ErrJobNotInBOQ
+		}
 
-	return nil
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		diff := map[string]interface{}{"job_id": jobID, "quantity": quantity, "labor_cost": laborCost}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionUpdateJob, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
 }
 
-func (r *boqRepository) DeleteBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID) error {
-	// Start transaction
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
+// DeleteBOQJob soft-deletes a job of a draft BOQ. Its material price logs
+// are kept so RestoreBOQJob can bring the job back priced; while the job is
+// deleted they count towards no total. Callers that want the logs gone must
+// use PurgeBOQJob, which removes the job's own logs and leaves other jobs'
+// logs for the same materials in place.
+func (r *boqRepository) DeleteBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
 
-	// Check BOQ status
-	var status string
-	checkStatusQuery := `SELECT status FROM boq WHERE boq_id = $1`
-	err = tx.GetContext(ctx, &status, checkStatusQuery, boqID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("boq not found")
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
 		}
-		return fmt.Errorf("failed to get BOQ status: %w", err)
-	}
 
-	if status != "draft" {
-		return errors.New("can only delete jobs from BOQ in draft status")
-	}
+		// Soft-delete the BOQ job. Its material price logs are kept and stay
+		// hidden with it until the job is restored.
+		deleteBOQJobQuery := `
+        UPDATE boq_job
+        SET deleted_at = CURRENT_TIMESTAMP
+        WHERE boq_id = $1
+        AND job_id = $2
+        AND deleted_at IS NULL`
+
+		result, err := tx.ExecContext(ctx, deleteBOQJobQuery, boqID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to delete job from BOQ: %w", err)
+		}
+
+		// Check if the BOQ job was actually deleted
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return repositories.ErrJobNotInBOQ
+		}
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionDeleteJob, map[string]uuid.UUID{"job_id": jobID}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
 
-	// Delete related material price logs first (foreign key constraint)
-	deleteMaterialPriceLogQuery := `
-        DELETE FROM material_price_log 
-        WHERE boq_id = $1 
+// PurgeBOQJob permanently removes a job, live or soft-deleted, from a draft
+// BOQ together with its own material price logs. Logs of other jobs in the
+// BOQ that use the same materials are left untouched.
+func (r *boqRepository) PurgeBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		// Delete the job's price logs first (foreign key constraint)
+		deleteMaterialPriceLogQuery := `
+        DELETE FROM material_price_log
+        WHERE boq_id = $1
         AND job_id = $2`
 
-	_, err = tx.ExecContext(ctx, deleteMaterialPriceLogQuery, boqID, jobID)
-	if err != nil {
-		return fmt.Errorf("failed to delete material price logs: %w", err)
-	}
+		_, err := tx.ExecContext(ctx, deleteMaterialPriceLogQuery, boqID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to delete material price logs: %w", err)
+		}
 
-	// Then delete the BOQ job
-	deleteBOQJobQuery := `
-        DELETE FROM boq_job 
-        WHERE boq_id = $1 
+		deleteBOQJobQuery := `
+        DELETE FROM boq_job
+        WHERE boq_id = $1
         AND job_id = $2`
 
-	result, err := tx.ExecContext(ctx, deleteBOQJobQuery, boqID, jobID)
-	if err != nil {
-		return fmt.Errorf("failed to delete job from BOQ: %w", err)
-	}
+		result, err := tx.ExecContext(ctx, deleteBOQJobQuery, boqID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to delete job from BOQ: %w", err)
+		}
 
-	// Check if the BOQ job was actually deleted
-	rows, err := result.RowsAffected()
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return repositories.ErrJobNotInBOQ
+		}
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		diff := map[string]interface{}{"job_id": jobID, "purge": true}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionDeleteJob, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ClearBOQJobs permanently removes every job of a draft BOQ, soft-deleted
+// ones included, together with all of the BOQ's material price logs, and
+// returns how many job rows were removed. Either everything goes or nothing
+// does, and a stale expectedVersion fails even when there is nothing to
+// remove.
+func (r *boqRepository) ClearBOQJobs(ctx context.Context, boqID uuid.UUID, expectedVersion int) (int, error) {
+	var removed int
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		// Delete the price logs first (foreign key constraint)
+		_, err := tx.ExecContext(ctx, `DELETE FROM material_price_log WHERE boq_id = $1`, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to delete material price logs: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM boq_job WHERE boq_id = $1`, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to delete BOQ jobs: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return nil
+		}
+		removed = int(rows)
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionClearJobs, map[string]int{"removed": removed}); err != nil {
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
+		return 0, err
 	}
 
-	if rows == 0 {
-		return errors.New("job not found in BOQ")
-	}
+	return removed, nil
+}
+
+// RestoreBOQJob brings back a soft-deleted job of a draft BOQ together with
+// the material price logs it had, so nothing needs to be re-priced.
+func (r *boqRepository) RestoreBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if err := r.checkJobLimit(ctx, tx, boqID, 1); err != nil {
+			return err
+		}
+
+		restoreQuery := `
+        UPDATE boq_job
+        SET deleted_at = NULL
+        WHERE boq_id = $1
+        AND job_id = $2
+        AND deleted_at IS NOT NULL`
+
+		result, err := tx.ExecContext(ctx, restoreQuery, boqID, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to restore job: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return fmt.Errorf("deleted %w", repositories.ErrJobNotInBOQ)
+		}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionRestoreJob, map[string]uuid.UUID{"job_id": jobID}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *boqRepository) SetJobSellingPrice(ctx context.Context, boqID uuid.UUID, req requests.JobSellingPrice, expectedVersion int) error {
+	if req.SellingPrice < 0 {
+		return fmt.Errorf("%w: selling price cannot be negative", repositories.ErrInvalidInput)
 	}
 
-	return nil
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		query := `
+        UPDATE boq_job
+        SET selling_price = $1
+        WHERE boq_id = $2 AND job_id = $3 AND deleted_at IS NULL`
+
+		result, err := tx.ExecContext(ctx, query, req.SellingPrice, boqID, req.JobID)
+		if err != nil {
+			return fmt.Errorf("failed to update job selling price: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return repositories.ErrJobNotInBOQ
+		}
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		diff := map[string]interface{}{"job_id": req.JobID, "selling_price": req.SellingPrice}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionUpdateJob, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *boqRepository) SetJobProvisional(ctx context.Context, boqID uuid.UUID, req requests.JobProvisionalRequest, expectedVersion int) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		query := `
+        UPDATE boq_job
+        SET is_provisional = $1
+        WHERE boq_id = $2 AND job_id = $3 AND deleted_at IS NULL`
+
+		result, err := tx.ExecContext(ctx, query, req.IsProvisional, boqID, req.JobID)
+		if err != nil {
+			return fmt.Errorf("failed to update job provisional flag: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return repositories.ErrJobNotInBOQ
+		}
+
+		if err := advanceBOQVersion(ctx, tx, boqID, expectedVersion); err != nil {
+			return err
+		}
+
+		diff := map[string]interface{}{"job_id": req.JobID, "is_provisional": req.IsProvisional}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionUpdateJob, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
 }
 
 func (r *boqRepository) GetBOQGeneralCosts(ctx context.Context, boqID uuid.UUID) ([]models.BOQGeneralCost, error) {
+	var result []models.BOQGeneralCost
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = getBOQGeneralCosts(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func getBOQGeneralCosts(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) ([]models.BOQGeneralCost, error) {
 	query := `
         SELECT b.boq_id, gc.type_name, gc.estimated_cost 
         FROM boq b 
@@ -435,7 +1386,7 @@ func (r *boqRepository) GetBOQGeneralCosts(ctx context.Context, boqID uuid.UUID)
         WHERE b.boq_id = $1`
 
 	var costs []models.BOQGeneralCost
-	err := r.db.SelectContext(ctx, &costs, query, boqID)
+	err := sqlx.SelectContext(ctx, q, &costs, query, boqID)
 	if err != nil {
 		return nil, err
 	}
@@ -460,15 +1411,15 @@ func (r *boqRepository) GetBOQDetails(ctx context.Context, projectID uuid.UUID)
             j.description, 
             bj.quantity, 
             j.unit, 
-            COALESCE(bj.labor_cost, 0) as labor_cost,
+            bj.labor_cost,
             mt.total_material_price as estimated_price,
-            (mt.total_material_price * bj.quantity) as total_estimated_price,
-            (COALESCE(bj.labor_cost, 0) * bj.quantity) as total_labour_cost,
-            ((mt.total_material_price * bj.quantity) + (COALESCE(bj.labor_cost, 0) * bj.quantity)) as total
+            (mt.total_material_price * COALESCE(bj.quantity, 0)) as total_estimated_price,
+            (COALESCE(bj.labor_cost, 0) * COALESCE(bj.quantity, 0)) as total_labour_cost,
+            ((mt.total_material_price * COALESCE(bj.quantity, 0)) + (COALESCE(bj.labor_cost, 0) * COALESCE(bj.quantity, 0))) as total
         FROM project p 
         JOIN boq b ON b.project_id = p.project_id 
         LEFT JOIN client c ON c.client_id = p.project_id
-        JOIN boq_job bj ON bj.boq_id = b.boq_id 
+        JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
         JOIN job j ON j.job_id = bj.job_id 
         LEFT JOIN MaterialTotals mt ON mt.job_id = bj.job_id AND mt.boq_id = bj.boq_id 
         WHERE p.project_id = $1 
@@ -477,9 +1428,15 @@ func (r *boqRepository) GetBOQDetails(ctx context.Context, projectID uuid.UUID)
             bj.quantity, j.unit, bj.labor_cost, mt.total_material_price`
 
 	var details []models.BOQDetails
-	err := r.db.SelectContext(ctx, &details, query, projectID)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &details, query, projectID); err != nil {
+			return fmt.Errorf("failed to get BOQ details: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get BOQ details: %w", err)
+		return nil, err
 	}
 
 	return details, nil
@@ -498,17 +1455,185 @@ func (r *boqRepository) GetBOQMaterialDetails(ctx context.Context, projectID uui
         FROM project p 
         JOIN boq b ON b.project_id = p.project_id 
         LEFT JOIN client c ON c.client_id = p.project_id 
-        JOIN boq_job bj ON bj.boq_id = b.boq_id 
+        JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
         JOIN job j ON j.job_id = bj.job_id 
         LEFT JOIN material_price_log mpl ON mpl.job_id = bj.job_id AND mpl.boq_id = bj.boq_id 
         JOIN material m ON m.material_id = mpl.material_id 
         WHERE p.project_id = $1`
 
 	var details []models.BOQMaterialDetails
-	err := r.db.SelectContext(ctx, &details, query, projectID)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &details, query, projectID); err != nil {
+			return fmt.Errorf("failed to get material details: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get material details: %w", err)
+		return nil, err
 	}
 
 	return details, nil
 }
+
+func (r *boqRepository) GetRequiredJobs(ctx context.Context, projectType string) ([]responses.RequiredJobResponse, error) {
+	query := `
+        SELECT j.job_id, j.name, j.unit
+        FROM project_type_required_job ptr
+        JOIN job j ON j.job_id = ptr.job_id
+        WHERE ptr.project_type = $1
+        ORDER BY j.name`
+
+	jobs := []responses.RequiredJobResponse{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &jobs, query, projectType); err != nil {
+			return fmt.Errorf("failed to get required jobs: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r *boqRepository) AddRequiredJob(ctx context.Context, projectType string, jobID uuid.UUID) error {
+	if projectType == "" {
+		return fmt.Errorf("%w: project type is required", repositories.ErrInvalidInput)
+	}
+
+	query := `
+        INSERT INTO project_type_required_job (project_type, job_id)
+        VALUES ($1, $2)
+        ON CONFLICT (project_type, job_id) DO NOTHING`
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, projectType, jobID); err != nil {
+			return fmt.Errorf("failed to add required job: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *boqRepository) RemoveRequiredJob(ctx context.Context, projectType string, jobID uuid.UUID) error {
+	query := `
+        DELETE FROM project_type_required_job
+        WHERE project_type = $1 AND job_id = $2`
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, query, projectType, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to remove required job: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rows == 0 {
+			return fmt.Errorf("required %w", repositories.ErrJobNotFound)
+		}
+
+		return nil
+	})
+}
+
+func (r *boqRepository) ValidateRequiredJobs(ctx context.Context, boqID uuid.UUID) (*responses.RequiredJobCheckResponse, error) {
+	var result *responses.RequiredJobCheckResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.checkRequiredJobs(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// checkRequiredJobs compares the BOQ's jobs against the checklist for its
+// project's type. A project without a type has nothing to check.
+func (r *boqRepository) checkRequiredJobs(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (*responses.RequiredJobCheckResponse, error) {
+	var projectType sql.NullString
+	projectTypeQuery := `
+        SELECT p.project_type
+        FROM boq b
+        JOIN project p ON p.project_id = b.project_id
+        WHERE b.boq_id = $1`
+
+	err := sqlx.GetContext(ctx, q, &projectType, projectTypeQuery, boqID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrBOQNotFound
+		}
+		return nil, fmt.Errorf("failed to get project type: %w", err)
+	}
+
+	check := &responses.RequiredJobCheckResponse{
+		BOQID:       boqID,
+		ProjectType: projectType.String,
+		Complete:    true,
+		MissingJobs: []responses.RequiredJobResponse{},
+	}
+	if !projectType.Valid || projectType.String == "" {
+		return check, nil
+	}
+
+	missingQuery := `
+        SELECT j.job_id, j.name, j.unit
+        FROM project_type_required_job ptr
+        JOIN job j ON j.job_id = ptr.job_id
+        WHERE ptr.project_type = $1
+        AND NOT EXISTS (
+            SELECT 1 FROM boq_job bj
+            WHERE bj.boq_id = $2 AND bj.job_id = ptr.job_id AND bj.deleted_at IS NULL
+        )
+        ORDER BY j.name`
+
+	err = sqlx.SelectContext(ctx, q, &check.MissingJobs, missingQuery, projectType.String, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get missing required jobs: %w", err)
+	}
+
+	check.Complete = len(check.MissingJobs) == 0
+
+	return check, nil
+}
+
+// getBOQJobMaterials fetches the material template of every job in the BOQ in
+// one query and groups it by job_id.
+func (r *boqRepository) getBOQJobMaterials(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (map[uuid.UUID][]responses.JobMaterialItem, error) {
+	query := `
+        SELECT
+            jm.job_id,
+            m.material_id,
+            m.name,
+            m.unit,
+            jm.quantity
+        FROM boq_job bj
+        JOIN job_material jm ON jm.job_id = bj.job_id
+        JOIN material m ON m.material_id = jm.material_id
+        WHERE bj.boq_id = $1 AND bj.deleted_at IS NULL
+        ORDER BY jm.job_id, m.name`
+
+	type jobMaterial struct {
+		JobID uuid.UUID `db:"job_id"`
+		responses.JobMaterialItem
+	}
+	var rows []jobMaterial
+	if err := sqlx.SelectContext(ctx, q, &rows, query, boqID); err != nil {
+		return nil, fmt.Errorf("failed to get job materials: %w", err)
+	}
+
+	materials := make(map[uuid.UUID][]responses.JobMaterialItem)
+	for _, row := range rows {
+		materials[row.JobID] = append(materials[row.JobID], row.JobMaterialItem)
+	}
+
+	return materials, nil
+}
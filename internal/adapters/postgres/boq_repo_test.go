@@ -0,0 +1,3521 @@
+package postgres_test
+
+import (
+	"boonkosang/internal/adapters/postgres"
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureStdout runs fn and returns everything it wrote to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+
+	require.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(out)
+}
+
+func TestBOQRepositoryGetBoqWithProject(t *testing.T) {
+	projectID := uuid.New()
+	boqID := uuid.New()
+	jobID := uuid.New()
+	bareJobID := uuid.New()
+	createdAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 3, 4, 16, 30, 0, 0, time.UTC)
+
+	expectFetch := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT\s+boq_id, project_id, status, selling_general_cost`).
+			WithArgs(projectID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "project_id", "status", "selling_general_cost", "version", "created_at", "updated_at"}).
+				AddRow(boqID, projectID, "draft", 1500.0, 3, createdAt, updatedAt))
+		mock.ExpectQuery(`FROM job j`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "description", "unit", "quantity", "labor_cost"}).
+				AddRow(jobID, "Foundation", "Concrete footing", "m3", 12.0, 450.0).
+				AddRow(bareJobID, "Site survey", nil, "ls", 1.0, 800.0))
+		mock.ExpectQuery(`JOIN job_material jm`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "material_id", "name", "unit", "quantity"}).
+				AddRow(jobID, "MAT-CEMENT", "Cement", "bag", 7.5).
+				AddRow(jobID, "MAT-SAND", "Sand", "m3", 0.5))
+		mock.ExpectQuery(`FROM boq_attachment`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"attachment_id", "boq_id", "job_id", "attachment_type", "reference", "created_at"}).
+				AddRow(uuid.New(), boqID, nil, "drawing", "https://example.com/plan.pdf", time.Now()))
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		name         string
+		stdoutLogger bool
+	}{
+		{name: "Default logger"},
+		{name: "Info level logger on stdout", stdoutLogger: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			expectFetch(mock)
+
+			out := captureStdout(t, func() {
+				// Built inside the capture so a handler bound to os.Stdout
+				// writes to the pipe.
+				var opts []postgres.BOQRepositoryOption
+				if tt.stdoutLogger {
+					handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+					opts = append(opts, postgres.WithLogger(slog.New(handler)))
+				}
+				repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"), opts...)
+
+				response, err := repo.GetBoqWithProject(context.Background(), projectID)
+				require.NoError(t, err)
+				assert.Equal(t, boqID, response.ID)
+				require.Len(t, response.Jobs, 2)
+				require.Len(t, response.Jobs[0].Materials, 2)
+				assert.Equal(t, "MAT-CEMENT", response.Jobs[0].Materials[0].MaterialID)
+				assert.InDelta(t, 7.5, response.Jobs[0].Materials[0].Quantity, 0.001)
+				assert.NotNil(t, response.Jobs[1].Materials)
+				assert.Empty(t, response.Jobs[1].Materials)
+				assert.Equal(t, 3, response.Version)
+				assert.Equal(t, createdAt, response.CreatedAt)
+				assert.Equal(t, updatedAt, response.UpdatedAt)
+
+				body, err := json.Marshal(response)
+				require.NoError(t, err)
+				assert.Contains(t, string(body), `"updated_at":"2024-03-04T16:30:00Z"`)
+				assert.Contains(t, string(body), `"materials":[]`)
+			})
+
+			assert.Empty(t, out)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBOQRepositoryGetBoqWithProjectEmpty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	projectID := uuid.New()
+	boqID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT\s+boq_id, project_id, status, selling_general_cost`).
+		WithArgs(projectID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "project_id", "status", "selling_general_cost"}).
+			AddRow(boqID, projectID, "draft", nil))
+	mock.ExpectQuery(`FROM job j`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "description", "unit", "quantity", "labor_cost"}))
+	mock.ExpectQuery(`JOIN job_material jm`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "material_id", "name", "unit", "quantity"}))
+	mock.ExpectQuery(`FROM boq_attachment`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"attachment_id", "boq_id", "job_id", "attachment_type", "reference", "created_at"}))
+	mock.ExpectCommit()
+
+	response, err := repo.GetBoqWithProject(context.Background(), projectID)
+	require.NoError(t, err)
+
+	assert.NotNil(t, response.Jobs)
+	assert.Empty(t, response.Jobs)
+
+	body, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"jobs":[]`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryPurgeBOQJob(t *testing.T) {
+	boqID := uuid.New()
+	concreteJobID := uuid.New()
+
+	t.Run("Success - Keeps shared material logs of other jobs", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		// The concrete job and a brick job of the BOQ both log the same cement
+		// material. The only price-log statement allowed is the anchored delete
+		// pinned to the concrete job: one keyed on the material, or any other
+		// write to material_price_log, does not match and fails the test, so
+		// the brick job's cement row survives.
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectExec(`^\s*DELETE FROM material_price_log\s+WHERE boq_id = \$1\s+AND job_id = \$2\s*$`).
+			WithArgs(boqID, concreteJobID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM boq_job\s+WHERE boq_id = \$1\s+AND job_id = \$2`).
+			WithArgs(boqID, concreteJobID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), boqID, "delete_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.PurgeBOQJob(context.Background(), boqID, concreteJobID, 3)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failure - Approved BOQ", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+		mock.ExpectRollback()
+
+		err = repo.PurgeBOQJob(context.Background(), boqID, concreteJobID, 3)
+		assert.ErrorIs(t, err, repositories.ErrBOQNotDraft)
+		assert.ErrorContains(t, err, "current status is approved")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryGetBoqWithProjectNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	projectID := uuid.New()
+
+	// No INSERT is expected: a missing BOQ must not be created on read.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT\s+boq_id, project_id, status, selling_general_cost`).
+		WithArgs(projectID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	response, err := repo.GetBoqWithProject(context.Background(), projectID)
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, repositories.ErrBOQNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryExportBOQ(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	foundationID := uuid.New()
+	wallID := uuid.New()
+
+	costColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+	costRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(costColumns).
+			AddRow(foundationID, "Foundation, footing", "m3", 12.5, 450.0, nil, 1200.0, 0, false).
+			AddRow(wallID, "Wall\nplaster \"smooth\"", "m2", 40.0, 85.0, nil, 62.25, 1, false)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(12.5))
+	mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1500.0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(costRows())
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT p.name, p.address, b.preliminaries_percent`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "address", "preliminaries_percent", "selling_general_cost", "status"}).
+			AddRow("Riverside House", nil, 12.5, 1500.0, "draft"))
+	mock.ExpectQuery(`JOIN general_cost gc`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "type_name", "estimated_cost"}).
+			AddRow(boqID, "Site office", 1000.0).
+			AddRow(boqID, "Insurance", 500.0))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(costRows())
+	mock.ExpectQuery(`FROM material_price_log mpl`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "material_name", "quantity", "unit", "estimated_price", "total"}))
+	mock.ExpectCommit()
+
+	export, err := repo.ExportBOQ(context.Background(), boqID)
+	require.NoError(t, err)
+
+	summary, err := repo.GetBOQSummary(context.Background(), boqID)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(bytes.NewReader(export)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 1+len(summary.Details)+3)
+
+	for i, detail := range summary.Details {
+		record := records[i+1]
+		assert.Equal(t, detail.JobName, record[0])
+		assert.Equal(t, detail.TotalLaborCost.String(), record[3])
+		assert.Equal(t, detail.TotalEstimatedPrice.String(), record[4])
+		assert.Equal(t, detail.Total.String(), record[5])
+	}
+
+	preliminaries := records[len(records)-3]
+	assert.Equal(t, "Preliminaries", preliminaries[0])
+	assert.NotZero(t, summary.SummaryMetrics.TotalPreliminaries)
+	assert.Equal(t, summary.SummaryMetrics.TotalPreliminaries.String(), preliminaries[5])
+
+	generalCost := records[len(records)-2]
+	assert.Equal(t, "General Cost", generalCost[0])
+	assert.Equal(t, summary.SummaryMetrics.TotalGeneralCost.String(), generalCost[5])
+
+	grandTotal := records[len(records)-1]
+	assert.Equal(t, "Grand Total", grandTotal[0])
+	assert.Equal(t, summary.SummaryMetrics.GrandTotal.String(), grandTotal[5])
+	assert.Equal(t, summary.SummaryMetrics.TotalAmount+summary.SummaryMetrics.TotalPreliminaries+summary.SummaryMetrics.TotalGeneralCost,
+		summary.SummaryMetrics.GrandTotal)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryUpdateBOQJobQuantity(t *testing.T) {
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	t.Run("Success - Material totals follow the job quantity", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		// Only boq_job changes: an unexpected write to material_price_log
+		// fails the test, since its quantities are per unit of job.
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectExec(`UPDATE boq_job\s+SET quantity = \$1, labor_cost = \$2`).
+			WithArgs(4.0, 300.0, boqID, jobID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), boqID, "update_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.UpdateBOQJobQuantity(context.Background(), boqID, jobID, 4, 300, 3)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		// 2.5 units of a 50.00 material per unit of job, now for 4 units.
+		mock.ExpectQuery(`SELECT p.name, p.address, b.preliminaries_percent`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "address", "preliminaries_percent", "selling_general_cost", "status"}).
+				AddRow("Riverside House", nil, nil, nil, "draft"))
+		mock.ExpectQuery(`JOIN general_cost gc`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "type_name", "estimated_cost"}))
+		mock.ExpectQuery(`FROM boq_job bj`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+				AddRow(jobID, "Brick wall", "m2", 4.0, 300.0, nil, 125.0, 0, false))
+		mock.ExpectQuery(`FROM material_price_log mpl`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "material_name", "quantity", "unit", "estimated_price", "total"}).
+				AddRow(jobID, "Brick wall", "Brick", 2.5, "pcs", 50.0, 125.0))
+		mock.ExpectCommit()
+
+		summary, err := repo.GetBOQSummary(context.Background(), boqID)
+		require.NoError(t, err)
+		require.Len(t, summary.Details, 1)
+		assert.Equal(t, models.MoneyFromFloat(500), summary.Details[0].TotalEstimatedPrice)
+		assert.Equal(t, models.MoneyFromFloat(1200), summary.Details[0].TotalLaborCost)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Success - Material quantities scale with the job quantity", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectExec(`UPDATE boq_job\s+SET quantity = \$1, labor_cost = \$2`).
+			WithArgs(4.0, 300.0, boqID, jobID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), boqID, "update_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.UpdateBOQJobQuantity(context.Background(), boqID, jobID, 4, 300, 3)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		// The log keeps 2.5 bricks per unit of job; the totals query must
+		// scale it by the job quantity, which is now 4.
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM boq WHERE boq_id = \$1\)`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(`COALESCE\(mpl.quantity, 0\) \* COALESCE\(bj.quantity, 0\) as quantity`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"material_id", "name", "unit", "quantity", "unit_price"}).
+				AddRow("MAT-BRICK", "Brick", "pcs", 10.0, 50.0))
+		mock.ExpectCommit()
+
+		totals, err := repo.GetBOQMaterialTotals(context.Background(), boqID)
+		require.NoError(t, err)
+		require.Len(t, totals, 1)
+		assert.Equal(t, 10.0, totals[0].Quantity)
+		assert.Equal(t, models.MoneyFromFloat(500.0), totals[0].Total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failure - Non-positive quantity", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		err = repo.UpdateBOQJobQuantity(context.Background(), boqID, jobID, 0, 300, 3)
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+		assert.ErrorContains(t, err, "quantity must be a positive number")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failure - Approved BOQ", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+		mock.ExpectRollback()
+
+		err = repo.UpdateBOQJobQuantity(context.Background(), boqID, jobID, 4, 300, 3)
+		assert.ErrorIs(t, err, repositories.ErrBOQNotDraft)
+		assert.ErrorContains(t, err, "current status is approved")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failure - Stale version", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		// Someone else edited the BOQ since version 3 was read, so the
+		// quantity change is rolled back.
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectExec(`UPDATE boq_job\s+SET quantity = \$1, labor_cost = \$2`).
+			WithArgs(4.0, 300.0, boqID, jobID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID, 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err = repo.UpdateBOQJobQuantity(context.Background(), boqID, jobID, 4, 300, 3)
+		assert.ErrorIs(t, err, repositories.ErrStaleBOQ)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryQueryTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"), postgres.WithQueryTimeout(250*time.Millisecond))
+	projectID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 250`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT\s+boq_id, project_id, status, selling_general_cost`).
+		WithArgs(projectID).
+		WillReturnError(&pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"})
+	mock.ExpectRollback()
+
+	response, err := repo.GetBoqWithProject(context.Background(), projectID)
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, repositories.ErrQueryTimeout)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryAddBOQJobUnknownJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	// Nothing is inserted: an unexpected write to boq_job or
+	// material_price_log fails the test.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT unit FROM job WHERE job_id = \$1`).
+		WithArgs(jobID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	err = repo.AddBOQJob(context.Background(), boqID, requests.BOQJobRequest{
+		JobID:     jobID,
+		Quantity:  2,
+		LaborCost: 150,
+	}, 3)
+	assert.ErrorIs(t, err, repositories.ErrJobNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryRetriesSerializationFailure(t *testing.T) {
+	boqID := uuid.New()
+
+	expectStatusChange := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE boq SET status = \$1`).
+			WithArgs("draft", boqID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	tests := []struct {
+		name    string
+		code    pq.ErrorCode
+		retried bool
+	}{
+		{name: "Serialization failure is retried", code: "40001", retried: true},
+		{name: "Deadlock is retried", code: "40P01", retried: true},
+		{name: "Unique violation is not retried", code: "23505"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"), postgres.WithRetryBackoff(0))
+
+			// The first attempt fails on commit; the retry runs the whole
+			// closure again, status check included.
+			expectStatusChange(mock)
+			mock.ExpectCommit().WillReturnError(&pq.Error{Code: tt.code, Message: "could not serialize access"})
+			if tt.retried {
+				expectStatusChange(mock)
+				mock.ExpectCommit()
+			}
+
+			err = repo.UpdateBOQStatus(context.Background(), boqID, "draft", 2)
+			if tt.retried {
+				assert.NoError(t, err)
+			} else {
+				var pqErr *pq.Error
+				require.ErrorAs(t, err, &pqErr)
+				assert.Equal(t, tt.code, pqErr.Code)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBOQRepositoryDeleteBOQJobKeepsSharedMaterialLogs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	concreteJobID := uuid.New()
+
+	// The concrete and brick jobs both log cement. Deleting the concrete job
+	// only marks its boq_job row; any write to material_price_log does not
+	// match and fails the test, so the brick job's cement row survives.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE boq_job\s+SET deleted_at = CURRENT_TIMESTAMP\s+WHERE boq_id = \$1\s+AND job_id = \$2`).
+		WithArgs(boqID, concreteJobID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), boqID, "delete_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.DeleteBOQJob(context.Background(), boqID, concreteJobID, 1)
+	require.NoError(t, err)
+
+	// The brick job's cement still totals; the deleted job's rows are joined
+	// away by the live-job filter.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM boq WHERE boq_id = \$1\)`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`JOIN boq_job bj ON .+ AND bj.deleted_at IS NULL`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"material_id", "name", "unit", "quantity", "unit_price"}).
+			AddRow("MAT-CEMENT", "Cement", "bag", 20.0, 40.0))
+	mock.ExpectCommit()
+
+	totals, err := repo.GetBOQMaterialTotals(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, "MAT-CEMENT", totals[0].MaterialID)
+	assert.Equal(t, models.MoneyFromFloat(800), totals[0].Total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryDeleteBOQJobNotInBOQ(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE boq_job\s+SET deleted_at = CURRENT_TIMESTAMP`).
+		WithArgs(boqID, jobID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.DeleteBOQJob(context.Background(), boqID, jobID, 1)
+	assert.ErrorIs(t, err, repositories.ErrJobNotInBOQ)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetBOQSummaryApprovedUsesSnapshot(t *testing.T) {
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	costColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+	materialColumns := []string{"job_id", "name", "material_name", "quantity", "unit", "estimated_price", "total"}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	// The cost and material queries must read material_price_snapshot: a
+	// query on the live log does not match and fails the test, so a price
+	// edited in material_price_log after approval cannot reach the summary.
+	mock.ExpectQuery(`SELECT p.name, p.address, b.preliminaries_percent, b.selling_general_cost, b.status`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "address", "preliminaries_percent", "selling_general_cost", "status"}).
+			AddRow("Riverside House", nil, nil, nil, "approved"))
+	mock.ExpectQuery(`JOIN general_cost gc`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "type_name", "estimated_cost"}))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM material_price_snapshot`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`LEFT JOIN \(\s+SELECT .+ FROM material_price_snapshot s`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows(costColumns).
+			AddRow(jobID, "Brick wall", "m2", 4.0, 300.0, nil, 125.0, 0, false))
+	mock.ExpectQuery(`FROM \(\s+SELECT .+ FROM material_price_snapshot s`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows(materialColumns).
+			AddRow(jobID, "Brick wall", "Brick", 2.5, "pcs", 50.0, 125.0))
+	mock.ExpectCommit()
+
+	summary, err := repo.GetBOQSummary(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, summary.Details, 1)
+	assert.Equal(t, models.MoneyFromFloat(500), summary.SummaryMetrics.TotalMaterialCost)
+	assert.Equal(t, models.MoneyFromFloat(1700), summary.SummaryMetrics.GrandTotal)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetBOQSummaryNullLaborCost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	legacyJobID := uuid.New()
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT p.name, p.address, b.preliminaries_percent, b.selling_general_cost, b.status`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "address", "preliminaries_percent", "selling_general_cost", "status"}).
+			AddRow("Riverside House", nil, nil, nil, "draft"))
+	mock.ExpectQuery(`JOIN general_cost gc`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "type_name", "estimated_cost"}))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(legacyJobID, "Legacy footing", "m3", 3.0, nil, nil, 0.0, 0, false).
+			AddRow(jobID, "Brick wall", "m2", 4.0, 300.0, nil, 0.0, 0, false))
+	mock.ExpectQuery(`FROM material_price_log mpl`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "material_name", "quantity", "unit", "estimated_price", "total"}))
+	mock.ExpectCommit()
+
+	summary, err := repo.GetBOQSummary(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, summary.Details, 2)
+
+	legacy := summary.Details[0]
+	assert.True(t, legacy.Incomplete)
+	assert.Zero(t, legacy.TotalLaborCost)
+	assert.Zero(t, legacy.Total)
+	assert.False(t, summary.Details[1].Incomplete)
+
+	assert.Equal(t, 1, summary.SummaryMetrics.IncompleteJobs)
+	assert.Equal(t, models.MoneyFromFloat(1200), summary.SummaryMetrics.GrandTotal)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetBoqWithProjectNullQuantityAndLaborCost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	projectID := uuid.New()
+	boqID := uuid.New()
+	legacyJobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT\s+boq_id, project_id, status, selling_general_cost`).
+		WithArgs(projectID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "project_id", "status", "selling_general_cost"}).
+			AddRow(boqID, projectID, "draft", nil))
+	mock.ExpectQuery(`FROM job j`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "description", "unit", "quantity", "labor_cost"}).
+			AddRow(legacyJobID, "Legacy footing", nil, "m3", nil, nil).
+			AddRow(uuid.New(), "Brick wall", nil, "m2", 4.0, 300.0))
+	mock.ExpectQuery(`JOIN job_material jm`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "material_id", "name", "unit", "quantity"}))
+	mock.ExpectQuery(`FROM boq_attachment`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"attachment_id", "boq_id", "job_id", "attachment_type", "reference", "created_at"}))
+	mock.ExpectCommit()
+
+	response, err := repo.GetBoqWithProject(context.Background(), projectID)
+	require.NoError(t, err)
+	require.Len(t, response.Jobs, 2)
+
+	legacy := response.Jobs[0]
+	assert.Equal(t, legacyJobID, legacy.JobID)
+	assert.Zero(t, legacy.Quantity)
+	assert.Zero(t, legacy.LaborCost)
+	assert.Equal(t, 4.0, response.Jobs[1].Quantity)
+	assert.Equal(t, models.MoneyFromFloat(300), response.Jobs[1].LaborCost)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetBOQSummaryTotalsAreExact(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT p.name, p.address, b.preliminaries_percent, b.selling_general_cost, b.status`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "address", "preliminaries_percent", "selling_general_cost", "status"}).
+			AddRow("Riverside House", nil, nil, nil, "draft"))
+	mock.ExpectQuery(`JOIN general_cost gc`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "type_name", "estimated_cost"}))
+
+	// Ten lines at 0.10 sum to 0.9999999999999999 in float64.
+	jobRows := sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"})
+	for i := 0; i < 10; i++ {
+		jobRows.AddRow(uuid.New(), "Sealant", "m", 1.0, []byte("0.10"), nil, 0.0, 0, false)
+	}
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(jobRows)
+	mock.ExpectQuery(`FROM material_price_log mpl`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "material_name", "quantity", "unit", "estimated_price", "total"}))
+	mock.ExpectCommit()
+
+	summary, err := repo.GetBOQSummary(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, summary.Details, 10)
+
+	assert.Equal(t, "1.00", summary.SummaryMetrics.TotalLaborCost.String())
+	assert.Equal(t, "1.00", summary.SummaryMetrics.GrandTotal.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetBOQSummaryUsesSellingGeneralCost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT p.name, p.address, b.preliminaries_percent, b.selling_general_cost, b.status`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "address", "preliminaries_percent", "selling_general_cost", "status"}).
+			AddRow("Riverside House", nil, nil, 300.0, "draft"))
+	mock.ExpectQuery(`JOIN general_cost gc`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "type_name", "estimated_cost"}).
+			AddRow(boqID, "Site office", 1000.0))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(uuid.New(), "Screed", "m2", 2.5, 100.0, nil, 0.0, 0, false))
+	mock.ExpectQuery(`FROM material_price_log mpl`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "material_name", "quantity", "unit", "estimated_price", "total"}))
+	mock.ExpectCommit()
+
+	summary, err := repo.GetBOQSummary(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, summary.Details, 1)
+	assert.Equal(t, 2.5, summary.Details[0].Quantity)
+
+	// The estimate is listed, but the selling general cost is what is charged.
+	require.Len(t, summary.GeneralCosts, 1)
+	assert.Equal(t, models.MoneyFromFloat(300), summary.SummaryMetrics.TotalGeneralCost)
+	assert.Equal(t, models.MoneyFromFloat(550), summary.SummaryMetrics.GrandTotal)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryMoveBOQJobKeepsPrices(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	fromBOQID := uuid.New()
+	toBOQID := uuid.New()
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT boq_id, status FROM boq WHERE boq_id IN \(\$1, \$2\) ORDER BY boq_id FOR UPDATE`).
+		WithArgs(fromBOQID, toBOQID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).
+			AddRow(fromBOQID, "draft").
+			AddRow(toBOQID, "draft"))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM boq_job WHERE boq_id = \$1 AND job_id = \$2\)`).
+		WithArgs(toBOQID, jobID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM boq_job`).
+		WithArgs(toBOQID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectExec(`INSERT INTO boq_job \(boq_id, job_id, quantity, labor_cost, selling_price, is_provisional\) SELECT \$1, job_id, quantity, labor_cost`).
+		WithArgs(toBOQID, fromBOQID, jobID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Prices are copied as they are, not reset.
+	mock.ExpectExec(`INSERT INTO material_price_log .* SELECT material_id, \$1, job_id, quantity, estimated_price, actual_price,`).
+		WithArgs(toBOQID, fromBOQID, jobID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM material_price_log WHERE boq_id = \$1 AND job_id = \$2`).
+		WithArgs(fromBOQID, jobID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM boq_job WHERE boq_id = \$1 AND job_id = \$2`).
+		WithArgs(fromBOQID, jobID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(fromBOQID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(toBOQID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), fromBOQID, "move_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), toBOQID, "move_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.MoveBOQJob(context.Background(), fromBOQID, toBOQID, jobID)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryMoveBOQJobTargetHasJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	fromBOQID := uuid.New()
+	toBOQID := uuid.New()
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT boq_id, status FROM boq`).
+		WithArgs(fromBOQID, toBOQID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).
+			AddRow(fromBOQID, "draft").
+			AddRow(toBOQID, "draft"))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM boq_job`).
+		WithArgs(toBOQID, jobID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = repo.MoveBOQJob(context.Background(), fromBOQID, toBOQID, jobID)
+	assert.ErrorIs(t, err, repositories.ErrJobAlreadyInBOQ)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositorySetJobSellingPriceAuditsInSameTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobID := uuid.New()
+	actorID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE boq_job\s+SET selling_price = \$1`).
+		WithArgs(950.0, boqID, jobID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), boqID, "update_job", uuid.NullUUID{UUID: actorID, Valid: true}, sqlmock.AnyArg()).
+		WillReturnError(errors.New("disk full"))
+	// The price change must not be committed without its audit row.
+	mock.ExpectRollback()
+
+	ctx := repositories.WithActor(context.Background(), actorID)
+	err = repo.SetJobSellingPrice(ctx, boqID, requests.JobSellingPrice{JobID: jobID, SellingPrice: 950}, 3)
+	assert.ErrorContains(t, err, "failed to write BOQ audit")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetBOQAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	actorID := uuid.New()
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+	mock.ExpectQuery(`FROM boq_audit\s+WHERE boq_id = \$1\s+ORDER BY created_at, audit_id`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"audit_id", "action", "actor_id", "diff", "created_at"}).
+			AddRow(uuid.New(), "add_job", actorID, []byte(`{"quantity": 4}`), at).
+			AddRow(uuid.New(), "price_update", nil, []byte(`{"estimated_price": 120}`), at.Add(time.Minute)))
+	mock.ExpectCommit()
+
+	entries, err := repo.GetBOQAudit(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "add_job", entries[0].Action)
+	require.NotNil(t, entries[0].ActorID)
+	assert.Equal(t, actorID, *entries[0].ActorID)
+	assert.JSONEq(t, `{"quantity": 4}`, string(entries[0].Diff))
+	assert.Equal(t, "price_update", entries[1].Action)
+	assert.Nil(t, entries[1].ActorID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryPruneOrphanedPriceLogs(t *testing.T) {
+	boqID := uuid.New()
+	orphanColumns := []string{"mpl_id", "job_id", "job_name", "material_id", "material_name", "quantity", "estimated_price"}
+
+	t.Run("removes logs without a boq_job row", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		// The postgres driver name makes Rebind emit $n placeholders.
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "postgres"))
+		mplID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		// One seeded orphan; only the listed row is deleted, so logs of live
+		// and soft-deleted jobs stay.
+		mock.ExpectQuery(`OR NOT EXISTS \( SELECT 1 FROM boq_job bj WHERE bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id \)`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows(orphanColumns).
+				AddRow(mplID, uuid.New(), "Wall", "M001", "Brick", 120.0, 3.5))
+		mock.ExpectExec(`DELETE FROM material_price_log WHERE boq_id = \$1 AND mpl_id IN \(\$2\)`).
+			WithArgs(boqID, mplID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), boqID, "clean_price_logs", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		removed, err := repo.PruneOrphanedPriceLogs(context.Background(), boqID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, removed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("skips BOQs that are not in draft", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+		mock.ExpectRollback()
+
+		removed, err := repo.PruneOrphanedPriceLogs(context.Background(), boqID)
+		require.NoError(t, err)
+		assert.Zero(t, removed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("fails for a missing BOQ", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err = repo.PruneOrphanedPriceLogs(context.Background(), boqID)
+		assert.ErrorIs(t, err, repositories.ErrBOQNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryClearBOQJobs(t *testing.T) {
+	boqID := uuid.New()
+
+	t.Run("removes every job, soft-deleted ones included, and its price logs", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectExec(`DELETE FROM material_price_log WHERE boq_id = \$1$`).
+			WithArgs(boqID).
+			WillReturnResult(sqlmock.NewResult(0, 7))
+		mock.ExpectExec(`DELETE FROM boq_job WHERE boq_id = \$1$`).
+			WithArgs(boqID).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), boqID, "clear_jobs", sqlmock.AnyArg(), []byte(`{"removed":3}`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		removed, err := repo.ClearBOQJobs(context.Background(), boqID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, removed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects a stale version when there is nothing to remove", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectExec(`DELETE FROM material_price_log WHERE boq_id = \$1$`).
+			WithArgs(boqID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`DELETE FROM boq_job WHERE boq_id = \$1$`).
+			WithArgs(boqID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID, 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		removed, err := repo.ClearBOQJobs(context.Background(), boqID, 3)
+		assert.ErrorIs(t, err, repositories.ErrStaleBOQ)
+		assert.Zero(t, removed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("refuses BOQs that are not in draft", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+		mock.ExpectRollback()
+
+		removed, err := repo.ClearBOQJobs(context.Background(), boqID, 3)
+		assert.ErrorIs(t, err, repositories.ErrBOQNotDraft)
+		assert.Zero(t, removed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryUpdateMaterialPriceUnknownMaterial(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE material_price_log\s+SET estimated_price = \$1`).
+		WithArgs(models.MoneyFromFloat(180), boqID, jobID, "MAT-STEEL").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.UpdateMaterialPrice(context.Background(), boqID, jobID, "MAT-STEEL", models.MoneyFromFloat(180), 2)
+	assert.ErrorIs(t, err, repositories.ErrMaterialNotInBOQ)
+	assert.ErrorContains(t, err, "MAT-STEEL")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetMaterialPriceLogsTotalsTheJobQuantity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+	// 2.5 bags per unit of job at 40.00 a bag, over 4 units of job.
+	mock.ExpectQuery(`COALESCE\(mpl.estimated_price, 0\) \* COALESCE\(mpl.fx_rate, 1\) \* COALESCE\(mpl.quantity, 0\) \* COALESCE\(bj.quantity, 0\) as total`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "job_name", "material_id", "material_name", "unit", "quantity", "unit_price", "total", "updated_at"}).
+			AddRow(jobID, "Wall", "MAT-CEMENT", "Cement", "bag", 2.5, "40.00", "400.00", nil))
+	mock.ExpectCommit()
+
+	logs, err := repo.GetMaterialPriceLogs(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, 2.5, logs[0].Quantity)
+	assert.Equal(t, models.NullMoney{Money: models.MoneyFromFloat(40), Valid: true}, logs[0].UnitPrice)
+	assert.Equal(t, models.MoneyFromFloat(400), logs[0].Total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryAddBOQJobResult(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT unit FROM job WHERE job_id = \$1`).
+		WithArgs(jobID).
+		WillReturnRows(sqlmock.NewRows([]string{"unit"}).AddRow("m2"))
+	mock.ExpectQuery(`SELECT\s+EXISTS`).
+		WithArgs(boqID, jobID).
+		WillReturnRows(sqlmock.NewRows([]string{"in_boq", "deleted"}).AddRow(false, false))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM boq_job`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`INSERT INTO boq_job .* ON CONFLICT \(boq_id, job_id\) DO NOTHING RETURNING quantity, labor_cost`).
+		WithArgs(boqID, jobID, 4.5, 300.0).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity", "labor_cost"}).AddRow(4.5, 300.0))
+	mock.ExpectQuery(`FROM job_material`).
+		WithArgs(jobID).
+		WillReturnRows(sqlmock.NewRows([]string{"material_id", "quantity"}).
+			AddRow("MAT-BRICK", 60.0).
+			AddRow("MAT-MORTAR", 0.03))
+	mock.ExpectQuery(`FROM boq_job bj\s+INNER JOIN material_price_log mpl`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"material_id", "estimated_price"}))
+	mock.ExpectExec(`INSERT INTO material_price_log`).
+		WithArgs("MAT-BRICK", boqID, jobID, 60.0, sql.NullFloat64{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO material_price_log`).
+		WithArgs("MAT-MORTAR", boqID, jobID, 0.03, sql.NullFloat64{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), boqID, "add_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	job, err := repo.AddBOQJobResult(context.Background(), boqID, requests.BOQJobRequest{
+		JobID:     jobID,
+		Quantity:  4.5,
+		LaborCost: 300,
+	}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, responses.BOQJobResponse{
+		BOQID:            boqID,
+		JobID:            jobID,
+		Quantity:         4.5,
+		LaborCost:        models.MoneyFromFloat(300),
+		PriceLogsCreated: 2,
+	}, *job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryAddBOQJobJobLimit(t *testing.T) {
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	tests := []struct {
+		name     string
+		existing int
+		wantErr  error
+	}{
+		{name: "Success - Reaches the limit", existing: 2},
+		{name: "Failure - One past the limit", existing: 3, wantErr: repositories.ErrBOQTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"), postgres.WithMaxJobsPerBOQ(3))
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+				WithArgs(boqID).
+				WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+			mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+				WithArgs(boqID, 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(`SELECT unit FROM job WHERE job_id = \$1`).
+				WithArgs(jobID).
+				WillReturnRows(sqlmock.NewRows([]string{"unit"}).AddRow("m2"))
+			mock.ExpectQuery(`SELECT\s+EXISTS`).
+				WithArgs(boqID, jobID).
+				WillReturnRows(sqlmock.NewRows([]string{"in_boq", "deleted"}).AddRow(false, false))
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM boq_job WHERE boq_id = \$1 AND deleted_at IS NULL`).
+				WithArgs(boqID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.existing))
+			if tt.wantErr == nil {
+				mock.ExpectQuery(`INSERT INTO boq_job`).
+					WithArgs(boqID, jobID, 1.0, 100.0).
+					WillReturnRows(sqlmock.NewRows([]string{"quantity", "labor_cost"}).AddRow(1.0, 100.0))
+				mock.ExpectQuery(`FROM job_material`).
+					WithArgs(jobID).
+					WillReturnRows(sqlmock.NewRows([]string{"material_id", "quantity"}))
+				mock.ExpectQuery(`FROM boq_job bj\s+INNER JOIN material_price_log mpl`).
+					WithArgs(boqID).
+					WillReturnRows(sqlmock.NewRows([]string{"material_id", "estimated_price"}))
+				mock.ExpectExec(`INSERT INTO boq_audit`).
+					WithArgs(sqlmock.AnyArg(), boqID, "add_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			err = repo.AddBOQJob(context.Background(), boqID, requests.BOQJobRequest{JobID: jobID, Quantity: 1, LaborCost: 100}, 1)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.ErrorContains(t, err, "limit is 3 jobs")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBOQRepositoryAddBOQJobsJobLimit(t *testing.T) {
+	boqID := uuid.New()
+	wallID := uuid.New()
+	roofID := uuid.New()
+	jobColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+
+	tests := []struct {
+		name     string
+		existing int
+		wantErr  error
+	}{
+		{name: "Success - Reaches the limit", existing: 1},
+		{name: "Failure - One past the limit", existing: 2, wantErr: repositories.ErrBOQTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"), postgres.WithMaxJobsPerBOQ(3))
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+				WithArgs(boqID).
+				WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+			mock.ExpectQuery(`FROM job j`).
+				WithArgs(boqID, sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"job_id", "unit", "in_boq", "deleted"}).
+					AddRow(wallID, "m2", false, false).
+					AddRow(roofID, "m2", false, false))
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM boq_job WHERE boq_id = \$1 AND deleted_at IS NULL`).
+				WithArgs(boqID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.existing))
+			if tt.wantErr == nil {
+				mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID).WillReturnRows(sqlmock.NewRows(jobColumns))
+				mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+					WithArgs(boqID).
+					WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(0.0))
+				mock.ExpectQuery(`SELECT preliminaries_percent FROM boq`).
+					WithArgs(boqID).
+					WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(nil))
+				mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows(jobColumns))
+				mock.ExpectExec(`INSERT INTO boq_job`).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`INSERT INTO material_price_log`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows(jobColumns))
+				mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+					WithArgs(boqID, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO boq_audit`).
+					WithArgs(sqlmock.AnyArg(), boqID, "add_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			_, err = repo.AddBOQJobs(context.Background(), boqID, []requests.BOQJobRequest{
+				{JobID: wallID, Quantity: 10, LaborCost: 50},
+				{JobID: roofID, Quantity: 5, LaborCost: 20},
+			}, 1)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.ErrorContains(t, err, "limit is 3 jobs")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBOQRepositoryMutationsRejectApprovedBOQ(t *testing.T) {
+	boqID := uuid.New()
+	jobID := uuid.New()
+	percent := 8.0
+
+	tests := []struct {
+		name   string
+		mutate func(repo repositories.BOQRepository) error
+	}{
+		{"AddBOQJob", func(repo repositories.BOQRepository) error {
+			return repo.AddBOQJob(context.Background(), boqID, requests.BOQJobRequest{JobID: jobID, Quantity: 1, LaborCost: 100}, 1)
+		}},
+		{"AddBOQJobs", func(repo repositories.BOQRepository) error {
+			_, err := repo.AddBOQJobs(context.Background(), boqID, []requests.BOQJobRequest{{JobID: jobID, Quantity: 1, LaborCost: 100}}, 3)
+			return err
+		}},
+		{"UpdateBOQJobQuantity", func(repo repositories.BOQRepository) error {
+			return repo.UpdateBOQJobQuantity(context.Background(), boqID, jobID, 2, 100, 3)
+		}},
+		{"DeleteBOQJob", func(repo repositories.BOQRepository) error {
+			return repo.DeleteBOQJob(context.Background(), boqID, jobID, 1)
+		}},
+		{"PurgeBOQJob", func(repo repositories.BOQRepository) error {
+			return repo.PurgeBOQJob(context.Background(), boqID, jobID, 3)
+		}},
+		{"RestoreBOQJob", func(repo repositories.BOQRepository) error {
+			return repo.RestoreBOQJob(context.Background(), boqID, jobID, 3)
+		}},
+		{"SetJobSellingPrice", func(repo repositories.BOQRepository) error {
+			return repo.SetJobSellingPrice(context.Background(), boqID, requests.JobSellingPrice{JobID: jobID, SellingPrice: 500}, 3)
+		}},
+		{"SetJobProvisional", func(repo repositories.BOQRepository) error {
+			return repo.SetJobProvisional(context.Background(), boqID, requests.JobProvisionalRequest{JobID: jobID, IsProvisional: true}, 3)
+		}},
+		{"UpdateMaterialPrice", func(repo repositories.BOQRepository) error {
+			return repo.UpdateMaterialPrice(context.Background(), boqID, jobID, "MAT-CEMENT", models.MoneyFromFloat(180), 1)
+		}},
+		{"SetMaterialPriceCurrency", func(repo repositories.BOQRepository) error {
+			return repo.SetMaterialPriceCurrency(context.Background(), boqID, requests.MaterialPriceCurrencyRequest{MaterialID: "MAT-CEMENT", Currency: "USD", FxRate: 35})
+		}},
+		{"SetPreliminariesPercent", func(repo repositories.BOQRepository) error {
+			return repo.SetPreliminariesPercent(context.Background(), boqID, requests.PreliminariesRequest{Percent: &percent})
+		}},
+		{"ApplyGeneralCostPercentage", func(repo repositories.BOQRepository) error {
+			_, err := repo.ApplyGeneralCostPercentage(context.Background(), boqID, percent)
+			return err
+		}},
+		{"ApplyRateRounding", func(repo repositories.BOQRepository) error {
+			_, err := repo.ApplyRateRounding(context.Background(), boqID, 10)
+			return err
+		}},
+		{"CleanOrphanedPriceLogs", func(repo repositories.BOQRepository) error {
+			_, err := repo.CleanOrphanedPriceLogs(context.Background(), boqID)
+			return err
+		}},
+		{"SubmitForApproval", func(repo repositories.BOQRepository) error {
+			return repo.SubmitForApproval(context.Background(), boqID, requests.SubmitBOQApprovalRequest{ApproverIDs: []uuid.UUID{uuid.New()}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+			// Nothing may be written once the status check fails.
+			mock.ExpectBegin()
+			mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+				WithArgs(boqID).
+				WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+			mock.ExpectRollback()
+
+			err = tt.mutate(repo)
+			assert.ErrorIs(t, err, repositories.ErrBOQNotDraft)
+			assert.ErrorContains(t, err, "current status is approved")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBOQRepositoryCompareBOQs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	sourceID := uuid.New()
+	cloneID := uuid.New()
+	wallID := uuid.New()
+	roofID := uuid.New()
+
+	jobColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	for _, boqID := range []uuid.UUID{sourceID, cloneID} {
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+	}
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(sourceID).
+		WillReturnRows(sqlmock.NewRows(jobColumns).
+			AddRow(wallID, "Brick wall", "m2", 4.0, 300.0, nil, 125.0, 0, false).
+			AddRow(roofID, "Roof tiles", "m2", 10.0, 80.0, nil, 20.0, 0, false))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(cloneID).
+		WillReturnRows(sqlmock.NewRows(jobColumns).
+			AddRow(wallID, "Brick wall", "m2", 6.0, 300.0, nil, 125.0, 0, false).
+			AddRow(roofID, "Roof tiles", "m2", 10.0, 80.0, nil, 20.0, 0, false))
+	mock.ExpectCommit()
+
+	diff, err := repo.CompareBOQs(context.Background(), sourceID, cloneID)
+	require.NoError(t, err)
+
+	assert.Empty(t, diff.OnlyInA)
+	assert.Empty(t, diff.OnlyInB)
+	require.Len(t, diff.Changed, 1)
+
+	change := diff.Changed[0]
+	assert.Equal(t, wallID, change.JobID)
+	assert.Equal(t, 4.0, change.OldQuantity)
+	assert.Equal(t, 6.0, change.NewQuantity)
+	assert.Equal(t, models.MoneyFromFloat(300.0), change.OldLaborCost)
+	assert.Equal(t, models.MoneyFromFloat(300.0), change.NewLaborCost)
+	assert.Equal(t, models.MoneyFromFloat(850.0), change.TotalDelta)
+	assert.Equal(t, models.MoneyFromFloat(850.0), diff.TotalDelta)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryCreateBOQForProjectUsesProjectDefault(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	projectID := uuid.New()
+	boqID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM project WHERE project_id = \$1 FOR UPDATE`).
+		WithArgs(projectID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("planning"))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM boq WHERE project_id = \$1\)`).
+		WithArgs(projectID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT default_general_cost FROM project WHERE project_id = \$1`).
+		WithArgs(projectID).
+		WillReturnRows(sqlmock.NewRows([]string{"default_general_cost"}).AddRow(2500.0))
+	mock.ExpectQuery(`INSERT INTO boq \(project_id, status, selling_general_cost\)`).
+		WithArgs(projectID, models.BOQStatusDraft, 2500.0).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "project_id", "status", "selling_general_cost", "version", "created_at", "updated_at"}).
+			AddRow(boqID, projectID, "draft", []byte("2500.00"), 1, now, now))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), boqID, "create", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	boq, err := repo.CreateBOQForProject(context.Background(), projectID, requests.CreateBOQOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.BOQStatusDraft, boq.Status)
+	assert.True(t, boq.SellingGeneralCost.Valid)
+	assert.Equal(t, models.MoneyFromFloat(2500), boq.SellingGeneralCost.Money)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryCreateBOQForProjectRejectsInvalidOptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	negative := -100.0
+
+	_, err = repo.CreateBOQForProject(context.Background(), uuid.New(), requests.CreateBOQOptions{SellingGeneralCost: &negative})
+	var validationErr *requests.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// failingWriter accepts limit bytes and then fails every write.
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return 0, errors.New("client went away")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestBOQRepositoryExportBOQStream(t *testing.T) {
+	boqID := uuid.New()
+	costColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+	costRows := func(n int) *sqlmock.Rows {
+		rows := sqlmock.NewRows(costColumns)
+		for i := 0; i < n; i++ {
+			rows.AddRow(uuid.New(), fmt.Sprintf("Job %03d", i), "m2", 2.0, 10.0, nil, 5.0, 0, false)
+		}
+		return rows
+	}
+
+	t.Run("writes job rows then the totals", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(10.0))
+		mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100.0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectQuery(`FROM boq_job bj`).
+			WithArgs(boqID).
+			WillReturnRows(costRows(250))
+		mock.ExpectCommit()
+
+		var buf bytes.Buffer
+		require.NoError(t, repo.ExportBOQStream(context.Background(), boqID, &buf))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 1+250+3)
+		assert.Equal(t, "Job 249", records[250][0])
+		assert.Equal(t, []string{"Preliminaries", "%", "10", "", "", "750.00"}, records[251])
+		assert.Equal(t, []string{"General Cost", "", "", "", "", "100.00"}, records[252])
+		assert.Equal(t, []string{"Grand Total", "", "", "", "", "8350.00"}, records[253])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("prices an approved BOQ from its snapshot", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		// A query on the live material_price_log does not match the cost
+		// expectation, so an approved export cannot pick up later price edits.
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(nil))
+		mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0.0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM material_price_snapshot`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(`LEFT JOIN \(\s+SELECT .+ FROM material_price_snapshot s`).
+			WithArgs(boqID).
+			WillReturnRows(costRows(1))
+		mock.ExpectCommit()
+
+		var buf bytes.Buffer
+		require.NoError(t, repo.ExportBOQStream(context.Background(), boqID, &buf))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 1+1+3)
+		assert.Equal(t, []string{"Grand Total", "", "", "", "", "30.00"}, records[4])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when the writer fails midway", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		jobRows := costRows(250).RowError(200, errors.New("rows should be closed before this"))
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(nil))
+		mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0.0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectQuery(`FROM boq_job bj`).
+			WithArgs(boqID).
+			WillReturnRows(jobRows).
+			RowsWillBeClosed()
+		mock.ExpectRollback()
+
+		err = repo.ExportBOQStream(context.Background(), boqID, &failingWriter{limit: 1024})
+		assert.ErrorContains(t, err, "client went away")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("writes nothing for a missing BOQ", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		var buf bytes.Buffer
+		err = repo.ExportBOQStream(context.Background(), boqID, &buf)
+		assert.ErrorIs(t, err, repositories.ErrBOQNotFound)
+		assert.Zero(t, buf.Len())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryAddBOQJobsUpsertReportsCostChange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	jobColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+	oldLine := func() *sqlmock.Rows {
+		return sqlmock.NewRows(jobColumns).AddRow(jobID, "Brick wall", "m2", 2.0, 100.0, nil, 50.0, 0, false)
+	}
+	newLine := func() *sqlmock.Rows {
+		return sqlmock.NewRows(jobColumns).AddRow(jobID, "Brick wall", "m2", 4.0, 100.0, nil, 50.0, 0, false)
+	}
+	expectGrandTotal := func(line *sqlmock.Rows) {
+		mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID).WillReturnRows(line)
+		mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0.0))
+		mock.ExpectQuery(`SELECT preliminaries_percent FROM boq`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(10.0))
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectQuery(`FROM job j`).
+		WithArgs(boqID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "unit", "in_boq", "deleted"}).AddRow(jobID, "m2", true, false))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM boq_job`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	// 2 x (100 + 50) plus 10% preliminaries
+	expectGrandTotal(oldLine())
+	mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID, sqlmock.AnyArg()).WillReturnRows(oldLine())
+	mock.ExpectExec(`INSERT INTO boq_job`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO material_price_log`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID, sqlmock.AnyArg()).WillReturnRows(newLine())
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), boqID, "add_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := repo.AddBOQJobs(context.Background(), boqID, []requests.BOQJobRequest{
+		{JobID: jobID, Quantity: 4, LaborCost: 100, Upsert: true},
+	}, 3)
+	require.NoError(t, err)
+	require.Len(t, result.Jobs, 1)
+	assert.Equal(t, models.MoneyFromFloat(330.0), result.StartingTotal)
+	assert.Equal(t, models.MoneyFromFloat(330.0), result.Jobs[0].Contribution)
+	assert.Equal(t, models.MoneyFromFloat(660.0), result.Jobs[0].RunningTotal)
+	assert.Equal(t, models.MoneyFromFloat(660.0), result.FinalTotal)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryAddBOQJobsRejectsDeletedJobWithoutUpsert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectQuery(`bj.deleted_at IS NULL\s+\) as in_boq`).
+		WithArgs(boqID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "unit", "in_boq", "deleted"}).AddRow(jobID, "m2", false, true))
+	mock.ExpectRollback()
+
+	_, err = repo.AddBOQJobs(context.Background(), boqID, []requests.BOQJobRequest{{JobID: jobID, Quantity: 1, LaborCost: 100}}, 3)
+	assert.ErrorIs(t, err, repositories.ErrJobAlreadyInBOQ)
+	assert.ErrorContains(t, err, "restore it instead")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryAddBOQJobsDuplicateJobIsInvalidInput(t *testing.T) {
+	repo := postgres.NewBOQRepository(sqlx.NewDb(nil, "sqlmock"))
+	jobID := uuid.New()
+
+	_, err := repo.AddBOQJobs(context.Background(), uuid.New(), []requests.BOQJobRequest{
+		{JobID: jobID, Quantity: 1, LaborCost: 100},
+		{JobID: jobID, Quantity: 2, LaborCost: 100},
+	}, 3)
+	assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+}
+
+func TestBOQRepositoryListBOQsScansGeneralCostAsMoney(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	pricedID := uuid.New()
+	unpricedID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	// Postgres returns numeric columns as text; a legacy BOQ has no general
+	// cost at all.
+	mock.ExpectQuery(`FROM boq b`).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "project_id", "status", "selling_general_cost", "version", "created_at", "updated_at", "job_count"}).
+			AddRow(pricedID, uuid.New(), "draft", []byte("1234.56"), 1, now, now, 3).
+			AddRow(unpricedID, uuid.New(), "draft", nil, 1, now, now, 0))
+	mock.ExpectCommit()
+
+	boqs, err := repo.ListBOQs(context.Background(), requests.ListBOQRequest{})
+	require.NoError(t, err)
+	require.Len(t, boqs, 2)
+	assert.Equal(t, models.MoneyFromFloat(1234.56), boqs[0].SellingGeneralCost)
+	assert.Zero(t, boqs[1].SellingGeneralCost)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetBOQCashFlowCurve(t *testing.T) {
+	boqID := uuid.New()
+	scheduledJobID := uuid.New()
+	unscheduledJobID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A 6000.00 job runs for the first 60 days, 100.00 a day over 31 days of
+	// January and 29 of February. A 600.00 job has no schedule.
+	tests := []struct {
+		name     string
+		mode     models.CashFlowUnscheduledMode
+		january  models.Money
+		february models.Money
+	}{
+		{"spread unscheduled jobs over the scheduled span", models.CashFlowUnscheduledSpread, models.MoneyFromFloat(3100 + 310), models.MoneyFromFloat(2900 + 290)},
+		{"lump unscheduled jobs on the first day", models.CashFlowUnscheduledLump, models.MoneyFromFloat(3100 + 600), models.MoneyFromFloat(2900)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+				WithArgs(boqID).
+				WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+			mock.ExpectQuery(`FROM boq_job bj`).
+				WithArgs(boqID).
+				WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+					AddRow(scheduledJobID, "Structure", "lot", 1.0, 6000.0, nil, 0.0, 0, false).
+					AddRow(unscheduledJobID, "Signage", "lot", 1.0, 600.0, nil, 0.0, 0, false))
+			mock.ExpectQuery(`SELECT job_id, start_offset_days, duration_days`).
+				WithArgs(boqID).
+				WillReturnRows(sqlmock.NewRows([]string{"job_id", "start_offset_days", "duration_days"}).
+					AddRow(scheduledJobID, 0, 60).
+					AddRow(unscheduledJobID, nil, nil))
+			mock.ExpectCommit()
+
+			curve, err := repo.GetBOQCashFlowCurve(context.Background(), boqID, requests.CashFlowCurveRequest{
+				StartDate:       start,
+				UnscheduledMode: tt.mode,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, models.MoneyFromFloat(6600.0), curve.TotalCost)
+			assert.Equal(t, 1, curve.UnscheduledJobs)
+			require.Len(t, curve.Points, 2)
+			assert.Equal(t, "2024-01", curve.Points[0].Month)
+			assert.Equal(t, tt.january, curve.Points[0].Spend)
+			assert.Equal(t, "2024-02", curve.Points[1].Month)
+			assert.Equal(t, tt.february, curve.Points[1].Spend)
+			assert.Equal(t, models.MoneyFromFloat(6600.0), curve.Points[1].CumulativeSpend)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("rejects an unknown unscheduled mode", func(t *testing.T) {
+		repo := postgres.NewBOQRepository(sqlx.NewDb(nil, "sqlmock"))
+
+		_, err := repo.GetBOQCashFlowCurve(context.Background(), boqID, requests.CashFlowCurveRequest{
+			StartDate:       start,
+			UnscheduledMode: "weekly",
+		})
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+	})
+}
+
+func TestBOQRepositoryGetPreferredSupplierShare(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	preferredID := uuid.New()
+	otherID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+	// Spend is priced at the estimated price, like the job costs and totals.
+	mock.ExpectQuery(`SUM\(COALESCE\(mpl.estimated_price, 0\) \* COALESCE\(mpl.fx_rate, 1\) \* COALESCE\(mpl.quantity, 0\) \* COALESCE\(bj.quantity, 0\)\)`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"supplier_id", "supplier_name", "is_preferred", "material_cost"}).
+			AddRow(preferredID, "Siam Cement", true, 6000.0).
+			AddRow(otherID, "Local Hardware", false, 3000.0).
+			AddRow(nil, "", false, 1000.0))
+	mock.ExpectCommit()
+
+	share, err := repo.GetPreferredSupplierShare(context.Background(), boqID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MoneyFromFloat(10000.0), share.TotalMaterialCost)
+	assert.Equal(t, models.MoneyFromFloat(6000.0), share.PreferredCost)
+	assert.Equal(t, models.MoneyFromFloat(3000.0), share.NonPreferredCost)
+	assert.Equal(t, models.MoneyFromFloat(1000.0), share.UnassignedCost)
+	assert.InDelta(t, 0.6, share.PreferredShare, 1e-9)
+	require.Len(t, share.NonPreferredSpend, 1)
+	assert.Equal(t, otherID, share.NonPreferredSpend[0].SupplierID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryValidateRequiredJobs(t *testing.T) {
+	boqID := uuid.New()
+	footingJobID := uuid.New()
+
+	t.Run("lists checklist jobs the BOQ is missing", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT p.project_type\s+FROM boq b`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"project_type"}).AddRow("house"))
+		// Soft-deleted jobs do not satisfy the checklist.
+		mock.ExpectQuery(`FROM project_type_required_job ptr.+bj.deleted_at IS NULL`).
+			WithArgs("house", boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit"}).AddRow(footingJobID, "Footing", "m3"))
+		mock.ExpectCommit()
+
+		check, err := repo.ValidateRequiredJobs(context.Background(), boqID)
+		require.NoError(t, err)
+		assert.Equal(t, "house", check.ProjectType)
+		assert.False(t, check.Complete)
+		require.Len(t, check.MissingJobs, 1)
+		assert.Equal(t, footingJobID, check.MissingJobs[0].JobID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("passes projects without a type", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT p.project_type\s+FROM boq b`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"project_type"}).AddRow(nil))
+		mock.ExpectCommit()
+
+		check, err := repo.ValidateRequiredJobs(context.Background(), boqID)
+		require.NoError(t, err)
+		assert.True(t, check.Complete)
+		assert.Empty(t, check.MissingJobs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryGetJobProfitability(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	pricedJobID := uuid.New()
+	unpricedJobID := uuid.New()
+
+	costColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+	// 2 x (100 labor + 50 material) costs 300.00 and sells at 2 x 200.00.
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows(costColumns).
+			AddRow(pricedJobID, "Brick wall", "m2", 2.0, 100.0, 200.0, 50.0, 0, false).
+			AddRow(unpricedJobID, "Cleanup", "lot", 1.0, 100.0, nil, 0.0, 0, false))
+	mock.ExpectCommit()
+
+	result, err := repo.GetJobProfitability(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, result.Jobs, 2)
+
+	priced := result.Jobs[0]
+	assert.True(t, priced.Priced)
+	assert.Equal(t, models.MoneyFromFloat(300.0), priced.Cost)
+	assert.Equal(t, models.MoneyFromFloat(400.0), priced.SellingPrice)
+	assert.Equal(t, models.MoneyFromFloat(100.0), priced.MarginAmount)
+	assert.InDelta(t, 25.0, priced.MarginPercent, 1e-9)
+	assert.InDelta(t, 100.0/3, priced.MarkupPercent, 1e-9)
+
+	// A line without a selling price earns nothing against its cost.
+	unpriced := result.Jobs[1]
+	assert.False(t, unpriced.Priced)
+	assert.Equal(t, models.MoneyFromFloat(-100.0), unpriced.MarginAmount)
+	assert.Zero(t, unpriced.MarginPercent)
+
+	assert.Equal(t, models.MoneyFromFloat(400.0), result.TotalCost)
+	assert.Equal(t, models.MoneyFromFloat(400.0), result.TotalSelling)
+	assert.Zero(t, result.MarginAmount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryRecalculateProjectBOQTotals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	projectID := uuid.New()
+	steadyID := uuid.New()
+	driftedID := uuid.New()
+	newID := uuid.New()
+
+	// Each draft BOQ is one line of labor with 10% preliminaries and no
+	// general cost.
+	expectGrandTotal := func(boqID uuid.UUID, labor, total float64) {
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectQuery(`FROM boq_job bj`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+				AddRow(uuid.New(), "Structure", "lot", 1.0, labor, nil, 0.0, 0, false))
+		mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(0.0))
+		mock.ExpectQuery(`SELECT preliminaries_percent FROM boq`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(10.0))
+		mock.ExpectExec(`UPDATE boq SET total_cost = \$1 WHERE boq_id = \$2`).
+			WithArgs(models.MoneyFromFloat(total), boqID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT boq_id, COALESCE\(total_cost, 0\) as previous_total FROM boq WHERE project_id = \$1 ORDER BY boq_id FOR UPDATE`).
+		WithArgs(projectID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "previous_total"}).
+			AddRow(steadyID, 1000.0).
+			AddRow(driftedID, 1000.0).
+			AddRow(newID, 0.0))
+	expectGrandTotal(steadyID, 914, 1005.4)
+	expectGrandTotal(driftedID, 1000, 1100)
+	expectGrandTotal(newID, 500, 550)
+	mock.ExpectCommit()
+
+	result, err := repo.RecalculateProjectBOQTotals(context.Background(), projectID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Updated)
+
+	// A 0.5% drift is not reported; 10% and a first total are.
+	require.Len(t, result.Changed, 2)
+	assert.Equal(t, driftedID, result.Changed[0].BOQID)
+	assert.Equal(t, models.MoneyFromFloat(100), result.Changed[0].Difference)
+	assert.Equal(t, newID, result.Changed[1].BOQID)
+	assert.Equal(t, models.MoneyFromFloat(550), result.Changed[1].Difference)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryRecalculateBOQTotalMatchesSummary(t *testing.T) {
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+	// An approved BOQ with 1700.00 of direct works priced from its snapshot,
+	// 10% preliminaries and a 300.00 selling general cost. The itemised
+	// 1000.00 estimate is not charged.
+	costRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(jobID, "Brick wall", "m2", 4.0, 300.0, nil, 125.0, 0, false)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT p.name, p.address, b.preliminaries_percent, b.selling_general_cost, b.status`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "address", "preliminaries_percent", "selling_general_cost", "status"}).
+			AddRow("Riverside House", nil, 10.0, 300.0, "approved"))
+	mock.ExpectQuery(`JOIN general_cost gc`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "type_name", "estimated_cost"}).
+			AddRow(boqID, "Site office", 1000.0))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM material_price_snapshot`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`LEFT JOIN \(\s+SELECT .+ FROM material_price_snapshot s`).
+		WithArgs(boqID).
+		WillReturnRows(costRows())
+	mock.ExpectQuery(`FROM \(\s+SELECT .+ FROM material_price_snapshot s`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "material_name", "quantity", "unit", "estimated_price", "total"}))
+	mock.ExpectCommit()
+
+	summary, err := repo.GetBOQSummary(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Equal(t, models.MoneyFromFloat(2170), summary.SummaryMetrics.GrandTotal)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT boq_id, COALESCE\(total_cost, 0\) as previous_total FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "previous_total"}).AddRow(boqID, 0.0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM material_price_snapshot`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`LEFT JOIN \(\s+SELECT .+ FROM material_price_snapshot s`).
+		WithArgs(boqID).
+		WillReturnRows(costRows())
+	mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(300.0))
+	mock.ExpectQuery(`SELECT preliminaries_percent FROM boq`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(10.0))
+	mock.ExpectExec(`UPDATE boq SET total_cost = \$1 WHERE boq_id = \$2`).
+		WithArgs(models.MoneyFromFloat(2170), boqID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	total, err := repo.RecalculateBOQTotal(context.Background(), boqID)
+	require.NoError(t, err)
+	assert.Equal(t, summary.SummaryMetrics.GrandTotal, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetHighLaborShareBOQs(t *testing.T) {
+	t.Run("filters on labor over direct cost", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+		boqID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		// BOQs with no direct cost are skipped rather than divided by zero.
+		mock.ExpectQuery(`WHERE bt.labor_cost \+ bt.material_cost > 0\s+AND bt.labor_cost / \(bt.labor_cost \+ bt.material_cost\) > \$1\s+ORDER BY labor_share DESC`).
+			WithArgs(0.6).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "project_id", "project_name", "status", "labor_cost", "material_cost", "labor_share"}).
+				AddRow(boqID, uuid.New(), "Riverside House", "draft", 7000.0, 3000.0, 0.7))
+		mock.ExpectCommit()
+
+		boqs, err := repo.GetHighLaborShareBOQs(context.Background(), 0.6)
+		require.NoError(t, err)
+		require.Len(t, boqs, 1)
+		assert.Equal(t, boqID, boqs[0].BOQID)
+		assert.Equal(t, 0.7, boqs[0].LaborShare)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects a threshold outside 0 to 1", func(t *testing.T) {
+		repo := postgres.NewBOQRepository(sqlx.NewDb(nil, "sqlmock"))
+
+		_, err := repo.GetHighLaborShareBOQs(context.Background(), 60)
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+	})
+}
+
+func TestBOQRepositoryNormalizeUnits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	jobID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT 'job' as source`).
+		WillReturnRows(sqlmock.NewRows([]string{"source", "id", "name", "unit"}).
+			AddRow("job", jobID.String(), "Brick wall", "ตร.ม.").
+			AddRow("job", uuid.New().String(), "Footing", "m3").
+			AddRow("material", "MAT-CEMENT", "Cement", " Bags ").
+			AddRow("material", "MAT-SAND", "Sand", "truckload"))
+	// Canonical units are left alone; only mappable ones are rewritten.
+	mock.ExpectExec(`UPDATE job SET unit = \$1 WHERE job_id = \$2::uuid`).
+		WithArgs("m2", jobID.String()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE material SET unit = \$1 WHERE material_id = \$2`).
+		WithArgs("bag", "MAT-CEMENT").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := repo.NormalizeUnits(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.JobsUpdated)
+	assert.Equal(t, 1, result.MaterialsUpdated)
+	require.Len(t, result.Unmapped, 1)
+	assert.Equal(t, "MAT-SAND", result.Unmapped[0].ID)
+	assert.Empty(t, result.Unmapped[0].CanonicalUnit)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetFXSensitivity(t *testing.T) {
+	boqID := uuid.New()
+	jobID := uuid.New()
+
+	t.Run("reprices foreign materials at the new rate", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status", "currency"}).AddRow(boqID, "draft", "THB"))
+		// 1000.00 labor plus 100 USD at 35 and 500 THB of materials.
+		mock.ExpectQuery(`FROM boq_job bj`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+				AddRow(jobID, "Steel frame", "lot", 1.0, 1000.0, nil, 4000.0, 0, false))
+		mock.ExpectQuery(`GROUP BY mpl.currency, mpl.fx_rate`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"currency", "fx_rate", "foreign_cost"}).
+				AddRow("THB", 1.0, 500.0).
+				AddRow("USD", 35.0, 100.0))
+		mock.ExpectCommit()
+
+		// A rate given for the BOQ's own currency is ignored.
+		result, err := repo.GetFXSensitivity(context.Background(), boqID, map[string]float64{"usd": 36, "THB": 2})
+		require.NoError(t, err)
+		assert.Equal(t, models.MoneyFromFloat(5000.0), result.CurrentTotal)
+		assert.Equal(t, models.MoneyFromFloat(100.0), result.Delta)
+		assert.Equal(t, models.MoneyFromFloat(5100.0), result.ProjectedTotal)
+		require.Len(t, result.Currencies, 2)
+		assert.Equal(t, "THB", result.Currencies[0].Currency)
+		assert.Zero(t, result.Currencies[0].Delta)
+		assert.Equal(t, "USD", result.Currencies[1].Currency)
+		assert.Equal(t, models.MoneyFromFloat(3500.0), result.Currencies[1].CurrentCost)
+		assert.Equal(t, models.MoneyFromFloat(3600.0), result.Currencies[1].ProjectedCost)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects a non-positive rate", func(t *testing.T) {
+		repo := postgres.NewBOQRepository(sqlx.NewDb(nil, "sqlmock"))
+
+		_, err := repo.GetFXSensitivity(context.Background(), boqID, map[string]float64{"USD": 0})
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+	})
+}
+
+func TestBOQRepositoryGetInvoiceSchedule(t *testing.T) {
+	boqID := uuid.New()
+	jobID := uuid.New()
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// 10000.00 of direct works plus 10% preliminaries and 1000.00 of general
+	// costs is a 12000.00 grand total.
+	expectGrandTotal := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "approved"))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM material_price_snapshot`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`FROM boq_job bj`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+				AddRow(jobID, "Structure", "lot", 1.0, 10000.0, nil, 0.0, 0, false))
+		mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1000.0))
+		mock.ExpectQuery(`SELECT preliminaries_percent FROM boq`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(10.0))
+	}
+	milestoneColumns := []string{"milestone_id", "boq_id", "name", "percentage", "fixed_amount", "trigger_date"}
+
+	t.Run("prices percentage and fixed milestones", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		expectGrandTotal(mock)
+		mock.ExpectQuery(`FROM boq_milestone`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows(milestoneColumns).
+				AddRow(uuid.New(), boqID, "Deposit", 30.0, nil, day).
+				AddRow(uuid.New(), boqID, "Roof complete", nil, 2400.0, day.AddDate(0, 2, 0)).
+				AddRow(uuid.New(), boqID, "Handover", 50.0, nil, day.AddDate(0, 4, 0)))
+		mock.ExpectCommit()
+
+		schedule, err := repo.GetInvoiceSchedule(context.Background(), boqID)
+		require.NoError(t, err)
+		assert.Equal(t, models.MoneyFromFloat(12000.0), schedule.GrandTotal)
+		require.Len(t, schedule.Milestones, 3)
+		assert.Equal(t, models.MoneyFromFloat(3600.0), schedule.Milestones[0].AmountDue)
+		assert.Equal(t, models.MoneyFromFloat(2400.0), schedule.Milestones[1].AmountDue)
+		assert.Equal(t, models.MoneyFromFloat(6000.0), schedule.Milestones[2].AmountDue)
+		assert.Equal(t, models.MoneyFromFloat(12000.0), schedule.Milestones[2].CumulativeDue)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects milestones that do not cover the grand total", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		expectGrandTotal(mock)
+		mock.ExpectQuery(`FROM boq_milestone`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows(milestoneColumns).
+				AddRow(uuid.New(), boqID, "Deposit", 30.0, nil, day).
+				AddRow(uuid.New(), boqID, "Handover", 60.0, nil, day.AddDate(0, 4, 0)))
+		mock.ExpectRollback()
+
+		_, err = repo.GetInvoiceSchedule(context.Background(), boqID)
+		assert.ErrorIs(t, err, repositories.ErrMilestonesUnbalanced)
+		assert.ErrorContains(t, err, "milestones total 10800.00, BOQ grand total 12000.00")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryCleanOrphanedPriceLogs(t *testing.T) {
+	boqID := uuid.New()
+	jobID := uuid.New()
+	mplID := uuid.New()
+	orphanColumns := []string{"mpl_id", "job_id", "job_name", "material_id", "material_name", "quantity", "estimated_price"}
+
+	t.Run("lists logs whose material left the job template", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+		mock.ExpectQuery(`FROM material_price_log mpl .+ AND \( NOT EXISTS \( SELECT 1 FROM job_material jm WHERE jm.job_id = mpl.job_id AND jm.material_id = mpl.material_id \) OR NOT EXISTS \( SELECT 1 FROM boq_job bj WHERE bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id \) \)`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows(orphanColumns).
+				AddRow(mplID, jobID, "Wall", "M001", "Brick", 120.0, 3.5))
+		mock.ExpectCommit()
+
+		orphans, err := repo.GetOrphanedPriceLogs(context.Background(), boqID)
+		require.NoError(t, err)
+		require.Len(t, orphans, 1)
+		assert.Equal(t, mplID, orphans[0].MplID)
+		assert.Equal(t, "M001", orphans[0].MaterialID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("deletes only the listed rows", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		// The postgres driver name makes Rebind emit $n placeholders.
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "postgres"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectQuery(`FROM material_price_log mpl`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows(orphanColumns).
+				AddRow(mplID, jobID, "Wall", "M001", "Brick", 120.0, 3.5))
+		mock.ExpectExec(`DELETE FROM material_price_log WHERE boq_id = \$1 AND mpl_id IN \(\$2\)`).
+			WithArgs(boqID, mplID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), boqID, "clean_price_logs", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+			WithArgs(boqID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		removed, err := repo.CleanOrphanedPriceLogs(context.Background(), boqID)
+		require.NoError(t, err)
+		require.Len(t, removed, 1)
+		assert.Equal(t, mplID, removed[0].MplID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("does not touch the BOQ when nothing is orphaned", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+		mock.ExpectQuery(`FROM material_price_log mpl`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows(orphanColumns))
+		mock.ExpectCommit()
+
+		removed, err := repo.CleanOrphanedPriceLogs(context.Background(), boqID)
+		require.NoError(t, err)
+		assert.Empty(t, removed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryGetEstimateVsActual(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+	boqID := uuid.New()
+	wallID := uuid.New()
+	roofID := uuid.New()
+	extraID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "approved"))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(wallID, "Wall", "lot", 1.0, 1000.0, nil, 0.0, 0, false).
+			AddRow(roofID, "Roof", "lot", 1.0, 2000.0, nil, 0.0, 0, false))
+	mock.ExpectQuery(`FROM material_price_log mpl .+ GROUP BY m.material_id, m.name`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"material_id", "name", "amount"}).
+			AddRow("M001", "Brick", 500.0))
+	mock.ExpectCommit()
+
+	result, err := repo.GetEstimateVsActual(context.Background(), boqID, requests.EstimateVsActualRequest{
+		Jobs:      map[uuid.UUID]float64{wallID: 1100, extraID: 500},
+		Materials: map[string]float64{"M001": 400},
+	})
+	require.NoError(t, err)
+
+	// Only the wall is in both the estimate and the actuals, so it alone
+	// counts toward the totals; the roof and the extra job are unmatched.
+	jobs := result.Jobs
+	require.NotNil(t, jobs)
+	require.Len(t, jobs.Lines, 3)
+	assert.Equal(t, roofID.String(), jobs.Lines[0].ID)
+	assert.Equal(t, extraID.String(), jobs.Lines[1].ID)
+	assert.Equal(t, wallID.String(), jobs.Lines[2].ID)
+	assert.Equal(t, 2, jobs.UnmatchedLines)
+	assert.Equal(t, models.MoneyFromFloat(1000.0), jobs.EstimatedTotal)
+	assert.Equal(t, models.MoneyFromFloat(1100.0), jobs.ActualTotal)
+	assert.InDelta(t, 10.0, jobs.VariancePercent, 0.001)
+	assert.InDelta(t, 90.0, jobs.AccuracyPercent, 0.001)
+
+	materials := result.Materials
+	require.NotNil(t, materials)
+	require.Len(t, materials.Lines, 1)
+	assert.Equal(t, models.MoneyFromFloat(-100.0), materials.Variance)
+	assert.InDelta(t, 80.0, materials.AccuracyPercent, 0.001)
+	assert.Zero(t, materials.UnmatchedLines)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetDirectCostFloor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+	boqID := uuid.New()
+	wallID := uuid.New()
+	roofID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(wallID, "Wall", "m2", 10.0, 50.0, 120.0, 30.0, 0, false).
+			AddRow(roofID, "Roof", "m2", 0.0, 100.0, nil, 0.0, 2, false))
+	mock.ExpectCommit()
+
+	floor, err := repo.GetDirectCostFloor(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, floor.Jobs, 2)
+
+	// The selling price plays no part: the floor is labor plus materials.
+	wall := floor.Jobs[0]
+	assert.Equal(t, models.MoneyFromFloat(500.0), wall.LaborCost)
+	assert.Equal(t, models.MoneyFromFloat(300.0), wall.MaterialCost)
+	assert.Equal(t, models.MoneyFromFloat(800.0), wall.FloorPrice)
+	assert.Equal(t, models.MoneyFromFloat(80.0), wall.FloorUnitRate)
+	assert.False(t, wall.Uncertain)
+
+	roof := floor.Jobs[1]
+	assert.Zero(t, roof.FloorUnitRate)
+	assert.True(t, roof.Uncertain)
+
+	assert.Equal(t, models.MoneyFromFloat(800.0), floor.FloorPrice)
+	assert.True(t, floor.Uncertain)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryPreviewRateRounding(t *testing.T) {
+	boqID := uuid.New()
+
+	t.Run("rounds selling prices and unit costs to the increment", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+		// The wall has a selling price; the roof falls back to its direct
+		// cost per unit, 47.00 labor plus 11.20 of materials.
+		mock.ExpectQuery(`FROM boq_job bj`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+				AddRow(uuid.New(), "Wall", "m2", 10.0, 50.0, 123.4, 30.0, 0, false).
+				AddRow(uuid.New(), "Roof", "m2", 2.0, 47.0, nil, 11.2, 0, false))
+		mock.ExpectCommit()
+
+		preview, err := repo.PreviewRateRounding(context.Background(), boqID, 5)
+		require.NoError(t, err)
+		require.Len(t, preview.Jobs, 2)
+
+		assert.Equal(t, models.MoneyFromFloat(125.0), preview.Jobs[0].RoundedRate)
+		assert.Equal(t, models.MoneyFromFloat(16.0), preview.Jobs[0].Delta)
+		assert.Equal(t, models.MoneyFromFloat(58.2), preview.Jobs[1].UnitRate)
+		assert.Equal(t, models.MoneyFromFloat(60.0), preview.Jobs[1].RoundedRate)
+		assert.Equal(t, models.MoneyFromFloat(3.6), preview.Jobs[1].Delta)
+
+		assert.Equal(t, models.MoneyFromFloat(1350.4), preview.GrandTotal)
+		assert.Equal(t, models.MoneyFromFloat(1370.0), preview.RoundedGrandTotal)
+		assert.Equal(t, models.MoneyFromFloat(19.6), preview.Delta)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects a non-positive increment", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+		mock.ExpectRollback()
+
+		_, err = repo.PreviewRateRounding(context.Background(), boqID, 0)
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryGetLinesExceedingMarkupCap(t *testing.T) {
+	boqID := uuid.New()
+
+	t.Run("returns priced lines above the cap", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		wallID := uuid.New()
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+		// Markups over direct cost: wall 25%, roof 10%. The unpriced floor has
+		// no markup to cap.
+		mock.ExpectQuery(`FROM boq_job bj`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+				AddRow(wallID, "Wall", "m2", 10.0, 50.0, 100.0, 30.0, 0, false).
+				AddRow(uuid.New(), "Roof", "lot", 1.0, 100.0, 110.0, 0.0, 0, false).
+				AddRow(uuid.New(), "Floor", "m2", 5.0, 20.0, nil, 0.0, 0, false))
+		mock.ExpectCommit()
+
+		lines, err := repo.GetLinesExceedingMarkupCap(context.Background(), boqID, 20)
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		assert.Equal(t, wallID, lines[0].JobID)
+		assert.InDelta(t, 25.0, lines[0].MarkupPercent, 0.001)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects a negative cap", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+		mock.ExpectRollback()
+
+		_, err = repo.GetLinesExceedingMarkupCap(context.Background(), boqID, -1)
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryGetSupplierMaterialRollup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+	boqID := uuid.New()
+	supplierID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+	mock.ExpectQuery(`FROM material_price_log mpl JOIN boq_job bj ON .+ AND bj.deleted_at IS NULL .+ GROUP BY s.supplier_id, s.name, m.material_id, m.name, m.unit ORDER BY s.supplier_id IS NULL`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"supplier_id", "supplier_name", "material_id", "material_name", "unit", "quantity", "cost"}).
+			AddRow(supplierID, "Siam Bricks", "M001", "Brick", "pcs", 1000.0, 3500.0).
+			AddRow(supplierID, "Siam Bricks", "M002", "Mortar", "bag", 20.0, 2400.0).
+			AddRow(nil, "", "M003", "Rebar", "kg", 0.0, 0.0))
+	mock.ExpectCommit()
+
+	rollup, err := repo.GetSupplierMaterialRollup(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, rollup, 2)
+
+	assert.Equal(t, uuid.NullUUID{UUID: supplierID, Valid: true}, rollup[0].SupplierID)
+	require.Len(t, rollup[0].Materials, 2)
+	assert.Equal(t, models.MoneyFromFloat(3.5), rollup[0].Materials[0].UnitPrice)
+	assert.Equal(t, models.MoneyFromFloat(120.0), rollup[0].Materials[1].UnitPrice)
+	assert.Equal(t, models.MoneyFromFloat(5900.0), rollup[0].TotalCost)
+
+	// Materials with no supplier selected form their own group.
+	assert.False(t, rollup[1].SupplierID.Valid)
+	require.Len(t, rollup[1].Materials, 1)
+	assert.Zero(t, rollup[1].Materials[0].UnitPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetCostCompositionTrend(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("buckets the latest approval snapshots", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT DISTINCT ON \(s.boq_id\) s.\* FROM boq_cost_snapshot s .+ date_trunc\('quarter', approved_at\) as bucket_start, .+ WHERE approved_at >= \$1 AND approved_at < \$2 AND total_cost > 0`).
+			WithArgs(from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"bucket_start", "boq_count", "labor_percent", "material_percent", "overhead_percent", "preliminaries_percent"}).
+				AddRow(from, 2, 36.0, 45.0, 10.0, 9.0))
+		mock.ExpectCommit()
+
+		trend, err := repo.GetCostCompositionTrend(context.Background(), from, to, "quarter")
+		require.NoError(t, err)
+		require.Len(t, trend, 1)
+		assert.Equal(t, 2, trend[0].BOQCount)
+		// total_cost is the grand total, so the four shares add up to it.
+		point := trend[0]
+		assert.InDelta(t, 100.0, point.LaborPercent+point.MaterialPercent+point.OverheadPercent+point.PreliminariesPercent, 0.001)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects unknown buckets and empty ranges", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		_, err = repo.GetCostCompositionTrend(context.Background(), from, to, "day")
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+
+		_, err = repo.GetCostCompositionTrend(context.Background(), to, from, "month")
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryGetProvisionalShare(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+	boqID := uuid.New()
+	wallID := uuid.New()
+	landscapingID := uuid.New()
+
+	costRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(landscapingID, "Landscaping", "lump", 1.0, 200.0, nil, 0.0, 0, true).
+			AddRow(wallID, "Wall", "m2", 10.0, 50.0, nil, 30.0, 0, false)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(costRows())
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(costRows())
+	mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0.0))
+	mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(nil))
+	mock.ExpectCommit()
+
+	share, err := repo.GetProvisionalShare(context.Background(), boqID)
+	require.NoError(t, err)
+	require.Len(t, share.Items, 1)
+	assert.Equal(t, landscapingID, share.Items[0].JobID)
+	assert.Equal(t, models.MoneyFromFloat(200.0), share.ProvisionalTotal)
+	assert.Equal(t, models.MoneyFromFloat(1000.0), share.GrandTotal)
+	assert.InDelta(t, 20.0, share.SharePercent, 0.001)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryCloneBOQScaled(t *testing.T) {
+	t.Run("scales job quantities into the target project's new draft", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		sourceBOQID := uuid.New()
+		targetProjectID := uuid.New()
+		targetBOQID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT selling_general_cost, currency FROM boq WHERE boq_id = \$1`).
+			WithArgs(sourceBOQID).
+			WillReturnRows(sqlmock.NewRows([]string{"selling_general_cost", "currency"}).AddRow(1500.0, "THB"))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM project WHERE project_id = \$1\)`).
+			WithArgs(targetProjectID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(`SELECT b.boq_id, b.status`).
+			WithArgs(targetProjectID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT status FROM project WHERE project_id = \$1 FOR UPDATE`).
+			WithArgs(targetProjectID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("planning"))
+		mock.ExpectQuery(`INSERT INTO boq \(project_id, status, selling_general_cost, currency\)`).
+			WithArgs(targetProjectID, sqlmock.AnyArg(), "THB").
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id"}).AddRow(targetBOQID))
+		mock.ExpectExec(`INSERT INTO boq_job .+ SELECT \$1, job_id, quantity \* \$3`).
+			WithArgs(targetBOQID, sourceBOQID, 1.5).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`INSERT INTO material_price_log`).
+			WithArgs(targetBOQID, sourceBOQID, true).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), targetBOQID, "clone", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		boqID, err := repo.CloneBOQScaled(context.Background(), sourceBOQID, targetProjectID, 1.5, true)
+		require.NoError(t, err)
+		assert.Equal(t, targetBOQID, boqID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects a non-positive factor", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		_, err = repo.CloneBOQScaled(context.Background(), uuid.New(), uuid.New(), 0, false)
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryAddBOQJobsReportsRunningTotals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	wallID := uuid.New()
+	roofID := uuid.New()
+	existingID := uuid.New()
+
+	jobColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+	existing := func(rows *sqlmock.Rows) *sqlmock.Rows {
+		return rows.AddRow(existingID, "Foundation", "m3", 1.0, 400.0, nil, 0.0, 0, false)
+	}
+	expectGrandTotal := func(lines *sqlmock.Rows) {
+		mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID).WillReturnRows(lines)
+		mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100.0))
+		mock.ExpectQuery(`SELECT preliminaries_percent FROM boq`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(nil))
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectQuery(`FROM job j`).
+		WithArgs(boqID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "unit", "in_boq", "deleted"}).
+			AddRow(wallID, "m2", false, false).
+			AddRow(roofID, "m2", false, false))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM boq_job`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	// 400 of existing work plus 100 of general costs
+	expectGrandTotal(existing(sqlmock.NewRows(jobColumns)))
+	mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows(jobColumns))
+	mock.ExpectExec(`INSERT INTO boq_job`).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO material_price_log`).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(jobColumns).
+			AddRow(roofID, "Roof", "m2", 5.0, 20.0, nil, 40.0, 0, false).
+			AddRow(wallID, "Wall", "m2", 10.0, 50.0, nil, 30.0, 0, false))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), boqID, "add_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := repo.AddBOQJobs(context.Background(), boqID, []requests.BOQJobRequest{
+		{JobID: wallID, Quantity: 10, LaborCost: 50},
+		{JobID: roofID, Quantity: 5, LaborCost: 20},
+	}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.MoneyFromFloat(500.0), result.StartingTotal)
+
+	// Jobs are reported in request order, each with the total after it.
+	require.Len(t, result.Jobs, 2)
+	assert.Equal(t, wallID, result.Jobs[0].JobID)
+	assert.Equal(t, models.MoneyFromFloat(800.0), result.Jobs[0].Contribution)
+	assert.Equal(t, models.MoneyFromFloat(1300.0), result.Jobs[0].RunningTotal)
+	assert.Equal(t, roofID, result.Jobs[1].JobID)
+	assert.Equal(t, models.MoneyFromFloat(300.0), result.Jobs[1].Contribution)
+	assert.Equal(t, models.MoneyFromFloat(1600.0), result.Jobs[1].RunningTotal)
+	assert.Equal(t, models.MoneyFromFloat(1600.0), result.FinalTotal)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryAddBOQJobsRunningTotalsMatchGrandTotal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	jobIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+
+	jobColumns := []string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}
+	known := sqlmock.NewRows([]string{"job_id", "unit", "in_boq", "deleted"})
+	added := sqlmock.NewRows(jobColumns)
+	reqs := make([]requests.BOQJobRequest, len(jobIDs))
+	for i, id := range jobIDs {
+		known.AddRow(id, "m2", false, false)
+		added.AddRow(id, "Job", "m2", 1.0, 100.05, nil, 0.0, 0, false)
+		reqs[i] = requests.BOQJobRequest{JobID: id, Quantity: 1, LaborCost: 100.05}
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectQuery(`FROM job j`).
+		WithArgs(boqID, sqlmock.AnyArg()).
+		WillReturnRows(known)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM boq_job`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID).WillReturnRows(sqlmock.NewRows(jobColumns))
+	mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0.0))
+	mock.ExpectQuery(`SELECT preliminaries_percent FROM boq`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(10.0))
+	mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows(jobColumns))
+	mock.ExpectExec(`INSERT INTO boq_job`).WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(`INSERT INTO material_price_log`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FROM boq_job bj`).WithArgs(boqID, sqlmock.AnyArg()).WillReturnRows(added)
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WithArgs(sqlmock.AnyArg(), boqID, "add_job", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := repo.AddBOQJobs(context.Background(), boqID, reqs, 1)
+	require.NoError(t, err)
+
+	// 10% of 100.05 rounds to 10.01, but 10% of 400.20 is 40.02, so adding
+	// rounded per-job shares would end 0.02 above the grand total.
+	require.Len(t, result.Jobs, 4)
+	assert.Equal(t, models.MoneyFromFloat(110.06), result.Jobs[0].RunningTotal)
+	assert.Equal(t, models.MoneyFromFloat(220.11), result.Jobs[1].RunningTotal)
+	assert.Equal(t, models.MoneyFromFloat(330.17), result.Jobs[2].RunningTotal)
+	assert.Equal(t, models.MoneyFromFloat(440.22), result.Jobs[3].RunningTotal)
+	assert.Equal(t, models.MoneyFromFloat(440.22), result.FinalTotal)
+
+	var sum models.Money
+	for _, job := range result.Jobs {
+		sum += job.Contribution
+	}
+	assert.Equal(t, result.FinalTotal-result.StartingTotal, sum)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryApproveRejectsUnconvertedCurrency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`AND mpl.currency <> b.currency`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"mpl_id", "job_id", "job_name", "material_id", "material_name", "currency", "boq_currency", "fx_rate", "converted"}).
+			AddRow(uuid.New(), uuid.New(), "Wall", "CEM-01", "Cement", "THB", "USD", 1.0, false).
+			AddRow(uuid.New(), uuid.New(), "Wall", "STL-02", "Steel", "EUR", "USD", 1.08, true))
+	mock.ExpectRollback()
+
+	err = repo.Approve(context.Background(), boqID, requests.ApproveBOQRequest{}, 2)
+	assert.ErrorIs(t, err, repositories.ErrMixedCurrency)
+
+	// Only the line that was never converted blocks approval.
+	assert.Contains(t, err.Error(), "Cement in Wall (THB)")
+	assert.NotContains(t, err.Error(), "Steel")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryApproveSnapshotsGrandTotal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE boq SET version = version \+ 1`).
+		WithArgs(boqID, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`AND mpl.currency <> b.currency`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"mpl_id", "job_id", "job_name", "material_id", "material_name", "currency", "boq_currency", "fx_rate", "converted"}))
+	mock.ExpectExec(`UPDATE boq SET status = 'approved' WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(uuid.New(), "Wall", "m2", 10.0, 50.0, nil, 30.0, 0, false))
+	mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100.0))
+	mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(10.0))
+	// 800 of direct works plus 10% preliminaries and 100 general cost, the
+	// same grand total the summary reports.
+	mock.ExpectExec(`INSERT INTO boq_cost_snapshot`).
+		WithArgs(sqlmock.AnyArg(), boqID, models.MoneyFromFloat(500), models.MoneyFromFloat(300),
+			models.MoneyFromFloat(100), models.MoneyFromFloat(80), models.MoneyFromFloat(980)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO material_price_snapshot`).
+		WithArgs(boqID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO boq_audit`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.Approve(context.Background(), boqID, requests.ApproveBOQRequest{}, 2)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetPreliminaries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(12.5))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(uuid.New(), "Wall", "m2", 10.0, 50.0, nil, 30.0, 0, false).
+			AddRow(uuid.New(), "Roof", "m2", 5.0, 20.0, nil, 40.0, 0, false))
+	mock.ExpectCommit()
+
+	preliminaries, err := repo.GetPreliminaries(context.Background(), boqID)
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, preliminaries.Percent)
+	assert.InDelta(t, 1100.0, preliminaries.DirectWorksSubtotal.Float64(), 0.001)
+	assert.InDelta(t, 137.5, preliminaries.Amount.Float64(), 0.001)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositorySetPreliminariesPercentRejectsOutOfRange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+	for _, percent := range []float64{-1, 100.5} {
+		err := repo.SetPreliminariesPercent(context.Background(), uuid.New(), requests.PreliminariesRequest{Percent: &percent})
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetBOQLifecycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+	snapshotID := uuid.New()
+	actorID := uuid.New()
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	approved := created.Add(48 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "approved"))
+	mock.ExpectQuery(`FROM boq_audit`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"action", "actor_id", "diff", "created_at"}).
+			AddRow("create", actorID, []byte(`{}`), created).
+			AddRow("approve", actorID, []byte(`{}`), approved).
+			AddRow("update_job", nil, []byte(`{"quantity":12}`), approved.Add(time.Hour)))
+	mock.ExpectQuery(`FROM boq_cost_snapshot`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot_id", "total_cost", "approved_at"}).
+			AddRow(snapshotID, 900.0, approved))
+	mock.ExpectQuery(`FROM boq_job bj`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit", "quantity", "labor_cost", "selling_price", "unit_material_cost", "unpriced_materials", "is_provisional"}).
+			AddRow(uuid.New(), "Wall", "m2", 12.0, 50.0, nil, 30.0, 0, false))
+	mock.ExpectQuery(`SELECT COALESCE\(selling_general_cost, 0\) FROM boq WHERE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100.0))
+	mock.ExpectQuery(`SELECT preliminaries_percent FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"preliminaries_percent"}).AddRow(10.0))
+	mock.ExpectCommit()
+
+	lifecycle, err := repo.GetBOQLifecycle(context.Background(), boqID)
+	require.NoError(t, err)
+
+	// The snapshot sorts after the approval audit row it shares a time with.
+	require.Len(t, lifecycle.Events, 4)
+	assert.Equal(t, "create", lifecycle.Events[0].Action)
+	assert.Equal(t, "audit", lifecycle.Events[1].Type)
+	assert.Equal(t, "approval_snapshot", lifecycle.Events[2].Type)
+	assert.Equal(t, &snapshotID, lifecycle.Events[2].SnapshotID)
+	assert.Equal(t, "update_job", lifecycle.Events[3].Action)
+	assert.Nil(t, lifecycle.Events[3].ActorID)
+
+	// The quantity was edited after approval, so the snapshot no longer
+	// matches. The current total includes 10% preliminaries on the 960 of
+	// direct works, like the summary's grand total.
+	require.NotNil(t, lifecycle.Integrity)
+	assert.Equal(t, models.MoneyFromFloat(1156.0), lifecycle.Integrity.CurrentTotal)
+	assert.Equal(t, models.MoneyFromFloat(256.0), lifecycle.Integrity.Difference)
+	assert.False(t, lifecycle.Integrity.Verified)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryAddBOQAttachment(t *testing.T) {
+	t.Run("scopes the reference to a job in the BOQ", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+		boqID := uuid.New()
+		jobID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+		mock.ExpectQuery(`SELECT 1 FROM boq_job`).
+			WithArgs(boqID, jobID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(`INSERT INTO boq_attachment`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), boqID, "add_attachment", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		attachment, err := repo.AddBOQAttachment(context.Background(), boqID, requests.BOQAttachmentRequest{
+			Type:      models.BOQAttachmentTypeQuote,
+			Reference: "  quotes/cement-2024.pdf ",
+			JobID:     &jobID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "quotes/cement-2024.pdf", attachment.Reference)
+		assert.Equal(t, &jobID, attachment.JobID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects a job that is not in the BOQ", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+		boqID := uuid.New()
+		jobID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+			WithArgs(boqID).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id", "status"}).AddRow(boqID, "draft"))
+		mock.ExpectQuery(`SELECT 1 FROM boq_job`).
+			WithArgs(boqID, jobID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		_, err = repo.AddBOQAttachment(context.Background(), boqID, requests.BOQAttachmentRequest{
+			Type:      models.BOQAttachmentTypeDrawing,
+			Reference: "drawings/A-101.dwg",
+			JobID:     &jobID,
+		})
+		assert.ErrorIs(t, err, repositories.ErrJobNotInBOQ)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects an unknown type", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+		_, err = repo.AddBOQAttachment(context.Background(), uuid.New(), requests.BOQAttachmentRequest{
+			Type:      "invoice",
+			Reference: "invoices/1.pdf",
+		})
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryDecideApproval(t *testing.T) {
+	approvalColumns := []string{"approval_id", "boq_id", "approver_id", "step", "status", "comment", "submitted_at", "decided_at"}
+
+	t.Run("records the current approver's rejection", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+		boqID := uuid.New()
+		approvalID := uuid.New()
+		approverID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq_approval_request`).
+			WithArgs(boqID, "pending").
+			WillReturnRows(sqlmock.NewRows(approvalColumns).
+				AddRow(approvalID, boqID, approverID, 2, "pending", nil, time.Now(), nil))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM boq_approval_request`).
+			WithArgs(boqID, "rejected").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(`UPDATE boq_approval_request`).
+			WithArgs("rejected", "labor rates too high", approvalID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO boq_audit`).
+			WithArgs(sqlmock.AnyArg(), boqID, "approval_decision", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.DecideApproval(context.Background(), boqID, approverID, requests.BOQApprovalDecisionRequest{
+			Approved: false,
+			Comment:  "labor rates too high",
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("refuses an approver whose step is not current", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+		boqID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM boq_approval_request`).
+			WithArgs(boqID, "pending").
+			WillReturnRows(sqlmock.NewRows(approvalColumns).
+				AddRow(uuid.New(), boqID, uuid.New(), 1, "pending", nil, time.Now(), nil))
+		mock.ExpectRollback()
+
+		err = repo.DecideApproval(context.Background(), boqID, uuid.New(), requests.BOQApprovalDecisionRequest{Approved: true})
+		assert.ErrorIs(t, err, repositories.ErrNotAwaitingApprover)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBOQRepositoryApproveRejectsMissingRequiredJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM boq WHERE boq_id = \$1 FOR UPDATE`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectQuery(`SELECT p.project_type\s+FROM boq b`).
+		WithArgs(boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"project_type"}).AddRow("house"))
+	mock.ExpectQuery(`FROM project_type_required_job ptr`).
+		WithArgs("house", boqID).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "name", "unit"}).AddRow(uuid.New(), "Footing", "m3"))
+	// The rejection comes before the version bump, so no UPDATE is expected.
+	mock.ExpectRollback()
+
+	err = repo.Approve(context.Background(), boqID, requests.ApproveBOQRequest{EnforceRequiredJobs: true}, 4)
+	assert.ErrorIs(t, err, repositories.ErrMissingRequiredJobs)
+	assert.Contains(t, err.Error(), "Footing")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryGetByIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+	boqID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 5000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT \* FROM boq WHERE boq_id = \$1`).
+		WithArgs(boqID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	// Read paths use GetByID as their existence check, so they report 404s.
+	_, err = repo.GetBOQAudit(context.Background(), boqID)
+	assert.ErrorIs(t, err, repositories.ErrBOQNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBOQRepositoryListBOQJobsRejectsUnknownSort(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := postgres.NewBOQRepository(sqlx.NewDb(db, "sqlmock"))
+
+	_, err = repo.ListBOQJobs(context.Background(), uuid.New(), requests.ListBOQJobsRequest{Sort: "price; DROP TABLE boq"})
+	assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,662 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/responses"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// materialUnitCost is the cost of one material_price_log row per unit of job.
+// BOQ figures are priced at the estimated price, converted to the BOQ
+// currency; an unpriced material counts as zero.
+const materialUnitCost = `COALESCE(mpl.estimated_price, 0) * COALESCE(mpl.fx_rate, 1) * COALESCE(mpl.quantity, 0)`
+
+// materialLineCost is materialUnitCost across the whole job quantity.
+const materialLineCost = materialUnitCost + ` * COALESCE(bj.quantity, 0)`
+
+// boqJobCost is one boq_job line with its material cost per unit of job.
+// Legacy rows may have no quantity or labor cost; both count as zero. Line
+// totals are rounded to the minor unit once, so sums of them are exact.
+type boqJobCost struct {
+	JobID             uuid.UUID        `db:"job_id"`
+	Name              string           `db:"name"`
+	Unit              string           `db:"unit"`
+	Quantity          sql.NullFloat64  `db:"quantity"`
+	LaborCost         models.NullMoney `db:"labor_cost"`
+	SellingPrice      models.NullMoney `db:"selling_price"`
+	UnitMaterialCost  float64          `db:"unit_material_cost"`
+	UnpricedMaterials int              `db:"unpriced_materials"`
+	IsProvisional     bool             `db:"is_provisional"`
+}
+
+func (c boqJobCost) LaborTotal() models.Money {
+	return c.LaborCost.Money.MulQuantity(c.Quantity.Float64)
+}
+
+func (c boqJobCost) MaterialTotal() models.Money {
+	return models.MoneyFromFloat(c.UnitMaterialCost * c.Quantity.Float64)
+}
+
+// MissingInputs reports whether the line has no quantity or no labor cost.
+func (c boqJobCost) MissingInputs() bool {
+	return !c.Quantity.Valid || !c.LaborCost.Valid
+}
+
+func (c boqJobCost) Total() models.Money {
+	return c.LaborTotal() + c.MaterialTotal()
+}
+
+func (r *boqRepository) getBOQJobCosts(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) ([]boqJobCost, error) {
+	return r.getBOQJobCostsFrom(ctx, q, boqID, materialPriceLogSource)
+}
+
+// getBOQJobCostsFrom is getBOQJobCosts with material prices read from prices,
+// materialPriceLogSource or materialPriceSnapshotSource.
+func (r *boqRepository) getBOQJobCostsFrom(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID, prices string) ([]boqJobCost, error) {
+	var costs []boqJobCost
+	err := sqlx.SelectContext(ctx, q, &costs, boqJobCostQueryFrom(prices, "bj.boq_id = $1"), boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BOQ job costs: %w", err)
+	}
+
+	return costs, nil
+}
+
+func (r *boqRepository) getBOQJobCost(ctx context.Context, q sqlx.QueryerContext, boqID, jobID uuid.UUID) (*boqJobCost, error) {
+	var cost boqJobCost
+	err := sqlx.GetContext(ctx, q, &cost, boqJobCostQuery("bj.boq_id = $1 AND bj.job_id = $2"), boqID, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrJobNotInBOQ
+		}
+		return nil, fmt.Errorf("failed to get BOQ job cost: %w", err)
+	}
+
+	return &cost, nil
+}
+
+func boqJobCostQuery(filter string) string {
+	return boqJobCostQueryFrom(materialPriceLogSource, filter)
+}
+
+func boqJobCostQueryFrom(prices, filter string) string {
+	return `
+        SELECT
+            j.job_id,
+            j.name,
+            j.unit,
+            bj.quantity,
+            bj.labor_cost,
+            bj.selling_price,
+            COALESCE(SUM(` + materialUnitCost + `), 0) as unit_material_cost,
+            COUNT(mpl.material_id) FILTER (WHERE mpl.estimated_price IS NULL) as unpriced_materials,
+            bj.is_provisional
+        FROM boq_job bj
+        JOIN job j ON j.job_id = bj.job_id
+        LEFT JOIN ` + prices + ` mpl ON mpl.boq_id = bj.boq_id AND mpl.job_id = bj.job_id
+        WHERE bj.deleted_at IS NULL AND ` + filter + `
+        GROUP BY j.job_id, j.name, j.unit, bj.quantity, bj.labor_cost, bj.selling_price, bj.is_provisional
+        ORDER BY j.name`
+}
+
+// getBOQGrandTotal returns labor, materials, preliminaries and the selling
+// general cost for the BOQ, priced as GetBOQSummary prices it: from the live
+// price log while it is a draft and from its approval snapshot after that.
+// RecalculateBOQTotal caches this figure in boq.total_cost.
+func (r *boqRepository) getBOQGrandTotal(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (models.Money, error) {
+	status, err := getBOQStatus(ctx, q, boqID)
+	if err != nil {
+		return 0, err
+	}
+
+	prices, err := materialPriceSourceFor(ctx, q, boqID, status)
+	if err != nil {
+		return 0, err
+	}
+
+	costs, err := r.getBOQJobCostsFrom(ctx, q, boqID, prices)
+	if err != nil {
+		return 0, err
+	}
+
+	var total models.Money
+	for _, cost := range costs {
+		total += cost.Total()
+	}
+
+	generalCost, err := getGeneralCostTotal(ctx, q, boqID)
+	if err != nil {
+		return 0, err
+	}
+
+	preliminaries, err := r.getPreliminariesPercent(ctx, q, boqID)
+	if err != nil {
+		return 0, err
+	}
+
+	return boqGrandTotal(total, generalCost, preliminaries), nil
+}
+
+// getBOQStatus returns the BOQ's status, or ErrBOQNotFound.
+func getBOQStatus(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (models.BOQStatus, error) {
+	var status models.BOQStatus
+	err := sqlx.GetContext(ctx, q, &status, `SELECT status FROM boq WHERE boq_id = $1`, boqID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", repositories.ErrBOQNotFound
+		}
+		return "", fmt.Errorf("failed to get BOQ status: %w", err)
+	}
+
+	return status, nil
+}
+
+// boqGrandTotal is the one definition of a BOQ grand total: the direct works,
+// preliminaries as a percentage of them, and the BOQ's selling general cost.
+func boqGrandTotal(directWorks, generalCost models.Money, preliminariesPercent float64) models.Money {
+	return directWorks + directWorks.Percent(preliminariesPercent) + generalCost
+}
+
+// getGeneralCostTotal returns boq.selling_general_cost, the general cost the
+// BOQ is priced with. The itemised general_cost estimates only inform it.
+func getGeneralCostTotal(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (models.Money, error) {
+	var total models.Money
+	query := `SELECT COALESCE(selling_general_cost, 0) FROM boq WHERE boq_id = $1`
+	if err := sqlx.GetContext(ctx, q, &total, query, boqID); err != nil {
+		return 0, fmt.Errorf("failed to get general cost total: %w", err)
+	}
+
+	return total, nil
+}
+
+// GetProvisionalShare returns the provisional jobs and their total as a share
+// of the BOQ grand total.
+func (r *boqRepository) GetProvisionalShare(ctx context.Context, boqID uuid.UUID) (*responses.ProvisionalShareResponse, error) {
+	var share *responses.ProvisionalShareResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		var err error
+		share, err = r.getProvisionalShare(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+func (r *boqRepository) getProvisionalShare(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (*responses.ProvisionalShareResponse, error) {
+	costs, err := r.getBOQJobCosts(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	grandTotal, err := r.getBOQGrandTotal(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	share := &responses.ProvisionalShareResponse{
+		BOQID:      boqID,
+		GrandTotal: grandTotal,
+		Items:      []responses.ProvisionalItemResponse{},
+	}
+	for _, cost := range costs {
+		if !cost.IsProvisional {
+			continue
+		}
+		share.Items = append(share.Items, responses.ProvisionalItemResponse{
+			JobID: cost.JobID,
+			Name:  cost.Name,
+			Total: cost.Total(),
+		})
+		share.ProvisionalTotal += cost.Total()
+	}
+	share.SharePercent = percentOf(share.ProvisionalTotal, grandTotal)
+
+	return share, nil
+}
+
+// percentOf returns part as a percentage of whole, or zero when whole is zero.
+func percentOf(part, whole models.Money) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole) * 100
+}
+
+func (r *boqRepository) GetPreferredSupplierShare(ctx context.Context, boqID uuid.UUID) (*responses.PreferredSupplierShareResponse, error) {
+	type SupplierSpend struct {
+		SupplierID   uuid.NullUUID `db:"supplier_id"`
+		SupplierName string        `db:"supplier_name"`
+		IsPreferred  bool          `db:"is_preferred"`
+		MaterialCost models.Money  `db:"material_cost"`
+	}
+
+	query := `
+        SELECT
+            s.supplier_id,
+            COALESCE(s.name, '') as supplier_name,
+            COALESCE(s.is_preferred, FALSE) as is_preferred,
+            COALESCE(SUM(` + materialLineCost + `), 0) as material_cost
+        FROM material_price_log mpl
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+        LEFT JOIN supplier s ON s.supplier_id = mpl.supplier_id
+        WHERE mpl.boq_id = $1
+        GROUP BY s.supplier_id, s.name, s.is_preferred
+        ORDER BY material_cost DESC`
+
+	var spends []SupplierSpend
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if err := tx.SelectContext(ctx, &spends, query, boqID); err != nil {
+			return fmt.Errorf("failed to get supplier spend: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	share := &responses.PreferredSupplierShareResponse{
+		BOQID:             boqID,
+		NonPreferredSpend: []responses.SupplierSpendResponse{},
+	}
+	for _, spend := range spends {
+		share.TotalMaterialCost += spend.MaterialCost
+		switch {
+		case !spend.SupplierID.Valid:
+			share.UnassignedCost += spend.MaterialCost
+		case spend.IsPreferred:
+			share.PreferredCost += spend.MaterialCost
+		default:
+			share.NonPreferredCost += spend.MaterialCost
+			share.NonPreferredSpend = append(share.NonPreferredSpend, responses.SupplierSpendResponse{
+				SupplierID:   spend.SupplierID.UUID,
+				SupplierName: spend.SupplierName,
+				MaterialCost: spend.MaterialCost,
+			})
+		}
+	}
+
+	if share.TotalMaterialCost > 0 {
+		share.PreferredShare = float64(share.PreferredCost) / float64(share.TotalMaterialCost)
+	}
+
+	return share, nil
+}
+
+// getExistingBOQJobCosts returns the BOQ's job costs, or ErrBOQNotFound when
+// there is no such BOQ.
+func (r *boqRepository) getExistingBOQJobCosts(ctx context.Context, boqID uuid.UUID) ([]boqJobCost, error) {
+	var costs []boqJobCost
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		var err error
+		costs, err = r.getBOQJobCosts(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return costs, nil
+}
+
+func (r *boqRepository) GetJobProfitability(ctx context.Context, boqID uuid.UUID) (*responses.BOQProfitabilityResponse, error) {
+	costs, err := r.getExistingBOQJobCosts(ctx, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildJobProfitability(boqID, costs), nil
+}
+
+func buildJobProfitability(boqID uuid.UUID, costs []boqJobCost) *responses.BOQProfitabilityResponse {
+	result := &responses.BOQProfitabilityResponse{
+		BOQID: boqID,
+		Jobs:  make([]responses.JobProfitabilityResponse, len(costs)),
+	}
+	for i, cost := range costs {
+		total := cost.Total()
+		selling := cost.SellingPrice.Money.MulQuantity(cost.Quantity.Float64)
+		margin := selling - total
+
+		result.Jobs[i] = responses.JobProfitabilityResponse{
+			JobID:         cost.JobID,
+			Name:          cost.Name,
+			Quantity:      cost.Quantity.Float64,
+			Cost:          total,
+			SellingPrice:  selling,
+			MarginAmount:  margin,
+			MarginPercent: percentOf(margin, selling),
+			MarkupPercent: percentOf(margin, total),
+			Priced:        cost.SellingPrice.Valid,
+		}
+
+		result.TotalCost += total
+		result.TotalSelling += selling
+	}
+
+	result.MarginAmount = result.TotalSelling - result.TotalCost
+	result.MarginPercent = percentOf(result.MarginAmount, result.TotalSelling)
+	result.MarkupPercent = percentOf(result.MarginAmount, result.TotalCost)
+
+	return result
+}
+
+// GetLinesExceedingMarkupCap returns the priced jobs whose markup over direct
+// cost is above maxMarkupPercent.
+func (r *boqRepository) GetLinesExceedingMarkupCap(ctx context.Context, boqID uuid.UUID, maxMarkupPercent float64) ([]responses.JobProfitabilityResponse, error) {
+	var exceeding []responses.JobProfitabilityResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		var err error
+		exceeding, err = r.findLinesExceedingMarkupCap(ctx, tx, boqID, maxMarkupPercent)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return exceeding, nil
+}
+
+func (r *boqRepository) findLinesExceedingMarkupCap(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID, maxMarkupPercent float64) ([]responses.JobProfitabilityResponse, error) {
+	if maxMarkupPercent < 0 {
+		return nil, fmt.Errorf("%w: markup cap cannot be negative", repositories.ErrInvalidInput)
+	}
+
+	costs, err := r.getBOQJobCosts(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	exceeding := []responses.JobProfitabilityResponse{}
+	for _, job := range buildJobProfitability(boqID, costs).Jobs {
+		if job.Priced && job.MarkupPercent > maxMarkupPercent {
+			exceeding = append(exceeding, job)
+		}
+	}
+
+	return exceeding, nil
+}
+
+// GetDirectCostFloor returns the price below which each job loses money on
+// direct cost alone, before general costs and profit. A floor is uncertain
+// when any of the job's materials has no estimated price yet.
+func (r *boqRepository) GetDirectCostFloor(ctx context.Context, boqID uuid.UUID) (*responses.BOQDirectCostFloorResponse, error) {
+	costs, err := r.getExistingBOQJobCosts(ctx, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &responses.BOQDirectCostFloorResponse{
+		BOQID: boqID,
+		Jobs:  make([]responses.JobDirectCostFloorResponse, len(costs)),
+	}
+	for i, cost := range costs {
+		floor := responses.JobDirectCostFloorResponse{
+			JobID:             cost.JobID,
+			Name:              cost.Name,
+			Unit:              cost.Unit,
+			Quantity:          cost.Quantity.Float64,
+			LaborCost:         cost.LaborTotal(),
+			MaterialCost:      cost.MaterialTotal(),
+			FloorPrice:        cost.Total(),
+			UnpricedMaterials: cost.UnpricedMaterials,
+			Uncertain:         cost.UnpricedMaterials > 0,
+		}
+		if cost.Quantity.Float64 != 0 {
+			floor.FloorUnitRate = models.MoneyFromFloat(floor.FloorPrice.Float64() / cost.Quantity.Float64)
+		}
+		result.Jobs[i] = floor
+
+		result.FloorPrice += floor.FloorPrice
+		if floor.Uncertain {
+			result.Uncertain = true
+		}
+	}
+
+	return result, nil
+}
+
+// GetSupplierMaterialRollup totals the BOQ's materials per selected supplier.
+// Materials without a supplier are grouped under a nil SupplierID, listed last.
+func (r *boqRepository) GetSupplierMaterialRollup(ctx context.Context, boqID uuid.UUID) ([]responses.SupplierMaterialRollupResponse, error) {
+	type RollupRow struct {
+		SupplierID   uuid.NullUUID `db:"supplier_id"`
+		SupplierName string        `db:"supplier_name"`
+		MaterialID   string        `db:"material_id"`
+		MaterialName string        `db:"material_name"`
+		Unit         string        `db:"unit"`
+		Quantity     float64       `db:"quantity"`
+		Cost         models.Money  `db:"cost"`
+	}
+
+	query := `
+        SELECT
+            s.supplier_id,
+            COALESCE(s.name, '') as supplier_name,
+            m.material_id,
+            m.name as material_name,
+            m.unit,
+            COALESCE(SUM(COALESCE(mpl.quantity, 0) * COALESCE(bj.quantity, 0)), 0) as quantity,
+            COALESCE(SUM(` + materialLineCost + `), 0) as cost
+        FROM material_price_log mpl
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+        JOIN material m ON m.material_id = mpl.material_id
+        LEFT JOIN supplier s ON s.supplier_id = mpl.supplier_id
+        WHERE mpl.boq_id = $1
+        GROUP BY s.supplier_id, s.name, m.material_id, m.name, m.unit
+        ORDER BY s.supplier_id IS NULL, s.name, m.name`
+
+	var rows []RollupRow
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		if err := tx.SelectContext(ctx, &rows, query, boqID); err != nil {
+			return fmt.Errorf("failed to get supplier material rollup: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rollup := []responses.SupplierMaterialRollupResponse{}
+	for _, row := range rows {
+		last := len(rollup) - 1
+		if last < 0 || rollup[last].SupplierID != row.SupplierID {
+			rollup = append(rollup, responses.SupplierMaterialRollupResponse{
+				SupplierID:   row.SupplierID,
+				SupplierName: row.SupplierName,
+				Materials:    []responses.RollupMaterialResponse{},
+			})
+			last++
+		}
+
+		material := responses.RollupMaterialResponse{
+			MaterialID: row.MaterialID,
+			Name:       row.MaterialName,
+			Unit:       row.Unit,
+			Quantity:   row.Quantity,
+			Cost:       row.Cost,
+		}
+		if row.Quantity != 0 {
+			material.UnitPrice = models.MoneyFromFloat(row.Cost.Float64() / row.Quantity)
+		}
+		rollup[last].Materials = append(rollup[last].Materials, material)
+		rollup[last].TotalCost += row.Cost
+	}
+
+	return rollup, nil
+}
+
+// GetBOQMaterialTotals lists every material the BOQ needs with its quantity
+// summed over all jobs, so a material used by several jobs appears once.
+// Totals use the material's latest recorded estimated price.
+func (r *boqRepository) GetBOQMaterialTotals(ctx context.Context, boqID uuid.UUID) ([]responses.MaterialTotalResponse, error) {
+	query := `
+        WITH lines AS (
+            SELECT
+                mpl.material_id,
+                COALESCE(mpl.quantity, 0) * COALESCE(bj.quantity, 0) as quantity,
+                mpl.estimated_price * COALESCE(mpl.fx_rate, 1) as unit_price,
+                mpl.updated_at
+            FROM material_price_log mpl
+            JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+            WHERE mpl.boq_id = $1
+        )
+        SELECT
+            m.material_id,
+            m.name,
+            m.unit,
+            COALESCE(SUM(l.quantity), 0) as quantity,
+            (
+                SELECT latest.unit_price FROM lines latest
+                WHERE latest.material_id = m.material_id AND latest.unit_price IS NOT NULL
+                ORDER BY latest.updated_at DESC NULLS LAST
+                LIMIT 1
+            ) as unit_price
+        FROM lines l
+        JOIN material m ON m.material_id = l.material_id
+        GROUP BY m.material_id, m.name, m.unit
+        ORDER BY m.name`
+
+	totals := []responses.MaterialTotalResponse{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var exists bool
+		err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM boq WHERE boq_id = $1)`, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to get BOQ: %w", err)
+		}
+		if !exists {
+			return repositories.ErrBOQNotFound
+		}
+
+		if err := tx.SelectContext(ctx, &totals, query, boqID); err != nil {
+			return fmt.Errorf("failed to get BOQ material totals: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range totals {
+		if totals[i].UnitPrice.Valid {
+			totals[i].Total = totals[i].UnitPrice.Money.MulQuantity(totals[i].Quantity)
+		}
+	}
+
+	return totals, nil
+}
+
+// GetHighLaborShareBOQs returns BOQs whose labor cost is more than threshold
+// (a fraction between 0 and 1) of their direct labor and material cost.
+func (r *boqRepository) GetHighLaborShareBOQs(ctx context.Context, threshold float64) ([]responses.BOQLaborShareResponse, error) {
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("%w: threshold must be between 0 and 1", repositories.ErrInvalidInput)
+	}
+
+	query := `
+        WITH material_totals AS (
+            SELECT boq_id, job_id,
+                SUM(COALESCE(estimated_price, 0) * COALESCE(fx_rate, 1) * COALESCE(quantity, 0)) as unit_material_cost
+            FROM material_price_log
+            GROUP BY boq_id, job_id
+        ), boq_totals AS (
+            SELECT
+                bj.boq_id,
+                SUM(COALESCE(bj.labor_cost, 0) * COALESCE(bj.quantity, 0)) as labor_cost,
+                SUM(COALESCE(mt.unit_material_cost, 0) * COALESCE(bj.quantity, 0)) as material_cost
+            FROM boq_job bj
+            LEFT JOIN material_totals mt ON mt.boq_id = bj.boq_id AND mt.job_id = bj.job_id
+            WHERE bj.deleted_at IS NULL
+            GROUP BY bj.boq_id
+        )
+        SELECT
+            b.boq_id,
+            p.project_id,
+            p.name as project_name,
+            b.status,
+            bt.labor_cost,
+            bt.material_cost,
+            bt.labor_cost / (bt.labor_cost + bt.material_cost) as labor_share
+        FROM boq_totals bt
+        JOIN boq b ON b.boq_id = bt.boq_id
+        JOIN project p ON p.project_id = b.project_id
+        WHERE bt.labor_cost + bt.material_cost > 0
+        AND bt.labor_cost / (bt.labor_cost + bt.material_cost) > $1
+        ORDER BY labor_share DESC`
+
+	boqs := []responses.BOQLaborShareResponse{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &boqs, query, threshold); err != nil {
+			return fmt.Errorf("failed to get high labor share BOQs: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return boqs, nil
+}
+
+// GetOversizedBOQs lists BOQs holding more than limit jobs, largest first. A
+// non-positive limit uses the repository's configured maximum.
+func (r *boqRepository) GetOversizedBOQs(ctx context.Context, limit int) ([]responses.BOQJobCountResponse, error) {
+	if limit <= 0 {
+		limit = r.maxJobs
+	}
+
+	query := `
+        SELECT
+            b.boq_id,
+            p.project_id,
+            p.name as project_name,
+            b.status,
+            COUNT(bj.job_id) as job_count
+        FROM boq b
+        JOIN project p ON p.project_id = b.project_id
+        JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
+        GROUP BY b.boq_id, p.project_id, p.name, b.status
+        HAVING COUNT(bj.job_id) > $1
+        ORDER BY job_count DESC`
+
+	boqs := []responses.BOQJobCountResponse{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &boqs, query, limit); err != nil {
+			return fmt.Errorf("failed to get oversized BOQs: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return boqs, nil
+}
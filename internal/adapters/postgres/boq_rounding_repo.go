@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// PreviewRateRounding rounds each job's unit rate to the nearest multiple of
+// roundTo and reports the effect on line and grand totals without saving.
+// The unit rate is the job's selling price when set, otherwise its direct
+// cost per unit.
+func (r *boqRepository) PreviewRateRounding(ctx context.Context, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error) {
+	var result *responses.RateRoundingResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := getBOQByID(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = r.previewRateRounding(ctx, tx, boqID, roundTo)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) previewRateRounding(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error) {
+	increment := models.MoneyFromFloat(roundTo)
+	if increment <= 0 {
+		return nil, fmt.Errorf("%w: rounding increment must be positive", repositories.ErrInvalidInput)
+	}
+
+	costs, err := r.getBOQJobCosts(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &responses.RateRoundingResponse{
+		BOQID:   boqID,
+		RoundTo: increment,
+		Jobs:    make([]responses.JobRateRoundingResponse, len(costs)),
+	}
+	for i, cost := range costs {
+		rate := cost.LaborCost.Money + models.MoneyFromFloat(cost.UnitMaterialCost)
+		if cost.SellingPrice.Valid {
+			rate = cost.SellingPrice.Money
+		}
+		rounded := roundToIncrement(rate, increment)
+
+		line := responses.JobRateRoundingResponse{
+			JobID:       cost.JobID,
+			Name:        cost.Name,
+			Quantity:    cost.Quantity.Float64,
+			UnitRate:    rate,
+			RoundedRate: rounded,
+			LineTotal:   rate.MulQuantity(cost.Quantity.Float64),
+			RoundedLine: rounded.MulQuantity(cost.Quantity.Float64),
+		}
+		line.Delta = line.RoundedLine - line.LineTotal
+		result.Jobs[i] = line
+
+		result.GrandTotal += line.LineTotal
+		result.RoundedGrandTotal += line.RoundedLine
+	}
+	result.Delta = result.RoundedGrandTotal - result.GrandTotal
+
+	return result, nil
+}
+
+// ApplyRateRounding stores the rounded rates from PreviewRateRounding as the
+// jobs' selling prices. Only draft BOQs can be changed.
+func (r *boqRepository) ApplyRateRounding(ctx context.Context, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error) {
+	var result *responses.RateRoundingResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		preview, err := r.previewRateRounding(ctx, tx, boqID, roundTo)
+		if err != nil {
+			return err
+		}
+		result = preview
+
+		query := `
+        UPDATE boq_job
+        SET selling_price = $1
+        WHERE boq_id = $2 AND job_id = $3 AND deleted_at IS NULL`
+
+		for _, job := range result.Jobs {
+			_, err = tx.ExecContext(ctx, query, job.RoundedRate, boqID, job.JobID)
+			if err != nil {
+				return fmt.Errorf("failed to update job selling price: %w", err)
+			}
+		}
+
+		if err := touchBOQ(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		diff := map[string]interface{}{"round_to": roundTo, "jobs": len(result.Jobs)}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionRoundRates, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func roundToIncrement(value, increment models.Money) models.Money {
+	return models.Money(math.Round(float64(value)/float64(increment))) * increment
+}
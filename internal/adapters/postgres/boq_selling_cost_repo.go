@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ApplyGeneralCostPercentage sets selling_general_cost of a draft BOQ to
+// percent of its labor and material base and returns the stored amount. The
+// amount is fixed at the time of the call; later job changes do not move it.
+func (r *boqRepository) ApplyGeneralCostPercentage(ctx context.Context, boqID uuid.UUID, percent float64) (float64, error) {
+	if percent < 0 {
+		return 0, fmt.Errorf("%w: general cost percentage cannot be negative", repositories.ErrInvalidInput)
+	}
+
+	var amount float64
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := requireDraft(ctx, tx, boqID); err != nil {
+			return err
+		}
+
+		costs, err := r.getBOQJobCosts(ctx, tx, boqID)
+		if err != nil {
+			return err
+		}
+
+		var base models.Money
+		for _, cost := range costs {
+			base += cost.Total()
+		}
+
+		amount = base.Percent(percent).Float64()
+
+		_, err = tx.ExecContext(ctx, `UPDATE boq SET selling_general_cost = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE boq_id = $2`, amount, boqID)
+		if err != nil {
+			return fmt.Errorf("failed to update selling general cost: %w", err)
+		}
+
+		diff := map[string]float64{"percent": percent, "selling_general_cost": amount}
+		if err := writeBOQAudit(ctx, tx, boqID, auditActionGeneralCost, diff); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return amount, nil
+}
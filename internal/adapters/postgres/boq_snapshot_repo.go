@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// costCompositionBuckets maps the accepted trend buckets to date_trunc fields.
+var costCompositionBuckets = map[string]string{
+	"week":    "week",
+	"month":   "month",
+	"quarter": "quarter",
+	"year":    "year",
+}
+
+const (
+	// materialPriceLogSource reads the live material prices of a BOQ.
+	materialPriceLogSource = `material_price_log`
+	// materialPriceSnapshotSource reads the prices frozen by the BOQ's latest
+	// approval, with the material_price_log columns the cost queries use.
+	materialPriceSnapshotSource = `(
+            SELECT s.boq_id, s.job_id, s.material_id, s.quantity, s.estimated_price, s.fx_rate
+            FROM material_price_snapshot s
+            WHERE s.snapshot_at = (
+                SELECT MAX(latest.snapshot_at) FROM material_price_snapshot latest
+                WHERE latest.boq_id = s.boq_id
+            )
+        )`
+)
+
+// writeMaterialPriceSnapshot copies the price and quantity of every live
+// material_price_log row of the BOQ. It is called from Approve inside the
+// approval transaction; all rows of one snapshot share its snapshot_at.
+func writeMaterialPriceSnapshot(ctx context.Context, tx *sqlx.Tx, boqID uuid.UUID) error {
+	query := `
+        INSERT INTO material_price_snapshot (
+            boq_id, job_id, material_id, quantity, estimated_price, fx_rate, snapshot_at
+        )
+        SELECT mpl.boq_id, mpl.job_id, mpl.material_id, mpl.quantity, mpl.estimated_price, mpl.fx_rate, CURRENT_TIMESTAMP
+        FROM material_price_log mpl
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+        WHERE mpl.boq_id = $1`
+
+	_, err := tx.ExecContext(ctx, query, boqID)
+	if err != nil {
+		return fmt.Errorf("failed to write material price snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// hasMaterialPriceSnapshot reports whether the BOQ has been approved since
+// material prices started being snapshotted.
+func hasMaterialPriceSnapshot(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (bool, error) {
+	var exists bool
+	err := sqlx.GetContext(ctx, q, &exists, `SELECT EXISTS (SELECT 1 FROM material_price_snapshot WHERE boq_id = $1)`, boqID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check material price snapshot: %w", err)
+	}
+
+	return exists, nil
+}
+
+// materialPriceSourceFor returns the prices a BOQ in the given status is
+// totalled with: the live price log while it is a draft, and the prices
+// frozen at its latest approval after that, if it has any.
+func materialPriceSourceFor(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID, status models.BOQStatus) (string, error) {
+	if status == models.BOQStatusDraft {
+		return materialPriceLogSource, nil
+	}
+
+	snapshotted, err := hasMaterialPriceSnapshot(ctx, q, boqID)
+	if err != nil {
+		return "", err
+	}
+	if snapshotted {
+		return materialPriceSnapshotSource, nil
+	}
+
+	return materialPriceLogSource, nil
+}
+
+// boqCostComposition is the BOQ cost split into labor, material, overhead
+// (general cost) and preliminaries.
+type boqCostComposition struct {
+	Labor                models.Money
+	Material             models.Money
+	Overhead             models.Money
+	PreliminariesPercent float64
+}
+
+// Total is the BOQ grand total, computed the same way as the summary.
+func (c boqCostComposition) Total() models.Money {
+	return boqGrandTotal(c.Labor+c.Material, c.Overhead, c.PreliminariesPercent)
+}
+
+// Preliminaries is the preliminaries amount charged on the direct works.
+func (c boqCostComposition) Preliminaries() models.Money {
+	return c.Total() - c.Labor - c.Material - c.Overhead
+}
+
+// writeBOQCostSnapshot freezes the BOQ's labor, material, overhead and
+// preliminaries costs and its grand total. It is called from Approve inside
+// the approval transaction.
+func (r *boqRepository) writeBOQCostSnapshot(ctx context.Context, tx *sqlx.Tx, boqID uuid.UUID) error {
+	composition, err := r.getBOQCostComposition(ctx, tx, boqID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO boq_cost_snapshot (
+            snapshot_id, boq_id, labor_cost, material_cost, overhead_cost, preliminaries_cost, total_cost
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = tx.ExecContext(ctx, query, uuid.New(), boqID,
+		composition.Labor, composition.Material, composition.Overhead, composition.Preliminaries(), composition.Total())
+	if err != nil {
+		return fmt.Errorf("failed to write BOQ cost snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// getBOQCostComposition splits the current BOQ cost the same way the approval
+// snapshot does, using the live material prices.
+func (r *boqRepository) getBOQCostComposition(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (boqCostComposition, error) {
+	var composition boqCostComposition
+
+	costs, err := r.getBOQJobCosts(ctx, q, boqID)
+	if err != nil {
+		return composition, err
+	}
+
+	for _, cost := range costs {
+		composition.Labor += cost.LaborTotal()
+		composition.Material += cost.MaterialTotal()
+	}
+
+	composition.Overhead, err = getGeneralCostTotal(ctx, q, boqID)
+	if err != nil {
+		return composition, err
+	}
+
+	composition.PreliminariesPercent, err = r.getPreliminariesPercent(ctx, q, boqID)
+	if err != nil {
+		return composition, err
+	}
+
+	return composition, nil
+}
+
+// GetCostCompositionTrend averages the labor, material, overhead and
+// preliminaries share of
+// BOQs approved in [from, to), per bucket. It reads the approval snapshots so
+// later price edits do not change historical figures.
+func (r *boqRepository) GetCostCompositionTrend(ctx context.Context, from, to time.Time, bucket string) ([]responses.CostCompositionPointResponse, error) {
+	field, ok := costCompositionBuckets[bucket]
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid bucket %q, must be week, month, quarter or year", repositories.ErrInvalidInput, bucket)
+	}
+
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to must be after from", repositories.ErrInvalidInput)
+	}
+
+	query := `
+        WITH latest AS (
+            SELECT DISTINCT ON (s.boq_id) s.*
+            FROM boq_cost_snapshot s
+            JOIN boq b ON b.boq_id = s.boq_id
+            WHERE b.status = 'approved'
+            ORDER BY s.boq_id, s.approved_at DESC
+        )
+        SELECT
+            date_trunc('` + field + `', approved_at) as bucket_start,
+            COUNT(*) as boq_count,
+            AVG(labor_cost / total_cost) * 100 as labor_percent,
+            AVG(material_cost / total_cost) * 100 as material_percent,
+            AVG(overhead_cost / total_cost) * 100 as overhead_percent,
+            AVG(preliminaries_cost / total_cost) * 100 as preliminaries_percent
+        FROM latest
+        WHERE approved_at >= $1 AND approved_at < $2
+        AND total_cost > 0
+        GROUP BY bucket_start
+        ORDER BY bucket_start`
+
+	trend := []responses.CostCompositionPointResponse{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &trend, query, from, to); err != nil {
+			return fmt.Errorf("failed to get cost composition trend: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return trend, nil
+}
@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/responses"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// GetBOQSummary totals a BOQ from its lines: labor and materials per job,
+// preliminaries on top of them and the BOQ's selling general cost, as in
+// boqGrandTotal. The itemised general cost estimates are listed but not
+// added again. Jobs with a material that has no price yet are
+// flagged incomplete rather than having the missing price counted as zero,
+// as are legacy lines without a quantity or labor cost, which contribute
+// nothing.
+// Once approved, a BOQ is totalled with the material prices frozen at its
+// latest approval instead of the live price log.
+func (r *boqRepository) GetBOQSummary(ctx context.Context, boqID uuid.UUID) (*responses.BOQSummaryResponse, error) {
+	var summary *responses.BOQSummaryResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		summary, err = r.getBOQSummary(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (r *boqRepository) getBOQSummary(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (*responses.BOQSummaryResponse, error) {
+	var header struct {
+		ProjectName          string           `db:"name"`
+		ProjectAddress       sql.NullString   `db:"address"`
+		PreliminariesPercent sql.NullFloat64  `db:"preliminaries_percent"`
+		SellingGeneralCost   models.NullMoney `db:"selling_general_cost"`
+		Status               models.BOQStatus `db:"status"`
+	}
+
+	headerQuery := `
+        SELECT p.name, p.address, b.preliminaries_percent, b.selling_general_cost, b.status
+        FROM boq b
+        JOIN project p ON p.project_id = b.project_id
+        WHERE b.boq_id = $1`
+
+	err := sqlx.GetContext(ctx, q, &header, headerQuery, boqID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrBOQNotFound
+		}
+		return nil, fmt.Errorf("failed to get BOQ: %w", err)
+	}
+
+	generalCosts, err := getBOQGeneralCosts(ctx, q, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get general costs: %w", err)
+	}
+
+	prices, err := materialPriceSourceFor(ctx, q, boqID, header.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	costs, err := r.getBOQJobCostsFrom(ctx, q, boqID, prices)
+	if err != nil {
+		return nil, err
+	}
+
+	materialsQuery := `
+        SELECT
+            mpl.job_id,
+            j.name,
+            m.name as material_name,
+            mpl.quantity,
+            m.unit,
+            mpl.estimated_price,
+            COALESCE(mpl.quantity, 0) * COALESCE(mpl.estimated_price, 0) * COALESCE(mpl.fx_rate, 1) as total
+        FROM ` + prices + ` mpl
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+        JOIN job j ON j.job_id = mpl.job_id
+        JOIN material m ON m.material_id = mpl.material_id
+        WHERE mpl.boq_id = $1
+        ORDER BY m.name`
+
+	var materials []models.BOQMaterialDetails
+	err = sqlx.SelectContext(ctx, q, &materials, materialsQuery, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get material details: %w", err)
+	}
+
+	materialsByJob := make(map[uuid.UUID][]responses.MaterialDTO)
+	for _, material := range materials {
+		materialsByJob[material.JobID] = append(materialsByJob[material.JobID], responses.MaterialDTO{
+			JobID:          material.JobID,
+			JobName:        material.JobName,
+			MaterialName:   material.MaterialName,
+			Quantity:       material.Quantity.Float64,
+			Unit:           material.Unit,
+			EstimatedPrice: material.EstimatedPrice.Money,
+			Total:          material.Total.Money,
+		})
+	}
+
+	summary := &responses.BOQSummaryResponse{
+		ProjectInfo: responses.ProjectInfo{
+			ProjectName: header.ProjectName,
+		},
+		GeneralCosts: make([]responses.GeneralCostDTO, len(generalCosts)),
+		Details:      make([]responses.BOQDetailDTO, len(costs)),
+	}
+	if header.ProjectAddress.Valid {
+		summary.ProjectInfo.ProjectAddress = json.RawMessage(header.ProjectAddress.String)
+	}
+
+	metrics := &summary.SummaryMetrics
+	for i, cost := range generalCosts {
+		summary.GeneralCosts[i] = responses.GeneralCostDTO{
+			TypeName:      cost.TypeName,
+			EstimatedCost: cost.EstimatedCost,
+		}
+	}
+	metrics.TotalGeneralCost = header.SellingGeneralCost.Money
+
+	for i, cost := range costs {
+		jobMaterials := materialsByJob[cost.JobID]
+		if jobMaterials == nil {
+			jobMaterials = []responses.MaterialDTO{}
+		}
+
+		summary.Details[i] = responses.BOQDetailDTO{
+			JobID:               cost.JobID,
+			JobName:             cost.Name,
+			Quantity:            cost.Quantity.Float64,
+			Unit:                cost.Unit,
+			LaborCost:           cost.LaborCost.Money,
+			EstimatedPrice:      models.MoneyFromFloat(cost.UnitMaterialCost),
+			TotalEstimatedPrice: cost.MaterialTotal(),
+			TotalLaborCost:      cost.LaborTotal(),
+			Total:               cost.Total(),
+			Materials:           jobMaterials,
+			Incomplete:          cost.UnpricedMaterials > 0 || cost.MissingInputs(),
+		}
+
+		metrics.TotalLaborCost += cost.LaborTotal()
+		metrics.TotalMaterialCost += cost.MaterialTotal()
+		metrics.TotalEstimatedPrice += cost.MaterialTotal()
+		metrics.TotalAmount += cost.Total()
+		if cost.UnpricedMaterials > 0 || cost.MissingInputs() {
+			metrics.IncompleteJobs++
+		}
+	}
+
+	metrics.TotalPreliminaries = metrics.TotalAmount.Percent(header.PreliminariesPercent.Float64)
+	metrics.GrandTotal = boqGrandTotal(metrics.TotalAmount, metrics.TotalGeneralCost, header.PreliminariesPercent.Float64)
+
+	return summary, nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// materialTotalChangeRatio is the relative change in a cached total that is
+// reported back as material after a recalculation.
+const materialTotalChangeRatio = 0.01
+
+// RecalculateBOQTotal caches the BOQ's grand total in boq.total_cost and
+// returns it. The cached figure is the grand total GetBOQSummary reports.
+func (r *boqRepository) RecalculateBOQTotal(ctx context.Context, boqID uuid.UUID) (models.Money, error) {
+	var total models.Money
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		changes, err := r.recalculateTotals(ctx, tx, "boq_id = $1", boqID)
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			return repositories.ErrBOQNotFound
+		}
+
+		total = changes[0].NewTotal
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *boqRepository) RecalculateProjectBOQTotals(ctx context.Context, projectID uuid.UUID) (*responses.RecalculateTotalsResponse, error) {
+	var changes []responses.BOQTotalChangeResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		changes, err = r.recalculateTotals(ctx, tx, "project_id = $1", projectID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &responses.RecalculateTotalsResponse{
+		ProjectID: projectID,
+		Updated:   len(changes),
+		Changed:   []responses.BOQTotalChangeResponse{},
+	}
+	for _, change := range changes {
+		change.Difference = change.NewTotal - change.PreviousTotal
+		if isMaterialChange(change.PreviousTotal, change.NewTotal) {
+			result.Changed = append(result.Changed, change)
+		}
+	}
+
+	return result, nil
+}
+
+// recalculateTotals locks every BOQ matching the filter, replaces its cached
+// total_cost with getBOQGrandTotal and returns the cached value it replaced.
+func (r *boqRepository) recalculateTotals(ctx context.Context, tx *sqlx.Tx, filter string, arg interface{}) ([]responses.BOQTotalChangeResponse, error) {
+	var changes []responses.BOQTotalChangeResponse
+	query := `
+        SELECT boq_id, COALESCE(total_cost, 0) as previous_total
+        FROM boq
+        WHERE ` + filter + `
+        ORDER BY boq_id
+        FOR UPDATE`
+
+	if err := tx.SelectContext(ctx, &changes, query, arg); err != nil {
+		return nil, fmt.Errorf("failed to get BOQ totals: %w", err)
+	}
+
+	for i := range changes {
+		total, err := r.getBOQGrandTotal(ctx, tx, changes[i].BOQID)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE boq SET total_cost = $1 WHERE boq_id = $2`, total, changes[i].BOQID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recalculate BOQ total: %w", err)
+		}
+
+		changes[i].NewTotal = total
+	}
+
+	return changes, nil
+}
+
+func isMaterialChange(previous, current models.Money) bool {
+	if previous == 0 {
+		return current != 0
+	}
+	return math.Abs(float64(current-previous))/math.Abs(float64(previous)) >= materialTotalChangeRatio
+}
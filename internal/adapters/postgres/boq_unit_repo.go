@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (r *boqRepository) GetNonCanonicalUnits(ctx context.Context) ([]responses.NonCanonicalUnitResponse, error) {
+	var result []responses.NonCanonicalUnitResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.findNonCanonicalUnits(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) findNonCanonicalUnits(ctx context.Context, q sqlx.QueryerContext) ([]responses.NonCanonicalUnitResponse, error) {
+	query := `
+        SELECT 'job' as source, job_id::text as id, name, unit FROM job
+        UNION ALL
+        SELECT 'material' as source, material_id as id, name, unit FROM material
+        ORDER BY source, name`
+
+	var units []responses.NonCanonicalUnitResponse
+	err := sqlx.SelectContext(ctx, q, &units, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get catalog units: %w", err)
+	}
+
+	result := []responses.NonCanonicalUnitResponse{}
+	for _, unit := range units {
+		if models.IsCanonicalUnit(unit.Unit) {
+			continue
+		}
+		unit.CanonicalUnit, unit.Mappable = models.NormalizeUnit(unit.Unit)
+		if !unit.Mappable {
+			unit.CanonicalUnit = ""
+		}
+		result = append(result, unit)
+	}
+
+	return result, nil
+}
+
+// NormalizeUnits rewrites every job and material unit that has a known
+// canonical form. Units that cannot be mapped are returned for manual review.
+func (r *boqRepository) NormalizeUnits(ctx context.Context) (*responses.NormalizeUnitsResponse, error) {
+	var result *responses.NormalizeUnitsResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		units, err := r.findNonCanonicalUnits(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		result = &responses.NormalizeUnitsResponse{
+			Unmapped: []responses.NonCanonicalUnitResponse{},
+		}
+		for _, unit := range units {
+			if !unit.Mappable {
+				result.Unmapped = append(result.Unmapped, unit)
+				continue
+			}
+
+			switch unit.Source {
+			case "job":
+				_, err = tx.ExecContext(ctx, `UPDATE job SET unit = $1 WHERE job_id = $2::uuid`, unit.CanonicalUnit, unit.ID)
+				result.JobsUpdated++
+			case "material":
+				_, err = tx.ExecContext(ctx, `UPDATE material SET unit = $1 WHERE material_id = $2`, unit.CanonicalUnit, unit.ID)
+				result.MaterialsUpdated++
+			}
+			if err != nil {
+				return fmt.Errorf("failed to normalize %s unit: %w", unit.Source, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	exportIssueError   = "error"
+	exportIssueWarning = "warning"
+)
+
+// ValidateBOQForExport runs the consistency checks an exported document must
+// pass. Issues with error severity block exports; warnings are reported only.
+func (r *boqRepository) ValidateBOQForExport(ctx context.Context, boqID uuid.UUID) (*responses.BOQExportValidationResponse, error) {
+	var result *responses.BOQExportValidationResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.validateBOQForExport(ctx, tx, boqID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) validateBOQForExport(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID) (*responses.BOQExportValidationResponse, error) {
+	if _, err := getBOQByID(ctx, q, boqID); err != nil {
+		return nil, err
+	}
+
+	result := &responses.BOQExportValidationResponse{
+		BOQID:  boqID,
+		Issues: []responses.BOQExportIssueResponse{},
+	}
+
+	orphans, err := r.findOrphanedPriceLogs(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+	for _, orphan := range orphans {
+		jobID := orphan.JobID
+		result.Issues = append(result.Issues, responses.BOQExportIssueResponse{
+			Code:       "orphaned_price_log",
+			Severity:   exportIssueError,
+			Message:    fmt.Sprintf("%s is no longer a material of job %s", orphan.MaterialName, orphan.JobName),
+			JobID:      &jobID,
+			MaterialID: orphan.MaterialID,
+		})
+	}
+
+	type LineIssue struct {
+		JobID      uuid.UUID `db:"job_id"`
+		JobName    string    `db:"job_name"`
+		MaterialID string    `db:"material_id"`
+	}
+
+	checks := []struct {
+		code     string
+		severity string
+		message  func(line LineIssue) string
+		query    string
+	}{
+		{
+			code:     "missing_material",
+			severity: exportIssueError,
+			message: func(line LineIssue) string {
+				return fmt.Sprintf("material %s of job %s is not in the catalog", line.MaterialID, line.JobName)
+			},
+			query: `
+                SELECT mpl.job_id, j.name as job_name, mpl.material_id
+                FROM material_price_log mpl
+                JOIN job j ON j.job_id = mpl.job_id
+                LEFT JOIN material m ON m.material_id = mpl.material_id
+                WHERE mpl.boq_id = $1 AND (m.material_id IS NULL OR m.name = '')
+                ORDER BY j.name, mpl.material_id`,
+		},
+		{
+			code:     "unpriced_material",
+			severity: exportIssueWarning,
+			message: func(line LineIssue) string {
+				return fmt.Sprintf("material %s of job %s has no estimated price", line.MaterialID, line.JobName)
+			},
+			query: `
+                SELECT mpl.job_id, j.name as job_name, mpl.material_id
+                FROM material_price_log mpl
+                JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+                JOIN job j ON j.job_id = mpl.job_id
+                WHERE mpl.boq_id = $1 AND mpl.estimated_price IS NULL
+                ORDER BY j.name, mpl.material_id`,
+		},
+		{
+			code:     "negative_line",
+			severity: exportIssueError,
+			message: func(line LineIssue) string {
+				return fmt.Sprintf("material %s of job %s has a negative price or quantity", line.MaterialID, line.JobName)
+			},
+			query: `
+                SELECT mpl.job_id, j.name as job_name, mpl.material_id
+                FROM material_price_log mpl
+                JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+                JOIN job j ON j.job_id = mpl.job_id
+                WHERE mpl.boq_id = $1
+                AND (mpl.quantity < 0 OR mpl.estimated_price < 0 OR mpl.actual_price < 0)
+                ORDER BY j.name, mpl.material_id`,
+		},
+		{
+			code:     "negative_line",
+			severity: exportIssueError,
+			message: func(line LineIssue) string {
+				return fmt.Sprintf("job %s has a negative quantity, labor cost or selling price", line.JobName)
+			},
+			query: `
+                SELECT bj.job_id, j.name as job_name, '' as material_id
+                FROM boq_job bj
+                JOIN job j ON j.job_id = bj.job_id
+                WHERE bj.boq_id = $1 AND bj.deleted_at IS NULL
+                AND (bj.quantity < 0 OR bj.labor_cost < 0 OR bj.selling_price < 0)
+                ORDER BY j.name`,
+		},
+	}
+
+	for _, check := range checks {
+		var lines []LineIssue
+		err := sqlx.SelectContext(ctx, q, &lines, check.query, boqID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %s check: %w", check.code, err)
+		}
+
+		for _, line := range lines {
+			jobID := line.JobID
+			result.Issues = append(result.Issues, responses.BOQExportIssueResponse{
+				Code:       check.code,
+				Severity:   check.severity,
+				Message:    check.message(line),
+				JobID:      &jobID,
+				MaterialID: line.MaterialID,
+			})
+		}
+	}
+
+	for _, issue := range result.Issues {
+		if issue.Severity == exportIssueError {
+			result.ErrorCount++
+		} else {
+			result.WarningCount++
+		}
+	}
+	result.Exportable = result.ErrorCount == 0
+
+	return result, nil
+}
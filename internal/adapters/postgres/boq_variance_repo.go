@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// GetEstimateVsActual compares the BOQ estimate against the actual costs
+// recorded after completion. Job and material actuals are compared
+// separately so each aggregate covers only the lines it was given.
+func (r *boqRepository) GetEstimateVsActual(ctx context.Context, boqID uuid.UUID, req requests.EstimateVsActualRequest) (*responses.EstimateVsActualResponse, error) {
+	var result *responses.EstimateVsActualResponse
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		result, err = r.getEstimateVsActual(ctx, tx, boqID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *boqRepository) getEstimateVsActual(ctx context.Context, q sqlx.QueryerContext, boqID uuid.UUID, req requests.EstimateVsActualRequest) (*responses.EstimateVsActualResponse, error) {
+	if _, err := getBOQByID(ctx, q, boqID); err != nil {
+		return nil, err
+	}
+
+	costs, err := r.getBOQJobCosts(ctx, q, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	jobEstimates := make(map[string]estimateLine, len(costs))
+	for _, cost := range costs {
+		jobEstimates[cost.JobID.String()] = estimateLine{Name: cost.Name, Amount: cost.Total()}
+	}
+
+	jobActuals := make(map[string]models.Money, len(req.Jobs))
+	for jobID, amount := range req.Jobs {
+		jobActuals[jobID.String()] = models.MoneyFromFloat(amount)
+	}
+
+	materialActuals := make(map[string]models.Money, len(req.Materials))
+	for materialID, amount := range req.Materials {
+		materialActuals[materialID] = models.MoneyFromFloat(amount)
+	}
+
+	type MaterialEstimate struct {
+		MaterialID string       `db:"material_id"`
+		Name       string       `db:"name"`
+		Amount     models.Money `db:"amount"`
+	}
+
+	query := `
+        SELECT
+            m.material_id,
+            m.name,
+            COALESCE(SUM(COALESCE(mpl.estimated_price, 0) * COALESCE(mpl.fx_rate, 1) * COALESCE(mpl.quantity, 0) * COALESCE(bj.quantity, 0)), 0) as amount
+        FROM material_price_log mpl
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL
+        JOIN material m ON m.material_id = mpl.material_id
+        WHERE mpl.boq_id = $1
+        GROUP BY m.material_id, m.name`
+
+	var materials []MaterialEstimate
+	err = sqlx.SelectContext(ctx, q, &materials, query, boqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get material estimates: %w", err)
+	}
+
+	materialEstimates := make(map[string]estimateLine, len(materials))
+	for _, material := range materials {
+		materialEstimates[material.MaterialID] = estimateLine{Name: material.Name, Amount: material.Amount}
+	}
+
+	result := &responses.EstimateVsActualResponse{BOQID: boqID}
+	if len(req.Jobs) > 0 {
+		result.Jobs = compareEstimates(jobEstimates, jobActuals)
+	}
+	if len(req.Materials) > 0 {
+		result.Materials = compareEstimates(materialEstimates, materialActuals)
+	}
+
+	return result, nil
+}
+
+type estimateLine struct {
+	Name   string
+	Amount models.Money
+}
+
+func compareEstimates(estimates map[string]estimateLine, actuals map[string]models.Money) *responses.EstimateVarianceResponse {
+	comparison := &responses.EstimateVarianceResponse{
+		Lines: []responses.EstimateVarianceLineResponse{},
+	}
+
+	ids := make(map[string]struct{}, len(estimates)+len(actuals))
+	for id := range estimates {
+		ids[id] = struct{}{}
+	}
+	for id := range actuals {
+		ids[id] = struct{}{}
+	}
+
+	for id := range ids {
+		estimate, inEstimate := estimates[id]
+		actual, inActuals := actuals[id]
+
+		line := responses.EstimateVarianceLineResponse{
+			ID:         id,
+			Name:       estimate.Name,
+			Estimated:  estimate.Amount,
+			Actual:     actual,
+			InEstimate: inEstimate,
+			InActuals:  inActuals,
+		}
+		line.Variance = line.Actual - line.Estimated
+		line.VariancePercent = percentOf(line.Variance, line.Estimated)
+		line.AccuracyPercent = estimateAccuracy(line.Estimated, line.Actual)
+		comparison.Lines = append(comparison.Lines, line)
+
+		if !inEstimate || !inActuals {
+			comparison.UnmatchedLines++
+			continue
+		}
+		comparison.EstimatedTotal += line.Estimated
+		comparison.ActualTotal += line.Actual
+	}
+
+	sort.Slice(comparison.Lines, func(i, j int) bool {
+		a, b := comparison.Lines[i], comparison.Lines[j]
+		if absMoney(a.Variance) != absMoney(b.Variance) {
+			return absMoney(a.Variance) > absMoney(b.Variance)
+		}
+		return a.ID < b.ID
+	})
+
+	comparison.Variance = comparison.ActualTotal - comparison.EstimatedTotal
+	comparison.VariancePercent = percentOf(comparison.Variance, comparison.EstimatedTotal)
+	comparison.AccuracyPercent = estimateAccuracy(comparison.EstimatedTotal, comparison.ActualTotal)
+
+	return comparison
+}
+
+// estimateAccuracy scores how close the estimate was to actual, from 100 for
+// an exact match down to 0 when the error is as large as the estimate.
+func estimateAccuracy(estimated, actual models.Money) float64 {
+	if estimated == 0 {
+		if actual == 0 {
+			return 100
+		}
+		return 0
+	}
+	return math.Max(0, 100-float64(absMoney(actual-estimated))/float64(estimated)*100)
+}
+
+func absMoney(m models.Money) models.Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
@@ -116,14 +116,10 @@ func (r *invoiceRepository) CreateForAllPeriods(ctx context.Context, projectID u
 		return errors.New("no available periods found for invoicing in this contract")
 	}
 
-	// Begin a transaction for creating multiple invoices
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	// Insert invoice for each period
-	insertQuery := `
+	// Create all invoices in one transaction
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Insert invoice for each period
+		insertQuery := `
         INSERT INTO invoice (
             invoice_id,
             project_id,
@@ -141,23 +137,18 @@ func (r *invoiceRepository) CreateForAllPeriods(ctx context.Context, projectID u
             CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
         )`
 
-	for _, period := range periods {
-		invoiceID := uuid.New()
-		_, err = tx.ExecContext(ctx, insertQuery,
-			invoiceID, projectID, period.PeriodID, period.PayWithin, paymentTerm)
+		for _, period := range periods {
+			invoiceID := uuid.New()
+			_, err := tx.ExecContext(ctx, insertQuery,
+				invoiceID, projectID, period.PeriodID, period.PayWithin, paymentTerm)
 
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to create invoice for period %d: %w", period.PeriodNumber, err)
+			if err != nil {
+				return fmt.Errorf("failed to create invoice for period %d: %w", period.PeriodNumber, err)
+			}
 		}
-	}
 
-	// Commit the transaction
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
+		return nil
+	})
 }
 
 func (r *invoiceRepository) GetByID(ctx context.Context, invoiceID uuid.UUID) (*models.Invoice, error) {
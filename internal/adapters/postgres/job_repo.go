@@ -51,21 +51,18 @@ func (r *jobRepository) GetJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]mo
 }
 
 func (r *jobRepository) GetJobMaterialByID(ctx context.Context, id uuid.UUID) (responses.JobMaterialResponse, error) {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return responses.JobMaterialResponse{}, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-	type JobQuery struct {
-		JobID       uuid.UUID      `db:"job_id"`
-		Name        string         `db:"name"`
-		Description sql.NullString `db:"description"`
-		Unit        string         `db:"unit"`
-	}
+	var result responses.JobMaterialResponse
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		type JobQuery struct {
+			JobID       uuid.UUID      `db:"job_id"`
+			Name        string         `db:"name"`
+			Description sql.NullString `db:"description"`
+			Unit        string         `db:"unit"`
+		}
 
-	// Get job details
-	var jobQuery JobQuery
-	jobQueryString := `
+		// Get job details
+		var jobQuery JobQuery
+		jobQueryString := `
         SELECT 
             j.job_id,
             j.name,
@@ -74,16 +71,16 @@ func (r *jobRepository) GetJobMaterialByID(ctx context.Context, id uuid.UUID) (r
         FROM Job j
         WHERE j.job_id = $1`
 
-	err = tx.GetContext(ctx, &jobQuery, jobQueryString, id)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return responses.JobMaterialResponse{}, errors.New("job not found")
+		err := tx.GetContext(ctx, &jobQuery, jobQueryString, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.New("job not found")
+			}
+			return fmt.Errorf("failed to get job: %w", err)
 		}
-		return responses.JobMaterialResponse{}, fmt.Errorf("failed to get job: %w", err)
-	}
 
-	// Get materials for the job
-	materialsQuery := `
+		// Get materials for the job
+		materialsQuery := `
         SELECT 
             m.material_id,
             m.name,
@@ -93,36 +90,38 @@ func (r *jobRepository) GetJobMaterialByID(ctx context.Context, id uuid.UUID) (r
         JOIN Job_material jm ON m.material_id = jm.material_id
         WHERE jm.job_id = $1`
 
-	var materials []responses.JobMaterialItem
-	err = tx.SelectContext(ctx, &materials, materialsQuery, id)
-	if err != nil {
-		return responses.JobMaterialResponse{}, fmt.Errorf("failed to get job materials: %w", err)
-	}
+		var materials []responses.JobMaterialItem
+		err = tx.SelectContext(ctx, &materials, materialsQuery, id)
+		if err != nil {
+			return fmt.Errorf("failed to get job materials: %w", err)
+		}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return responses.JobMaterialResponse{}, fmt.Errorf("failed to commit transaction: %w", err)
-	}
+		materialsForResponse := []responses.JobMaterialItem{}
+		for _, material := range materials {
+			materialsForResponse = append(materialsForResponse, responses.JobMaterialItem{
+				MaterialID: material.MaterialID,
+				Name:       material.Name,
+				Unit:       material.Unit,
+				Quantity:   material.Quantity,
+			})
+		}
 
-	var materialsForResponse []responses.JobMaterialItem
-	for _, material := range materials {
-		materialsForResponse = append(materialsForResponse, responses.JobMaterialItem{
-			MaterialID: material.MaterialID,
-			Name:       material.Name,
-			Unit:       material.Unit,
-			Quantity:   material.Quantity,
-		})
-	}
+		job := responses.JobMaterialResponse{
+			JobID:       jobQuery.JobID,
+			Name:        jobQuery.Name,
+			Description: jobQuery.Description.String,
+			Unit:        jobQuery.Unit,
+			Materials:   materialsForResponse,
+		}
 
-	job := responses.JobMaterialResponse{
-		JobID:       jobQuery.JobID,
-		Name:        jobQuery.Name,
-		Description: jobQuery.Description.String,
-		Unit:        jobQuery.Unit,
-		Materials:   materialsForResponse,
+		result = job
+		return nil
+	})
+	if err != nil {
+		return responses.JobMaterialResponse{}, err
 	}
 
-	return job, nil
+	return result, nil
 }
 
 // List retrieves all jobs
@@ -134,7 +133,7 @@ func (r *jobRepository) List(ctx context.Context) (*responses.JobListResponse, e
 		return nil, fmt.Errorf("failed to list jobs: %w", err)
 	}
 
-	var jobList []responses.JobResponse
+	jobList := []responses.JobResponse{}
 	for _, job := range jobs {
 		jobList = append(jobList, responses.JobResponse{
 			JobID:       job.JobID,
@@ -245,58 +244,45 @@ func (r *jobRepository) Delete(ctx context.Context, jobID uuid.UUID) error {
 	}
 
 	// 7. If job is not used, proceed with deletion
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Delete job materials first due to foreign key constraint
-	deleteMaterialsQuery := `DELETE FROM Job_material WHERE job_id = $1`
-	_, err = tx.ExecContext(ctx, deleteMaterialsQuery, jobID)
-	if err != nil {
-		return fmt.Errorf("failed to delete job materials: %w", err)
-	}
-	// Delete material price logs
-	deletePriceLogsQuery := `
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Delete job materials first due to foreign key constraint
+		deleteMaterialsQuery := `DELETE FROM Job_material WHERE job_id = $1`
+		_, err := tx.ExecContext(ctx, deleteMaterialsQuery, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to delete job materials: %w", err)
+		}
+		// Delete material price logs
+		deletePriceLogsQuery := `
 		DELETE FROM Material_price_log
 		WHERE job_id = $1`
-	_, err = tx.ExecContext(ctx, deletePriceLogsQuery, jobID)
-	if err != nil {
-		return fmt.Errorf("failed to delete material price logs: %w", err)
-	}
-
-	// Delete the job
-	deleteJobQuery := `DELETE FROM Job WHERE job_id = $1`
-	result, err := tx.ExecContext(ctx, deleteJobQuery, jobID)
-	if err != nil {
-		return fmt.Errorf("failed to delete job: %w", err)
-	}
+		_, err = tx.ExecContext(ctx, deletePriceLogsQuery, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to delete material price logs: %w", err)
+		}
 
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
+		// Delete the job
+		deleteJobQuery := `DELETE FROM Job WHERE job_id = $1`
+		result, err := tx.ExecContext(ctx, deleteJobQuery, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to delete job: %w", err)
+		}
 
-	if affected == 0 {
-		return fmt.Errorf("job not found")
-	}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
+		if affected == 0 {
+			return fmt.Errorf("job not found")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (r *jobRepository) AddJobMaterial(ctx context.Context, jobID uuid.UUID, req requests.AddJobMaterialRequest) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	insertJobMaterialQuery := `
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		insertJobMaterialQuery := `
 		INSERT INTO Job_material (
 			job_id, material_id, quantity
 		) VALUES (
@@ -304,91 +290,83 @@ func (r *jobRepository) AddJobMaterial(ctx context.Context, jobID uuid.UUID, req
 		) ON CONFLICT (job_id, material_id) 
 		DO UPDATE SET quantity = Job_material.quantity + EXCLUDED.quantity`
 
-	getProjectsQuery := `
+		getProjectsQuery := `
 		SELECT DISTINCT b.boq_id, b.status
 		FROM boq_job bj 
 		JOIN boq b ON b.boq_id = bj.boq_id 
 		JOIN project p ON p.project_id = b.project_id 
 		WHERE bj.job_id = $1`
 
-	type BOQInfo struct {
-		BOQID  uuid.UUID `db:"boq_id"`
-		Status string    `db:"status"`
-	}
-	var boqs []BOQInfo
-
-	err = tx.SelectContext(ctx, &boqs, getProjectsQuery, jobID)
-	if err != nil {
-		return fmt.Errorf("failed to get associated projects: %w", err)
-	}
+		type BOQInfo struct {
+			BOQID  uuid.UUID `db:"boq_id"`
+			Status string    `db:"status"`
+		}
+		var boqs []BOQInfo
 
-	// Insert job materials
-	for _, material := range req.Materials {
-		params := map[string]interface{}{
-			"job_id":      jobID,
-			"material_id": material.MaterialID,
-			"quantity":    material.Quantity,
+		err := tx.SelectContext(ctx, &boqs, getProjectsQuery, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to get associated projects: %w", err)
 		}
 
-		_, err := tx.NamedExecContext(ctx, insertJobMaterialQuery, params)
-		fmt.Print(err)
+		// Insert job materials
+		for _, material := range req.Materials {
+			params := map[string]interface{}{
+				"job_id":      jobID,
+				"material_id": material.MaterialID,
+				"quantity":    material.Quantity,
+			}
+
+			_, err := tx.NamedExecContext(ctx, insertJobMaterialQuery, params)
+			fmt.Print(err)
 
-		if err != nil {
+			if err != nil {
 
-			return fmt.Errorf("failed to add material: %w", err)
-		}
+				return fmt.Errorf("failed to add material: %w", err)
+			}
 
-		// 14.4 For each draft BOQ, create material price log entries
-		for _, boq := range boqs {
-			if boq.Status == "draft" {
-				insertPriceLogQuery := `
+			// 14.4 For each draft BOQ, create material price log entries
+			for _, boq := range boqs {
+				if boq.Status == "draft" {
+					insertPriceLogQuery := `
 					INSERT INTO Material_price_log (
 						material_id, boq_id, supplier_id, actual_price, estimated_price, job_id, quantity, updated_at
 					) VALUES (
 						$1, $2, NULL, NULL, NULL, $3, $4, CURRENT_TIMESTAMP
 					)`
 
-				_, err = tx.ExecContext(ctx, insertPriceLogQuery, material.MaterialID, boq.BOQID, jobID, material.Quantity)
-				if err != nil {
-					return fmt.Errorf("failed to create material price log: %w", err)
+					_, err = tx.ExecContext(ctx, insertPriceLogQuery, material.MaterialID, boq.BOQID, jobID, material.Quantity)
+					if err != nil {
+						return fmt.Errorf("failed to create material price log: %w", err)
+					}
 				}
 			}
 		}
-	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
+		return nil
+	})
 }
 
 func (r *jobRepository) DeleteJobMaterial(ctx context.Context, jobID uuid.UUID, materialID string) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	deleteJobMaterialQuery := `
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		deleteJobMaterialQuery := `
 		DELETE FROM Job_material 
 		WHERE job_id = $1 AND material_id = $2`
 
-	result, err := tx.ExecContext(ctx, deleteJobMaterialQuery, jobID, materialID)
-	if err != nil {
-		return fmt.Errorf("failed to delete job material: %w", err)
-	}
+		result, err := tx.ExecContext(ctx, deleteJobMaterialQuery, jobID, materialID)
+		if err != nil {
+			return fmt.Errorf("failed to delete job material: %w", err)
+		}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
 
-	if rows == 0 {
-		return errors.New("job material not found")
-	}
+		if rows == 0 {
+			return errors.New("job material not found")
+		}
 
-	deletePriceLogsQuery := `
+		deletePriceLogsQuery := `
 		DELETE FROM material_price_log 
 		WHERE job_id = $1 
 		AND material_id = $2 
@@ -400,16 +378,13 @@ func (r *jobRepository) DeleteJobMaterial(ctx context.Context, jobID uuid.UUID,
 			AND b.status = 'draft'
 		)`
 
-	_, err = tx.ExecContext(ctx, deletePriceLogsQuery, jobID, materialID)
-	if err != nil {
-		return fmt.Errorf("failed to delete material price logs: %w", err)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
+		_, err = tx.ExecContext(ctx, deletePriceLogsQuery, jobID, materialID)
+		if err != nil {
+			return fmt.Errorf("failed to delete material price logs: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (r *jobRepository) UpdateJobMaterialQuantity(ctx context.Context, jobID uuid.UUID, req requests.UpdateJobMaterialQuantityRequest) error {
@@ -471,12 +446,12 @@ func (r *jobRepository) GetJobByProjectID(ctx context.Context, projectID uuid.UU
 	query := `
 		SELECT DISTINCT j.job_id, j.name, j.description, j.unit, bj.quantity, bj.labor_cost
 		FROM job j
-		INNER JOIN boq_job bj ON j.job_id = bj.job_id
+		INNER JOIN boq_job bj ON j.job_id = bj.job_id AND bj.deleted_at IS NULL
 		INNER JOIN boq b ON bj.boq_id = b.boq_id
 		WHERE b.project_id = $1
 	`
 
-	var jobs []responses.JobResponse
+	jobs := []responses.JobResponse{}
 	err := r.db.SelectContext(ctx, &jobs, query, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get jobs by project ID: %w", err)
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -88,21 +89,15 @@ func (r *materialRepository) Update(ctx context.Context, materialID string, req
 }
 
 func (r *materialRepository) Delete(ctx context.Context, materialID string) error {
-	// Start transaction
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Check material usage in projects
-	type ProjectUsage struct {
-		ProjectID   uuid.UUID `db:"project_id"`
-		ProjectName string    `db:"name"`
-		Status      string    `db:"status"`
-	}
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Check material usage in projects
+		type ProjectUsage struct {
+			ProjectID   uuid.UUID `db:"project_id"`
+			ProjectName string    `db:"name"`
+			Status      string    `db:"status"`
+		}
 
-	checkUsageQuery := `
+		checkUsageQuery := `
        SELECT DISTINCT 
            p.project_id,
            p.name,
@@ -113,66 +108,62 @@ func (r *materialRepository) Delete(ctx context.Context, materialID string) erro
        JOIN project p ON p.project_id = b.project_id 
        WHERE jm.material_id = $1`
 
-	var usages []ProjectUsage
-	err = tx.SelectContext(ctx, &usages, checkUsageQuery, materialID)
-	if err != nil {
-		return fmt.Errorf("failed to check material usage: %w", err)
-	}
+		var usages []ProjectUsage
+		err := tx.SelectContext(ctx, &usages, checkUsageQuery, materialID)
+		if err != nil {
+			return fmt.Errorf("failed to check material usage: %w", err)
+		}
 
-	// If material is being used, return error with project names
-	if len(usages) > 0 {
-		var projectNames []string
-		for _, usage := range usages {
-			projectNames = append(projectNames, usage.ProjectName)
+		// If material is being used, return error with project names
+		if len(usages) > 0 {
+			var projectNames []string
+			for _, usage := range usages {
+				projectNames = append(projectNames, usage.ProjectName)
+			}
+			return fmt.Errorf("material is used in following projects: %s", strings.Join(projectNames, ", "))
 		}
-		return fmt.Errorf("material is used in following projects: %s", strings.Join(projectNames, ", "))
-	}
 
-	// Delete from material_price_log first
-	deletePriceLogQuery := `
+		// Delete from material_price_log first
+		deletePriceLogQuery := `
        DELETE FROM material_price_log 
        WHERE material_id = $1`
 
-	_, err = tx.ExecContext(ctx, deletePriceLogQuery, materialID)
-	if err != nil {
-		return fmt.Errorf("failed to delete material price logs: %w", err)
-	}
+		_, err = tx.ExecContext(ctx, deletePriceLogQuery, materialID)
+		if err != nil {
+			return fmt.Errorf("failed to delete material price logs: %w", err)
+		}
 
-	// Delete from job_material
-	deleteJobMaterialQuery := `
+		// Delete from job_material
+		deleteJobMaterialQuery := `
        DELETE FROM job_material 
        WHERE material_id = $1`
 
-	_, err = tx.ExecContext(ctx, deleteJobMaterialQuery, materialID)
-	if err != nil {
-		return fmt.Errorf("failed to delete job materials: %w", err)
-	}
+		_, err = tx.ExecContext(ctx, deleteJobMaterialQuery, materialID)
+		if err != nil {
+			return fmt.Errorf("failed to delete job materials: %w", err)
+		}
 
-	// Finally delete the material
-	deleteMaterialQuery := `
+		// Finally delete the material
+		deleteMaterialQuery := `
        DELETE FROM Material 
        WHERE material_id = $1`
 
-	result, err := tx.ExecContext(ctx, deleteMaterialQuery, materialID)
-	if err != nil {
-		return fmt.Errorf("failed to delete material: %w", err)
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
+		result, err := tx.ExecContext(ctx, deleteMaterialQuery, materialID)
+		if err != nil {
+			return fmt.Errorf("failed to delete material: %w", err)
+		}
 
-	if rows == 0 {
-		return errors.New("material not found")
-	}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
+		if rows == 0 {
+			return errors.New("material not found")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (r *materialRepository) GetByID(ctx context.Context, materialID string) (*models.Material, error) {
@@ -249,7 +240,7 @@ func (r *materialRepository) GetMaterialPricesByProjectID(ctx context.Context, p
         JOIN job j ON j.job_id = mpl.job_id 
         JOIN material m ON m.material_id = mpl.material_id 
         JOIN FinalAvg fa ON fa.material_id = m.material_id
-        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id 
+        JOIN boq_job bj ON bj.boq_id = mpl.boq_id AND bj.job_id = mpl.job_id AND bj.deleted_at IS NULL 
         LEFT JOIN supplier s ON s.supplier_id = mpl.supplier_id
         WHERE p.project_id = $1
         GROUP BY 
@@ -397,3 +388,44 @@ func (r *materialRepository) GetQuotationStatus(ctx context.Context, BOQId uuid.
 
 	return status, nil
 }
+
+// GetMaterialVolatility measures how much each material's logged price moved
+// between from and to, across every BOQ. Prices are converted to the BOQ
+// currency and the actual price is preferred over the estimate. Materials
+// with fewer than two observations are skipped.
+func (r *materialRepository) GetMaterialVolatility(ctx context.Context, from, to time.Time, limit int) ([]models.MaterialVolatility, error) {
+	query := `
+        WITH prices AS (
+            SELECT
+                mpl.material_id,
+                mpl.boq_id,
+                COALESCE(mpl.actual_price, mpl.estimated_price) * COALESCE(mpl.fx_rate, 1) as price
+            FROM material_price_log mpl
+            WHERE mpl.updated_at >= $1 AND mpl.updated_at < $2
+            AND COALESCE(mpl.actual_price, mpl.estimated_price) IS NOT NULL
+        )
+        SELECT
+            m.material_id,
+            m.name,
+            m.unit,
+            COUNT(*) as observations,
+            COUNT(DISTINCT p.boq_id) as boq_count,
+            MIN(p.price) as min_price,
+            MAX(p.price) as max_price,
+            AVG(p.price) as avg_price,
+            COALESCE(STDDEV_POP(p.price), 0) as std_dev
+        FROM prices p
+        JOIN material m ON m.material_id = p.material_id
+        GROUP BY m.material_id, m.name, m.unit
+        HAVING COUNT(*) > 1 AND AVG(p.price) > 0
+        ORDER BY STDDEV_POP(p.price) / AVG(p.price) DESC, m.material_id
+        LIMIT $3`
+
+	var materials []models.MaterialVolatility
+	err := r.db.SelectContext(ctx, &materials, query, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get material volatility: %w", err)
+	}
+
+	return materials, nil
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"boonkosang/internal/domain/models"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// recordProjectBenchmark stores the key ratios of the project's BOQ in the
+// benchmark library, replacing any earlier record for the project. Projects
+// without a costed BOQ are skipped.
+func recordProjectBenchmark(ctx context.Context, tx *sqlx.Tx, projectID uuid.UUID) error {
+	type BOQCosts struct {
+		BOQID        uuid.UUID       `db:"boq_id"`
+		ProjectType  sql.NullString  `db:"project_type"`
+		GFA          sql.NullFloat64 `db:"gross_floor_area"`
+		LaborCost    float64         `db:"labor_cost"`
+		MaterialCost float64         `db:"material_cost"`
+		OverheadCost float64         `db:"overhead_cost"`
+		JobCount     int             `db:"job_count"`
+	}
+
+	query := `
+        WITH material_totals AS (
+            SELECT boq_id, job_id,
+                SUM(COALESCE(estimated_price, 0) * COALESCE(fx_rate, 1) * COALESCE(quantity, 0)) as unit_material_cost
+            FROM material_price_log
+            GROUP BY boq_id, job_id
+        )
+        SELECT
+            b.boq_id,
+            p.project_type,
+            p.gross_floor_area,
+            COALESCE((
+                SELECT SUM(COALESCE(bj.labor_cost, 0) * COALESCE(bj.quantity, 0))
+                FROM boq_job bj WHERE bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
+            ), 0) as labor_cost,
+            COALESCE((
+                SELECT SUM(COALESCE(mt.unit_material_cost, 0) * COALESCE(bj.quantity, 0))
+                FROM boq_job bj
+                LEFT JOIN material_totals mt ON mt.boq_id = bj.boq_id AND mt.job_id = bj.job_id
+                WHERE bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
+            ), 0) as material_cost,
+            COALESCE((
+                SELECT SUM(COALESCE(gc.estimated_cost, 0))
+                FROM general_cost gc WHERE gc.boq_id = b.boq_id
+            ), 0) as overhead_cost,
+            (SELECT COUNT(*) FROM boq_job bj WHERE bj.boq_id = b.boq_id AND bj.deleted_at IS NULL) as job_count
+        FROM boq b
+        JOIN project p ON p.project_id = b.project_id
+        WHERE b.project_id = $1`
+
+	var costs BOQCosts
+	err := tx.GetContext(ctx, &costs, query, projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to get BOQ costs for benchmark: %w", err)
+	}
+
+	total := costs.LaborCost + costs.MaterialCost + costs.OverheadCost
+	if total <= 0 {
+		return nil
+	}
+
+	benchmark := models.ProjectBenchmark{
+		ProjectID:     projectID,
+		BOQID:         costs.BOQID,
+		ProjectType:   costs.ProjectType,
+		GFA:           costs.GFA,
+		TotalCost:     total,
+		LaborShare:    costs.LaborCost / total,
+		MaterialShare: costs.MaterialCost / total,
+		OverheadShare: costs.OverheadCost / total,
+		JobCount:      costs.JobCount,
+	}
+	if costs.GFA.Valid && costs.GFA.Float64 > 0 {
+		benchmark.CostPerArea = sql.NullFloat64{Float64: total / costs.GFA.Float64, Valid: true}
+	}
+
+	insertQuery := `
+        INSERT INTO project_benchmark (
+            project_id, boq_id, project_type, gross_floor_area, total_cost, cost_per_area,
+            labor_share, material_share, overhead_share, job_count, recorded_at
+        ) VALUES (
+            :project_id, :boq_id, :project_type, :gross_floor_area, :total_cost, :cost_per_area,
+            :labor_share, :material_share, :overhead_share, :job_count, CURRENT_TIMESTAMP
+        )
+        ON CONFLICT (project_id) DO UPDATE SET
+            boq_id = EXCLUDED.boq_id,
+            project_type = EXCLUDED.project_type,
+            gross_floor_area = EXCLUDED.gross_floor_area,
+            total_cost = EXCLUDED.total_cost,
+            cost_per_area = EXCLUDED.cost_per_area,
+            labor_share = EXCLUDED.labor_share,
+            material_share = EXCLUDED.material_share,
+            overhead_share = EXCLUDED.overhead_share,
+            job_count = EXCLUDED.job_count,
+            recorded_at = EXCLUDED.recorded_at`
+
+	_, err = tx.NamedExecContext(ctx, insertQuery, benchmark)
+	if err != nil {
+		return fmt.Errorf("failed to record project benchmark: %w", err)
+	}
+
+	return nil
+}
+
+func (r *projectRepository) GetBenchmarks(ctx context.Context, projectType string) ([]models.ProjectBenchmark, error) {
+	query := `
+        SELECT * FROM project_benchmark
+        WHERE project_type = $1
+        ORDER BY recorded_at DESC`
+
+	var benchmarks []models.ProjectBenchmark
+	err := r.db.SelectContext(ctx, &benchmarks, query, projectType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project benchmarks: %w", err)
+	}
+
+	return benchmarks, nil
+}
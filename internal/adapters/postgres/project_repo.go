@@ -33,15 +33,19 @@ func (r *projectRepository) Create(ctx context.Context, req requests.CreateProje
 		Status:      models.ProjectStatusPlanning,
 		ClientID:    req.ClientID,
 		CreatedAt:   time.Now(),
+		ProjectType: sql.NullString{String: req.ProjectType, Valid: req.ProjectType != ""},
+	}
+	if req.GFA != nil {
+		project.GFA = sql.NullFloat64{Float64: *req.GFA, Valid: true}
 	}
 
 	query := `
         INSERT INTO Project (
             project_id, name, description, address, status, 
-            client_id, created_at
+            client_id, created_at, project_type, gross_floor_area
         ) VALUES (
             :project_id, :name, :description, :address, :status,
-            :client_id, :created_at
+            :client_id, :created_at, :project_type, :gross_floor_area
         ) RETURNING *`
 
 	rows, err := r.db.NamedQueryContext(ctx, query, project)
@@ -67,16 +71,25 @@ func (r *projectRepository) Update(ctx context.Context, id uuid.UUID, req reques
             description = :description,
             address = :address,
 			client_id = :client_id,
+            project_type = :project_type,
+            gross_floor_area = :gross_floor_area,
             updated_at = :updated_at
         WHERE project_id = :project_id`
 
+	gfa := sql.NullFloat64{}
+	if req.GFA != nil {
+		gfa = sql.NullFloat64{Float64: *req.GFA, Valid: true}
+	}
+
 	params := map[string]interface{}{
-		"project_id":  id,
-		"name":        req.Name,
-		"description": req.Description,
-		"address":     req.Address,
-		"client_id":   req.ClientID,
-		"updated_at":  time.Now(),
+		"project_id":       id,
+		"name":             req.Name,
+		"description":      req.Description,
+		"address":          req.Address,
+		"client_id":        req.ClientID,
+		"project_type":     sql.NullString{String: req.ProjectType, Valid: req.ProjectType != ""},
+		"gross_floor_area": gfa,
+		"updated_at":       time.Now(),
 	}
 
 	result, err := r.db.NamedExecContext(ctx, query, params)
@@ -133,7 +146,8 @@ func (r *projectRepository) GetByIDWithClient(ctx context.Context, id uuid.UUID)
 
 	query := `
         SELECT 
-            p.*,
+            p.project_id, p.name, p.description, p.address, p.status,
+            p.client_id, p.created_at, p.updated_at, p.project_type, p.gross_floor_area,
             c.client_id as "client.client_id",
             c.name as "client.name",
             c.email as "client.email",
@@ -147,7 +161,7 @@ func (r *projectRepository) GetByIDWithClient(ctx context.Context, id uuid.UUID)
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&project.ProjectID, &project.Name, &project.Description,
 		&project.Address, &project.Status, &project.ClientID,
-		&project.CreatedAt, &project.UpdatedAt,
+		&project.CreatedAt, &project.UpdatedAt, &project.ProjectType, &project.GFA,
 		&client.ClientID, &client.Name, &client.Email,
 		&client.Tel, &client.Address, &client.TaxID,
 	)
@@ -257,26 +271,35 @@ func (r *projectRepository) UpdateStatus(ctx context.Context, projectID uuid.UUI
 		return err
 	}
 
-	query := `
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
         UPDATE project 
         SET status = $1, updated_at = CURRENT_TIMESTAMP 
         WHERE project_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, status, projectID)
-	if err != nil {
-		return fmt.Errorf("failed to update project status: %w", err)
-	}
+		result, err := tx.ExecContext(ctx, query, status, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to update project status: %w", err)
+		}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
 
-	if rows == 0 {
-		return errors.New("project not found")
-	}
+		if rows == 0 {
+			return errors.New("project not found")
+		}
 
-	return nil
+		// Completed projects feed the estimating benchmark library
+		if status == models.ProjectStatusCompleted {
+			if err := recordProjectBenchmark(ctx, tx, projectID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func (r *projectRepository) ValidateProjectData(ctx context.Context, projectID uuid.UUID) error {
@@ -328,7 +351,7 @@ func (r *projectRepository) GetProjectOverview(ctx context.Context, projectID uu
                 b.boq_id, 
                 SUM(bj.selling_price*bj.quantity) as total_selling_price_exclude_gc_cost 
             FROM boq b 
-            LEFT JOIN boq_job bj ON bj.boq_id = b.boq_id 
+            LEFT JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
             WHERE b.project_id = $1 
             GROUP BY b.boq_id
         ), ActualPriceTotal AS (
@@ -349,7 +372,7 @@ func (r *projectRepository) GetProjectOverview(ctx context.Context, projectID uu
         FROM project p 
         LEFT JOIN quotation q ON q.project_id = p.project_id 
         LEFT JOIN boq b ON b.project_id = p.project_id 
-        LEFT JOIN boq_job bj ON bj.boq_id = b.boq_id 
+        LEFT JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
         LEFT JOIN MaterialTotals mt ON mt.job_id = bj.job_id AND mt.boq_id = bj.boq_id 
         LEFT JOIN GeneralCost gc ON gc.boq_id = b.boq_id 
         LEFT JOIN JobTotals jt ON jt.boq_id = b.boq_id 
@@ -437,7 +460,7 @@ func (r *projectRepository) getJobDetails(ctx context.Context, projectID uuid.UU
         FROM project p 
         LEFT JOIN quotation q ON q.project_id = p.project_id 
         JOIN boq b ON b.project_id = p.project_id 
-        JOIN boq_job bj ON bj.boq_id = b.boq_id 
+        JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
         JOIN job j ON j.job_id = bj.job_id 
         LEFT JOIN MaterialTotals mt ON mt.job_id = j.job_id AND mt.boq_id = b.boq_id 
         WHERE p.project_id = $1
@@ -57,21 +57,17 @@ func (r *quotationRepository) GetByProjectID(ctx context.Context, projectID uuid
 }
 
 func (r *quotationRepository) Create(ctx context.Context, projectID uuid.UUID) (*models.Quotation, error) {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	quotation := &models.Quotation{
-		QuotationID:   uuid.New(),
-		ProjectID:     projectID,
-		Status:        "draft",
-		ValidDate:     sql.NullTime{Time: time.Now().AddDate(0, 1, 0), Valid: true}, // Default validity: 1 month
-		TaxPercentage: sql.NullFloat64{Float64: 7, Valid: true},                     // Default tax percentage
-	}
+	var result *models.Quotation
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		quotation := &models.Quotation{
+			QuotationID:   uuid.New(),
+			ProjectID:     projectID,
+			Status:        "draft",
+			ValidDate:     sql.NullTime{Time: time.Now().AddDate(0, 1, 0), Valid: true}, // Default validity: 1 month
+			TaxPercentage: sql.NullFloat64{Float64: 7, Valid: true},                     // Default tax percentage
+		}
 
-	query := `
+		query := `
         INSERT INTO quotation (
             quotation_id, project_id, status, valid_date, 
             final_amount, tax_percentage
@@ -80,21 +76,24 @@ func (r *quotationRepository) Create(ctx context.Context, projectID uuid.UUID) (
             :final_amount, :tax_percentage
         ) RETURNING *`
 
-	_, err = tx.NamedExecContext(ctx, query, quotation)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create quotation: %w", err)
-	}
+		_, err := tx.NamedExecContext(ctx, query, quotation)
+		if err != nil {
+			return fmt.Errorf("failed to create quotation: %w", err)
+		}
 
-	err = tx.GetContext(ctx, quotation, "SELECT * FROM quotation WHERE quotation_id = $1", quotation.QuotationID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve created quotation: %w", err)
-	}
+		err = tx.GetContext(ctx, quotation, "SELECT * FROM quotation WHERE quotation_id = $1", quotation.QuotationID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve created quotation: %w", err)
+		}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		result = quotation
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return quotation, nil
+	return result, nil
 }
 func (r *quotationRepository) GetQuotationJobs(ctx context.Context, projectID uuid.UUID) ([]models.QuotationJob, error) {
 	query := `
@@ -125,7 +124,7 @@ SELECT
 FROM project p
 LEFT JOIN quotation q ON q.project_id = p.project_id
 JOIN boq b ON b.project_id = p.project_id
-JOIN boq_job bj ON bj.boq_id = b.boq_id
+JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
 JOIN job j ON j.job_id = bj.job_id
 LEFT JOIN MaterialTotals mt ON mt.job_id = j.job_id AND mt.boq_id = b.boq_id
 WHERE p.project_id = $1
@@ -172,69 +171,61 @@ func (r *quotationRepository) GetQuotationGeneralCosts(ctx context.Context, proj
 }
 
 func (r *quotationRepository) ValidateApproval(ctx context.Context, projectID uuid.UUID) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Check BOQ status
-	var boqStatus string
-	boqQuery := `SELECT status FROM boq WHERE project_id = $1`
-	err = tx.GetContext(ctx, &boqStatus, boqQuery, projectID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("BOQ not found")
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Check BOQ status
+		var boqStatus string
+		boqQuery := `SELECT status FROM boq WHERE project_id = $1`
+		err := tx.GetContext(ctx, &boqStatus, boqQuery, projectID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.New("BOQ not found")
+			}
+			return fmt.Errorf("failed to get BOQ status: %w", err)
 		}
-		return fmt.Errorf("failed to get BOQ status: %w", err)
-	}
 
-	if boqStatus != "approved" {
-		return errors.New("BOQ must be approved before approving quotation")
-	}
+		if boqStatus != "approved" {
+			return errors.New("BOQ must be approved before approving quotation")
+		}
 
-	// Check quotation status
-	var quotationStatus string
-	quotationQuery := `SELECT status FROM quotation WHERE project_id = $1`
-	err = tx.GetContext(ctx, &quotationStatus, quotationQuery, projectID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("quotation not found")
+		// Check quotation status
+		var quotationStatus string
+		quotationQuery := `SELECT status FROM quotation WHERE project_id = $1`
+		err = tx.GetContext(ctx, &quotationStatus, quotationQuery, projectID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.New("quotation not found")
+			}
+			return fmt.Errorf("failed to get quotation status: %w", err)
 		}
-		return fmt.Errorf("failed to get quotation status: %w", err)
-	}
 
-	if quotationStatus != "draft" {
-		return errors.New("only draft quotations can be approved")
-	}
+		if quotationStatus != "draft" {
+			return errors.New("only draft quotations can be approved")
+		}
 
-	return tx.Commit()
+		return nil
+	})
 }
 
 func (r *quotationRepository) ApproveQuotation(ctx context.Context, projectID uuid.UUID) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Update quotation status
-	query := `
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Update quotation status
+		query := `
         UPDATE quotation 
         SET status = 'approved'
         WHERE project_id = $1 AND status = 'draft'
         RETURNING quotation_id`
 
-	var quotationID uuid.UUID
-	err = tx.GetContext(ctx, &quotationID, query, projectID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("no draft quotation found to approve")
+		var quotationID uuid.UUID
+		err := tx.GetContext(ctx, &quotationID, query, projectID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.New("no draft quotation found to approve")
+			}
+			return fmt.Errorf("failed to approve quotation: %w", err)
 		}
-		return fmt.Errorf("failed to approve quotation: %w", err)
-	}
 
-	return tx.Commit()
+		return nil
+	})
 }
 
 func (r *quotationRepository) GetQuotationStatus(ctx context.Context, projectID uuid.UUID) (string, error) {
@@ -253,14 +244,10 @@ func (r *quotationRepository) GetQuotationStatus(ctx context.Context, projectID
 }
 
 func (r *quotationRepository) GetExportData(ctx context.Context, projectID uuid.UUID) (*responses.QuotationExportData, error) {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Get main quotation data
-	query := `
+	var result *responses.QuotationExportData
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Get main quotation data
+		query := `
         SELECT 
             p.project_id,
             p.name,
@@ -283,17 +270,17 @@ func (r *quotationRepository) GetExportData(ctx context.Context, projectID uuid.
             AND q.status = 'approved'
         LIMIT 1`
 
-	var data responses.QuotationExportData
-	err = tx.GetContext(ctx, &data, query, projectID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("approved quotation not found")
+		var data responses.QuotationExportData
+		err := tx.GetContext(ctx, &data, query, projectID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.New("approved quotation not found")
+			}
+			return fmt.Errorf("failed to get quotation data: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get quotation data: %w", err)
-	}
 
-	// Get detailed job and cost information using the new query structure
-	detailQuery := `
+		// Get detailed job and cost information using the new query structure
+		detailQuery := `
         WITH MaterialTotals AS (
             SELECT job_id, boq_id, SUM(estimated_price * quantity) as total_material_price 
             FROM material_price_log 
@@ -309,127 +296,125 @@ func (r *quotationRepository) GetExportData(ctx context.Context, projectID uuid.
             (bj.selling_price * bj.quantity) as amount
         FROM project p
         JOIN boq b ON b.project_id = p.project_id
-        JOIN boq_job bj ON bj.boq_id = b.boq_id
+        JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
         JOIN job j ON j.job_id = bj.job_id
         LEFT JOIN MaterialTotals mt ON mt.job_id = j.job_id AND mt.boq_id = b.boq_id
         WHERE p.project_id = $1
         GROUP BY b.selling_general_cost, j.name, j.description, j.unit, bj.quantity, bj.selling_price`
 
-	type jobDetailResult struct {
-		SellingGeneralCost float64         `db:"selling_general_cost"`
-		Name               string          `db:"name"`
-		Description        string          `db:"description"`
-		Unit               string          `db:"unit"`
-		Quantity           float64         `db:"quantity"`
-		SellingPrice       sql.NullFloat64 `db:"selling_price"`
-		Amount             sql.NullFloat64 `db:"amount"`
-	}
-
-	var detailResults []jobDetailResult
-	err = tx.SelectContext(ctx, &detailResults, detailQuery, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get job details: %w", err)
-	}
+		type jobDetailResult struct {
+			SellingGeneralCost float64         `db:"selling_general_cost"`
+			Name               string          `db:"name"`
+			Description        string          `db:"description"`
+			Unit               string          `db:"unit"`
+			Quantity           float64         `db:"quantity"`
+			SellingPrice       sql.NullFloat64 `db:"selling_price"`
+			Amount             sql.NullFloat64 `db:"amount"`
+		}
 
-	// Process job details and calculate totals
-	data.JobDetails = make([]responses.JobDetail, len(detailResults))
-	var totalSellingPrice float64
+		var detailResults []jobDetailResult
+		err = tx.SelectContext(ctx, &detailResults, detailQuery, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to get job details: %w", err)
+		}
 
-	// Set selling general cost from the first result
-	if len(detailResults) > 0 {
-		data.SellingGeneralCost = detailResults[0].SellingGeneralCost
-	}
+		// Process job details and calculate totals
+		data.JobDetails = make([]responses.JobDetail, len(detailResults))
+		var totalSellingPrice float64
 
-	for i, result := range detailResults {
-		data.JobDetails[i] = responses.JobDetail{
-			Name:         result.Name,
-			Description:  result.Description,
-			Unit:         result.Unit,
-			Quantity:     result.Quantity,
-			SellingPrice: result.SellingPrice,
-			Amount:       result.Amount,
+		// Set selling general cost from the first result
+		if len(detailResults) > 0 {
+			data.SellingGeneralCost = detailResults[0].SellingGeneralCost
 		}
 
-		if result.Amount.Valid {
-			totalSellingPrice += result.Amount.Float64
+		for i, result := range detailResults {
+			data.JobDetails[i] = responses.JobDetail{
+				Name:         result.Name,
+				Description:  result.Description,
+				Unit:         result.Unit,
+				Quantity:     result.Quantity,
+				SellingPrice: result.SellingPrice,
+				Amount:       result.Amount,
+			}
+
+			if result.Amount.Valid {
+				totalSellingPrice += result.Amount.Float64
+			}
 		}
-	}
 
-	// Calculate subtotal including selling general cost and tax
-	data.SubTotal = data.SellingGeneralCost + totalSellingPrice
+		// Calculate subtotal including selling general cost and tax
+		data.SubTotal = data.SellingGeneralCost + totalSellingPrice
 
-	// Calculate tax amount if tax percentage exists
-	if data.TaxPercentage > 0 {
-		data.TaxAmount = data.SubTotal * data.TaxPercentage / 100
+		// Calculate tax amount if tax percentage exists
+		if data.TaxPercentage > 0 {
+			data.TaxAmount = data.SubTotal * data.TaxPercentage / 100
 
-		// Update final amount if not already set
-		if !data.FinalAmount.Valid {
-			data.FinalAmount = sql.NullFloat64{
-				Float64: data.SubTotal + data.TaxAmount,
-				Valid:   true,
+			// Update final amount if not already set
+			if !data.FinalAmount.Valid {
+				data.FinalAmount = sql.NullFloat64{
+					Float64: data.SubTotal + data.TaxAmount,
+					Valid:   true,
+				}
 			}
 		}
-	}
 
-	// Format all nullable fields
-	data.FormatFinalAmount()
-	for i := range data.JobDetails {
-		if data.JobDetails[i].SellingPrice.Valid {
-			value := data.JobDetails[i].SellingPrice.Float64
-			data.JobDetails[i].FormattedSellingPrice = &value
-		}
-		if data.JobDetails[i].Amount.Valid {
-			value := data.JobDetails[i].Amount.Float64
-			data.JobDetails[i].FormattedAmount = &value
+		// Format all nullable fields
+		data.FormatFinalAmount()
+		for i := range data.JobDetails {
+			if data.JobDetails[i].SellingPrice.Valid {
+				value := data.JobDetails[i].SellingPrice.Float64
+				data.JobDetails[i].FormattedSellingPrice = &value
+			}
+			if data.JobDetails[i].Amount.Valid {
+				value := data.JobDetails[i].Amount.Float64
+				data.JobDetails[i].FormattedAmount = &value
+			}
 		}
-	}
 
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		result = &data
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &data, nil
+	return result, nil
 }
 
 func (r *quotationRepository) UpdateProjectSellingPrice(ctx context.Context, req requests.UpdateProjectSellingPriceRequest) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Update quotation tax percentage
-	query := `UPDATE quotation SET tax_percentage = $1 WHERE project_id = $2`
-	_, err = tx.ExecContext(ctx, query, req.TaxPercentage, req.ProjectID)
-	if err != nil {
-		return fmt.Errorf("failed to update tax percentage: %w", err)
-	}
-
-	query = `UPDATE boq SET selling_general_cost = $1 WHERE project_id = $2`
-	_, err = tx.ExecContext(ctx, query, req.SellingGeneralCost, req.ProjectID)
-	if err != nil {
-		return fmt.Errorf("failed to update selling general cost: %w", err)
-	}
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Update quotation tax percentage
+		query := `UPDATE quotation SET tax_percentage = $1 WHERE project_id = $2`
+		_, err := tx.ExecContext(ctx, query, req.TaxPercentage, req.ProjectID)
+		if err != nil {
+			return fmt.Errorf("failed to update tax percentage: %w", err)
+		}
 
-	// Get BOQ ID
-	var boqID uuid.UUID
-	query = `SELECT boq_id FROM boq WHERE project_id = $1`
-	err = tx.GetContext(ctx, &boqID, query, req.ProjectID)
-	if err != nil {
-		return fmt.Errorf("failed to get BOQ ID: %w", err)
-	}
+		query = `UPDATE boq SET selling_general_cost = $1 WHERE project_id = $2`
+		_, err = tx.ExecContext(ctx, query, req.SellingGeneralCost, req.ProjectID)
+		if err != nil {
+			return fmt.Errorf("failed to update selling general cost: %w", err)
+		}
 
-	// Update job selling prices
-	for _, job := range req.JobSellingPrices {
-		query = `UPDATE boq_job SET selling_price = $1 WHERE boq_id = $2 AND job_id = $3`
-		_, err = tx.ExecContext(ctx, query, job.SellingPrice, boqID, job.JobID)
+		// Get BOQ ID
+		var boqID uuid.UUID
+		query = `SELECT boq_id FROM boq WHERE project_id = $1`
+		err = tx.GetContext(ctx, &boqID, query, req.ProjectID)
 		if err != nil {
-			return fmt.Errorf("failed to update job selling price: %w", err)
+			return fmt.Errorf("failed to get BOQ ID: %w", err)
 		}
-	}
 
-	// Update final amount
-	query = `
+		// Update job selling prices
+		for _, job := range req.JobSellingPrices {
+			query = `UPDATE boq_job SET selling_price = $1 WHERE boq_id = $2 AND job_id = $3 AND deleted_at IS NULL`
+			_, err = tx.ExecContext(ctx, query, job.SellingPrice, boqID, job.JobID)
+			if err != nil {
+				return fmt.Errorf("failed to update job selling price: %w", err)
+			}
+		}
+
+		// Update final amount
+		query = `
         WITH ProjectCostData AS (
             SELECT 
                 q.tax_percentage, 
@@ -437,7 +422,7 @@ func (r *quotationRepository) UpdateProjectSellingPrice(ctx context.Context, req
             FROM project p 
             JOIN boq b ON b.project_id = p.project_id 
             JOIN quotation q ON q.project_id = p.project_id 
-            LEFT JOIN boq_job bj ON bj.boq_id = b.boq_id 
+            LEFT JOIN boq_job bj ON bj.boq_id = b.boq_id AND bj.deleted_at IS NULL
             WHERE p.project_id = $1 
             GROUP BY bj.boq_id, q.tax_percentage , b.selling_general_cost
         )
@@ -448,10 +433,11 @@ func (r *quotationRepository) UpdateProjectSellingPrice(ctx context.Context, req
         ) 
         WHERE project_id = $1`
 
-	_, err = tx.ExecContext(ctx, query, req.ProjectID)
-	if err != nil {
-		return fmt.Errorf("failed to update final amount: %w", err)
-	}
+		_, err = tx.ExecContext(ctx, query, req.ProjectID)
+		if err != nil {
+			return fmt.Errorf("failed to update final amount: %w", err)
+		}
 
-	return tx.Commit()
+		return nil
+	})
 }
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// priceListBatchSize is the number of BOQs updated per transaction when a
+// supplier price list is propagated.
+const priceListBatchSize = 50
+
+// ApplyPriceListToOpenBOQs writes the supplier's new prices onto every draft
+// BOQ where the supplier is selected for a listed material. BOQs are updated
+// in batches so one large propagation does not hold locks across all of them;
+// a failed batch returns an error, and the batches before it stay committed.
+func (r *supplierRepository) ApplyPriceListToOpenBOQs(ctx context.Context, supplierID uuid.UUID, req requests.SupplierPriceListRequest) (*responses.PriceListApplyResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: price list is empty", repositories.ErrInvalidInput)
+	}
+
+	materialIDs := make([]string, len(req.Items))
+	prices := make([]float64, len(req.Items))
+	for i, item := range req.Items {
+		if item.Price < 0 {
+			return nil, fmt.Errorf("%w: price for material %s cannot be negative", repositories.ErrInvalidInput, item.MaterialID)
+		}
+		materialIDs[i] = item.MaterialID
+		prices[i] = item.Price
+	}
+
+	if _, err := r.GetByID(ctx, supplierID); err != nil {
+		return nil, err
+	}
+
+	boqQuery := `
+        SELECT DISTINCT b.boq_id
+        FROM boq b
+        JOIN material_price_log mpl ON mpl.boq_id = b.boq_id
+        WHERE b.status = 'draft'
+        AND mpl.supplier_id = $1
+        AND mpl.material_id = ANY($2)
+        ORDER BY b.boq_id`
+
+	var boqIDs []uuid.UUID
+	err := r.db.SelectContext(ctx, &boqIDs, boqQuery, supplierID, pq.Array(materialIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find open BOQs: %w", err)
+	}
+
+	updateQuery := `
+        UPDATE material_price_log mpl
+        SET actual_price = pl.price,
+            updated_at = CURRENT_TIMESTAMP
+        FROM unnest($2::varchar[], $3::numeric[]) AS pl(material_id, price),
+             boq b
+        WHERE mpl.material_id = pl.material_id
+        AND mpl.supplier_id = $1
+        AND mpl.boq_id = ANY($4)
+        AND b.boq_id = mpl.boq_id
+        AND b.status = 'draft'
+        AND mpl.actual_price IS DISTINCT FROM pl.price
+        RETURNING mpl.boq_id`
+
+	result := &responses.PriceListApplyResponse{SupplierID: supplierID}
+	for start := 0; start < len(boqIDs); start += priceListBatchSize {
+		end := start + priceListBatchSize
+		if end > len(boqIDs) {
+			end = len(boqIDs)
+		}
+
+		changed, err := r.applyPriceListBatch(ctx, updateQuery, supplierID, materialIDs, prices, boqIDs[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("%d BOQs were updated before the failure: %w", result.BOQsUpdated, err)
+		}
+
+		for _, count := range changed {
+			result.RowsUpdated += count
+		}
+		result.BOQsUpdated += len(changed)
+	}
+
+	return result, nil
+}
+
+func (r *supplierRepository) applyPriceListBatch(ctx context.Context, query string, supplierID uuid.UUID, materialIDs []string, prices []float64, boqIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	var result map[uuid.UUID]int
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		ids := make([]string, len(boqIDs))
+		for i, id := range boqIDs {
+			ids[i] = id.String()
+		}
+
+		var updated []uuid.UUID
+		err := tx.SelectContext(ctx, &updated, query, supplierID, pq.Array(materialIDs), pq.Array(prices), pq.Array(ids))
+		if err != nil {
+			return fmt.Errorf("failed to apply price list: %w", err)
+		}
+
+		changed := make(map[uuid.UUID]int)
+		for _, boqID := range updated {
+			changed[boqID]++
+		}
+
+		if len(changed) > 0 {
+			changedIDs := make([]string, 0, len(changed))
+			for boqID := range changed {
+				changedIDs = append(changedIDs, boqID.String())
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE boq SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE boq_id = ANY($1)`, pq.Array(changedIDs))
+			if err != nil {
+				return fmt.Errorf("failed to update BOQ versions: %w", err)
+			}
+		}
+
+		result = changed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
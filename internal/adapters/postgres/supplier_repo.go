@@ -26,18 +26,19 @@ func NewSupplierRepository(db *sqlx.DB) repositories.SupplierRepository {
 
 func (r *supplierRepository) Create(ctx context.Context, req requests.CreateSupplierRequest) (*models.Supplier, error) {
 	supplier := &models.Supplier{
-		SupplierID: uuid.New(),
-		Name:       req.Name,
-		Email:      req.Email,
-		Tel:        req.Tel,
-		Address:    req.Address,
+		SupplierID:  uuid.New(),
+		Name:        req.Name,
+		Email:       req.Email,
+		Tel:         req.Tel,
+		Address:     req.Address,
+		IsPreferred: req.IsPreferred,
 	}
 
 	query := `
 	INSERT INTO Supplier (
-	supplier_id, name, email,tel, address
+	supplier_id, name, email,tel, address, is_preferred
 	) VALUES (
-	 :supplier_id, :name , :email, :tel, :address
+	 :supplier_id, :name , :email, :tel, :address, :is_preferred
 	 ) RETURNING *
 	`
 
@@ -67,15 +68,17 @@ func (r *supplierRepository) Update(ctx context.Context, id uuid.UUID, req reque
             name = :name,
             email = :email,
             tel = :tel,
-            address = :address
+            address = :address,
+            is_preferred = :is_preferred
         WHERE supplier_id = :supplier_id`
 
 	params := map[string]interface{}{
-		"supplier_id": id,
-		"name":        req.Name,
-		"email":       req.Email,
-		"tel":         req.Tel,
-		"address":     req.Address,
+		"supplier_id":  id,
+		"name":         req.Name,
+		"email":        req.Email,
+		"tel":          req.Tel,
+		"address":      req.Address,
+		"is_preferred": req.IsPreferred,
 	}
 
 	result, err := r.db.NamedExecContext(ctx, query, params)
@@ -98,20 +101,15 @@ func (r *supplierRepository) Update(ctx context.Context, id uuid.UUID, req reque
 	return nil
 }
 func (r *supplierRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Check if supplier is being used
-	type ProjectUsage struct {
-		ProjectID   uuid.UUID `db:"project_id"`
-		ProjectName string    `db:"project_name"`
-		BOQID       uuid.UUID `db:"boq_id"`
-	}
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Check if supplier is being used
+		type ProjectUsage struct {
+			ProjectID   uuid.UUID `db:"project_id"`
+			ProjectName string    `db:"project_name"`
+			BOQID       uuid.UUID `db:"boq_id"`
+		}
 
-	checkUsageQuery := `
+		checkUsageQuery := `
         SELECT DISTINCT 
             pj.project_id,
             pj.name as project_name,
@@ -122,43 +120,39 @@ func (r *supplierRepository) Delete(ctx context.Context, id uuid.UUID) error {
         JOIN project pj ON pj.project_id = b.project_id 
         WHERE sp.supplier_id = $1`
 
-	var usages []ProjectUsage
-	err = tx.SelectContext(ctx, &usages, checkUsageQuery, id)
-	if err != nil {
-		return fmt.Errorf("failed to check supplier usage: %w", err)
-	}
-
-	// If supplier is being used, return error with project names
-	if len(usages) > 0 {
-		var projectNames []string
-		for _, usage := range usages {
-			projectNames = append(projectNames, usage.ProjectName)
+		var usages []ProjectUsage
+		err := tx.SelectContext(ctx, &usages, checkUsageQuery, id)
+		if err != nil {
+			return fmt.Errorf("failed to check supplier usage: %w", err)
 		}
-		return fmt.Errorf("supplier is being used in following projects: %s", strings.Join(projectNames, ", "))
-	}
 
-	// If supplier is not being used, proceed with deletion
-	deleteQuery := `DELETE FROM Supplier WHERE supplier_id = $1`
-	result, err := tx.ExecContext(ctx, deleteQuery, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete supplier: %w", err)
-	}
+		// If supplier is being used, return error with project names
+		if len(usages) > 0 {
+			var projectNames []string
+			for _, usage := range usages {
+				projectNames = append(projectNames, usage.ProjectName)
+			}
+			return fmt.Errorf("supplier is being used in following projects: %s", strings.Join(projectNames, ", "))
+		}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
+		// If supplier is not being used, proceed with deletion
+		deleteQuery := `DELETE FROM Supplier WHERE supplier_id = $1`
+		result, err := tx.ExecContext(ctx, deleteQuery, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete supplier: %w", err)
+		}
 
-	if rows == 0 {
-		return errors.New("supplier not found")
-	}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
+		if rows == 0 {
+			return errors.New("supplier not found")
+		}
 
-	return nil
+		return nil
+	})
 }
 func (r *supplierRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Supplier, error) {
 	supplier := &models.Supplier{}
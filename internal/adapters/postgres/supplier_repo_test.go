@@ -0,0 +1,100 @@
+package postgres_test
+
+import (
+	"boonkosang/internal/adapters/postgres"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupplierRepositoryApplyPriceListToOpenBOQs(t *testing.T) {
+	supplierID := uuid.New()
+	boqID := uuid.New()
+	otherBOQID := uuid.New()
+	req := requests.SupplierPriceListRequest{Items: []requests.SupplierPriceListItem{
+		{MaterialID: "M001", Price: 4.25},
+		{MaterialID: "M002", Price: 12},
+	}}
+
+	t.Run("updates the supplier's lines on open BOQs", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewSupplierRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectQuery(`SELECT \* FROM Supplier WHERE supplier_id = \$1`).
+			WithArgs(supplierID).
+			WillReturnRows(sqlmock.NewRows([]string{"supplier_id", "name"}).AddRow(supplierID, "Siam Bricks"))
+		mock.ExpectQuery(`SELECT DISTINCT b.boq_id FROM boq b .+ WHERE b.status = 'draft'`).
+			WithArgs(supplierID, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id"}).AddRow(boqID).AddRow(otherBOQID))
+		mock.ExpectBegin()
+		// Two lines change on the first BOQ; the second already has the
+		// listed prices, so it is neither updated nor versioned.
+		mock.ExpectQuery(`UPDATE material_price_log mpl SET actual_price = pl.price, .+ AND mpl.actual_price IS DISTINCT FROM pl.price RETURNING mpl.boq_id`).
+			WithArgs(supplierID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id"}).AddRow(boqID).AddRow(boqID))
+		mock.ExpectExec(`UPDATE boq SET version = version \+ 1, updated_at = CURRENT_TIMESTAMP WHERE boq_id = ANY\(\$1\)`).
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		result, err := repo.ApplyPriceListToOpenBOQs(context.Background(), supplierID, req)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.RowsUpdated)
+		assert.Equal(t, 1, result.BOQsUpdated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejects negative prices", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewSupplierRepository(sqlx.NewDb(db, "sqlmock"))
+
+		_, err = repo.ApplyPriceListToOpenBOQs(context.Background(), supplierID, requests.SupplierPriceListRequest{
+			Items: []requests.SupplierPriceListItem{{MaterialID: "M001", Price: -1}},
+		})
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+		assert.ErrorContains(t, err, "price for material M001 cannot be negative")
+
+		_, err = repo.ApplyPriceListToOpenBOQs(context.Background(), supplierID, requests.SupplierPriceListRequest{})
+		assert.ErrorIs(t, err, repositories.ErrInvalidInput)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns an error, not a partial result, when a batch fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := postgres.NewSupplierRepository(sqlx.NewDb(db, "sqlmock"))
+
+		mock.ExpectQuery(`SELECT \* FROM Supplier WHERE supplier_id = \$1`).
+			WithArgs(supplierID).
+			WillReturnRows(sqlmock.NewRows([]string{"supplier_id", "name"}).AddRow(supplierID, "Siam Bricks"))
+		mock.ExpectQuery(`SELECT DISTINCT b.boq_id FROM boq b`).
+			WithArgs(supplierID, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"boq_id"}).AddRow(boqID))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE material_price_log mpl`).
+			WithArgs(supplierID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		result, err := repo.ApplyPriceListToOpenBOQs(context.Background(), supplierID, req)
+		assert.Nil(t, result)
+		assert.ErrorContains(t, err, "0 BOQs were updated before the failure")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"boonkosang/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// withTx runs fn in a transaction that is committed when fn returns nil and
+// rolled back otherwise. A context cancelled while fn ran is reported as the
+// context error rather than as a failed commit.
+func withTx(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// withTx runs fn in a transaction whose statements are each limited to the
+// repository's query timeout. A statement cancelled by that limit, or by a
+// context deadline, fails with ErrQueryTimeout; the transaction is rolled
+// back and its connection returned to the pool as for any other error.
+//
+// A transaction that fails with a serialization failure or deadlock is run
+// again from the start, fn included, up to the repository's attempt limit.
+func (r *boqRepository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+			// SET does not accept bind parameters.
+			timeout := fmt.Sprintf("SET LOCAL statement_timeout = %d", r.queryTimeout.Milliseconds())
+			if _, err := tx.ExecContext(ctx, timeout); err != nil {
+				return fmt.Errorf("failed to set statement timeout: %w", err)
+			}
+
+			return fn(tx)
+		})
+		if !isRetryableTxError(err) || attempt >= r.txAttempts {
+			break
+		}
+
+		r.logger.DebugContext(ctx, "retrying BOQ transaction",
+			slog.Int("attempt", attempt),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return asQueryTimeout(ctx.Err())
+		case <-time.After(time.Duration(attempt) * r.retryBackoff):
+		}
+	}
+
+	return asQueryTimeout(err)
+}
+
+// isRetryableTxError reports whether err is a serialization failure
+// (SQLSTATE 40001) or deadlock (40P01), after which the whole transaction can
+// safely be run again.
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
+// asQueryTimeout wraps err with ErrQueryTimeout when a statement was
+// cancelled by statement_timeout (SQLSTATE 57014) or a context deadline.
+func asQueryTimeout(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "57014" || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", repositories.ErrQueryTimeout, err)
+	}
+
+	return err
+}
@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"boonkosang/internal/domain/models"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	localUserID = "auth_user_id"
+	localRole   = "auth_role"
+)
+
+// Authenticate verifies the bearer token issued at login and records the
+// user and role it names for later handlers. Requests without a token pass
+// through anonymously; a token that fails verification is rejected.
+func Authenticate(secret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			return c.Next()
+		}
+
+		raw, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid authorization header",
+			})
+		}
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+			}
+			return []byte(secret), nil
+		})
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+
+		userID, _ := claims["user_id"].(string)
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+
+		role, _ := claims["role"].(string)
+		c.Locals(localUserID, parsed)
+		c.Locals(localRole, role)
+		return c.Next()
+	}
+}
+
+// authenticatedUser returns the user verified by Authenticate, if any.
+func authenticatedUser(c *fiber.Ctx) (uuid.UUID, bool) {
+	userID, ok := c.Locals(localUserID).(uuid.UUID)
+	return userID, ok
+}
+
+// isAdmin reports whether the request carries a verified admin token.
+func isAdmin(c *fiber.Ctx) bool {
+	role, _ := c.Locals(localRole).(string)
+	return role == models.UserRoleAdmin
+}
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"boonkosang/internal/repositories"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	const secret = "test-secret"
+	userID := uuid.New()
+
+	app := fiber.New()
+	app.Use(Authenticate(secret))
+	app.Get("/whoami", func(c *fiber.Ctx) error {
+		id, ok := authenticatedUser(c)
+		return c.JSON(fiber.Map{"authenticated": ok, "user_id": id, "admin": isAdmin(c)})
+	})
+
+	sign := func(key, role string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": userID,
+			"role":    role,
+			"exp":     time.Now().Add(time.Minute).Unix(),
+		})
+		signed, err := token.SignedString([]byte(key))
+		require.NoError(t, err)
+		return signed
+	}
+
+	testCases := []struct {
+		name           string
+		header         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Anonymous request passes through",
+			expectedStatus: fiber.StatusOK,
+			expectedBody:   `{"admin":false,"authenticated":false,"user_id":"00000000-0000-0000-0000-000000000000"}`,
+		},
+		{
+			name:           "Admin token",
+			header:         "Bearer " + sign(secret, "admin"),
+			expectedStatus: fiber.StatusOK,
+			expectedBody:   `{"admin":true,"authenticated":true,"user_id":"` + userID.String() + `"}`,
+		},
+		{
+			name:           "User token is not admin",
+			header:         "Bearer " + sign(secret, "user"),
+			expectedStatus: fiber.StatusOK,
+			expectedBody:   `{"admin":false,"authenticated":true,"user_id":"` + userID.String() + `"}`,
+		},
+		{
+			name:           "Token signed with another key",
+			header:         "Bearer " + sign("forged", "admin"),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/whoami", nil)
+			if tc.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tc.header)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			if tc.expectedBody != "" {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tc.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestWithRequestActorIgnoresUserHeader(t *testing.T) {
+	const secret = "test-secret"
+	userID := uuid.New()
+
+	app := fiber.New()
+	app.Use(Authenticate(secret))
+	app.Get("/actor", func(c *fiber.Ctx) error {
+		actor, ok := repositories.ActorFromContext(withRequestActor(c))
+		if !ok {
+			return c.SendString("none")
+		}
+		return c.SendString(actor.String())
+	})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	actorOf := func(req *http.Request) string {
+		req.Header.Set("X-User-ID", uuid.NewString())
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	anonymous := httptest.NewRequest(fiber.MethodGet, "/actor", nil)
+	assert.Equal(t, "none", actorOf(anonymous))
+
+	authenticated := httptest.NewRequest(fiber.MethodGet, "/actor", nil)
+	authenticated.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	assert.Equal(t, userID.String(), actorOf(authenticated))
+}
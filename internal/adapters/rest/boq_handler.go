@@ -1,8 +1,18 @@
 package rest
 
 import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
 	"boonkosang/internal/requests"
 	"boonkosang/internal/usecase"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -25,9 +35,75 @@ func (h *BOQHandler) BOQRoutes(app *fiber.App) {
 
 	boq.Post("/:id/approve", h.Approve)
 	boq.Get("/project/:project_id", h.GetBoqWithProject)
+	boq.Post("/project/:project_id", h.CreateBOQForProject)
 	boq.Post("/:id/jobs", h.AddBOQJob)
 	boq.Put("/:id/jobs", h.UpdateBOQJob)
 	boq.Delete("/:id/jobs/:jobId", h.DeleteBOQJob)
+
+	boq.Get("/required-jobs/:projectType", h.GetRequiredJobs)
+	boq.Post("/required-jobs/:projectType", h.AddRequiredJob)
+	boq.Delete("/required-jobs/:projectType/:jobId", h.RemoveRequiredJob)
+	boq.Get("/:id/required-jobs", h.ValidateRequiredJobs)
+	boq.Get("/:id/preferred-supplier-share", h.GetPreferredSupplierShare)
+	boq.Get("/:id/profitability", h.GetJobProfitability)
+	boq.Put("/:id/jobs/selling-price", h.SetJobSellingPrice)
+	boq.Post("/:id/recalculate", h.RecalculateBOQTotal)
+	boq.Post("/project/:project_id/recalculate", h.RecalculateProjectBOQTotals)
+	boq.Get("/high-labor-share", h.GetHighLaborShareBOQs)
+	boq.Get("/:id/attachments", h.ListBOQAttachments)
+	boq.Post("/:id/attachments", h.AddBOQAttachment)
+	boq.Delete("/:id/attachments/:attachmentId", h.RemoveBOQAttachment)
+	boq.Put("/:id/jobs/schedule", h.SetBOQJobSchedule)
+	boq.Get("/:id/cash-flow", h.GetBOQCashFlowCurve)
+	boq.Get("/units/non-canonical", h.GetNonCanonicalUnits)
+	boq.Post("/units/normalize", h.NormalizeUnits)
+	boq.Put("/:id/materials/currency", h.SetMaterialPriceCurrency)
+	boq.Post("/:id/fx-sensitivity", h.GetFXSensitivity)
+	boq.Get("/oversized", h.GetOversizedBOQs)
+	boq.Post("/:id/milestones", h.AddBOQMilestone)
+	boq.Delete("/:id/milestones/:milestoneId", h.RemoveBOQMilestone)
+	boq.Get("/:id/invoice-schedule", h.GetInvoiceSchedule)
+	boq.Get("/:id/price-logs/orphaned", h.GetOrphanedPriceLogs)
+	boq.Delete("/:id/price-logs/orphaned", h.CleanOrphanedPriceLogs)
+	boq.Post("/:id/price-logs/prune", h.PruneOrphanedPriceLogs)
+	boq.Post("/:id/estimate-vs-actual", h.GetEstimateVsActual)
+	boq.Get("/:id/direct-cost-floor", h.GetDirectCostFloor)
+	boq.Get("/approvals/pending/:userId", h.GetPendingApprovalsForUser)
+	boq.Post("/:id/approvals", h.SubmitForApproval)
+	boq.Post("/:id/approvals/:userId/decision", h.DecideApproval)
+	boq.Get("/:id/rate-rounding", h.PreviewRateRounding)
+	boq.Post("/:id/rate-rounding", h.ApplyRateRounding)
+	boq.Get("/:id/markup-cap", h.GetLinesExceedingMarkupCap)
+	boq.Get("/:id/supplier-rollup", h.GetSupplierMaterialRollup)
+	boq.Get("/:id/po-workbook", h.ExportPOWorkbook)
+	boq.Get("/cost-composition-trend", h.GetCostCompositionTrend)
+	boq.Put("/:id/jobs/provisional", h.SetJobProvisional)
+	boq.Get("/:id/provisional-share", h.GetProvisionalShare)
+	boq.Get("/:id/compare/:otherId", h.CompareBOQs)
+	boq.Post("/:id/clone-scaled", h.CloneBOQScaled)
+	boq.Get("/:id/export-validation", h.ValidateBOQForExport)
+	boq.Post("/:id/jobs/batch", h.AddBOQJobs)
+	boq.Get("/:id/mixed-currency", h.GetMixedCurrencyLines)
+	boq.Get("/:id/preliminaries", h.GetPreliminaries)
+	boq.Put("/:id/preliminaries", h.SetPreliminariesPercent)
+	boq.Get("/:id/lifecycle", h.GetBOQLifecycle)
+	boq.Put("/:id/status", h.UpdateBOQStatus)
+	boq.Get("/:id/price-logs", h.GetMaterialPriceLogs)
+	boq.Put("/:id/price-logs", h.UpdateMaterialPrice)
+	boq.Get("/:id/summary", h.GetBOQCostSummary)
+	boq.Post("/:id/jobs/:jobId/restore", h.RestoreBOQJob)
+	boq.Delete("/:id/jobs/:jobId/purge", h.PurgeBOQJob)
+	boq.Delete("/:id/jobs", h.ClearBOQJobs)
+	boq.Get("/:id/export.csv", h.ExportBOQCSV)
+	boq.Get("/:id/jobs", h.ListBOQJobs)
+	boq.Post("/:id/clone", h.CloneBOQ)
+	boq.Put("/:id/jobs/:jobId/quantity", h.UpdateBOQJobQuantity)
+	boq.Post("/:id/lock", h.LockBOQ)
+	boq.Get("/:id/material-totals", h.GetBOQMaterialTotals)
+	boq.Get("/", h.ListBOQs)
+	boq.Put("/:id/general-cost-percentage", h.ApplyGeneralCostPercentage)
+	boq.Post("/:id/jobs/:jobId/move", h.MoveBOQJob)
+	boq.Get("/:id/audit", h.GetBOQAudit)
 }
 
 func (h *BOQHandler) Approve(c *fiber.Ctx) error {
@@ -38,10 +114,25 @@ func (h *BOQHandler) Approve(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.boqUsecase.Approve(c.Context(), boqID)
+	var req requests.ApproveBOQRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	version, err := boqVersion(c)
 	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	err = h.boqUsecase.Approve(withRequestActor(c), boqID, req, version)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -68,8 +159,7 @@ func (h *BOQHandler) GetBoqWithProject(c *fiber.Ctx) error {
 
 	boq, err := h.boqUsecase.GetBoqWithProject(c.Context(), uuid)
 	if err != nil {
-
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -95,16 +185,66 @@ func (h *BOQHandler) AddBOQJob(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.boqUsecase.AddBOQJob(c.Context(), boqID, req)
+	ctx := withRequestActor(c)
+	if c.QueryBool("override_limit") {
+		// Only a verified admin token may lift the job limit
+		if !isAdmin(c) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Only administrators can override the BOQ job limit",
+			})
+		}
+		ctx = repositories.WithBOQSizeOverride(ctx)
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	job, err := h.boqUsecase.AddBOQJobResult(ctx, boqID, req, version)
 	if err != nil {
+		if errors.Is(err, repositories.ErrBOQLocked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		var validationErr *requests.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":  err.Error(),
+				"fields": validationErr.Fields,
+			})
+		}
+
+		if errors.Is(err, repositories.ErrStaleBOQ) {
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		if errors.Is(err, repositories.ErrJobNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, repositories.ErrJobAlreadyInBOQ) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "BOQ job added successfully",
+		"data":    job,
 	})
 }
 
@@ -123,10 +263,17 @@ func (h *BOQHandler) UpdateBOQJob(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.boqUsecase.UpdateBOQJob(c.Context(), boqID, req)
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.UpdateBOQJob(withRequestActor(c), boqID, req, version)
 	if err != nil {
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -151,10 +298,34 @@ func (h *BOQHandler) DeleteBOQJob(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.boqUsecase.DeleteBOQJob(c.Context(), boqID, jobID)
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.DeleteBOQJob(withRequestActor(c), boqID, jobID, version)
 	if err != nil {
+		if errors.Is(err, repositories.ErrJobNotInBOQ) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		if errors.Is(err, repositories.ErrBOQLocked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, repositories.ErrStaleBOQ) {
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -173,9 +344,14 @@ func (h *BOQHandler) ExportBOQ(c *fiber.Ctx) error {
 	}
 
 	// Get BOQ summary data
-	summary, err := h.boqUsecase.GetBOQSummary(c.Context(), projectID)
+	summary, err := h.boqUsecase.ExportBOQ(c.Context(), projectID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		if errors.Is(err, repositories.ErrBOQNotExportable) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -186,3 +362,1820 @@ func (h *BOQHandler) ExportBOQ(c *fiber.Ctx) error {
 	})
 
 }
+
+func (h *BOQHandler) GetRequiredJobs(c *fiber.Ctx) error {
+	jobs, err := h.boqUsecase.GetRequiredJobs(c.Context(), c.Params("projectType"))
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Required jobs retrieved successfully",
+		"data":    jobs,
+	})
+}
+
+func (h *BOQHandler) AddRequiredJob(c *fiber.Ctx) error {
+	var req requests.RequiredJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err := h.boqUsecase.AddRequiredJob(c.Context(), c.Params("projectType"), req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Required job added successfully",
+	})
+}
+
+func (h *BOQHandler) RemoveRequiredJob(c *fiber.Ctx) error {
+	jobID, err := uuid.Parse(c.Params("jobId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	err = h.boqUsecase.RemoveRequiredJob(c.Context(), c.Params("projectType"), jobID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Required job removed successfully",
+	})
+}
+
+func (h *BOQHandler) ValidateRequiredJobs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	check, err := h.boqUsecase.ValidateRequiredJobs(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Required jobs validated successfully",
+		"data":    check,
+	})
+}
+
+func (h *BOQHandler) GetPreferredSupplierShare(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	share, err := h.boqUsecase.GetPreferredSupplierShare(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Preferred supplier share retrieved successfully",
+		"data":    share,
+	})
+}
+
+func (h *BOQHandler) GetJobProfitability(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	profitability, err := h.boqUsecase.GetJobProfitability(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Job profitability retrieved successfully",
+		"data":    profitability,
+	})
+}
+
+func (h *BOQHandler) SetJobSellingPrice(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.JobSellingPrice
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.SetJobSellingPrice(withRequestActor(c), boqID, req, version)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Job selling price updated successfully",
+	})
+}
+
+func (h *BOQHandler) RecalculateBOQTotal(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	total, err := h.boqUsecase.RecalculateBOQTotal(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ total recalculated successfully",
+		"data":    fiber.Map{"total_cost": total},
+	})
+}
+
+func (h *BOQHandler) RecalculateProjectBOQTotals(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("project_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid project ID",
+		})
+	}
+
+	result, err := h.boqUsecase.RecalculateProjectBOQTotals(c.Context(), projectID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Project BOQ totals recalculated successfully",
+		"data":    result,
+	})
+}
+
+func (h *BOQHandler) GetHighLaborShareBOQs(c *fiber.Ctx) error {
+	threshold, err := strconv.ParseFloat(c.Query("threshold", "0.5"), 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid threshold",
+		})
+	}
+
+	boqs, err := h.boqUsecase.GetHighLaborShareBOQs(c.Context(), threshold)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "High labor share BOQs retrieved successfully",
+		"data":    boqs,
+	})
+}
+
+func (h *BOQHandler) ListBOQAttachments(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	attachments, err := h.boqUsecase.ListBOQAttachments(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ attachments retrieved successfully",
+		"data":    attachments,
+	})
+}
+
+func (h *BOQHandler) AddBOQAttachment(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.BOQAttachmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	attachment, err := h.boqUsecase.AddBOQAttachment(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "BOQ attachment added successfully",
+		"data":    attachment,
+	})
+}
+
+func (h *BOQHandler) RemoveBOQAttachment(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	attachmentID, err := uuid.Parse(c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid attachment ID",
+		})
+	}
+
+	err = h.boqUsecase.RemoveBOQAttachment(withRequestActor(c), boqID, attachmentID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "BOQ attachment removed successfully",
+	})
+}
+
+func (h *BOQHandler) SetBOQJobSchedule(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.BOQJobScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err = h.boqUsecase.SetBOQJobSchedule(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "BOQ job schedule updated successfully",
+	})
+}
+
+func (h *BOQHandler) GetBOQCashFlowCurve(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid start date, expected YYYY-MM-DD",
+		})
+	}
+
+	req := requests.CashFlowCurveRequest{
+		StartDate:       startDate,
+		UnscheduledMode: models.CashFlowUnscheduledMode(c.Query("unscheduled_mode", string(models.CashFlowUnscheduledSpread))),
+	}
+
+	curve, err := h.boqUsecase.GetBOQCashFlowCurve(c.Context(), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ cash flow curve retrieved successfully",
+		"data":    curve,
+	})
+}
+
+func (h *BOQHandler) GetNonCanonicalUnits(c *fiber.Ctx) error {
+	units, err := h.boqUsecase.GetNonCanonicalUnits(c.Context())
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Non-canonical units retrieved successfully",
+		"data":    units,
+	})
+}
+
+func (h *BOQHandler) NormalizeUnits(c *fiber.Ctx) error {
+	result, err := h.boqUsecase.NormalizeUnits(c.Context())
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Units normalized successfully",
+		"data":    result,
+	})
+}
+
+func (h *BOQHandler) SetMaterialPriceCurrency(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.MaterialPriceCurrencyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err = h.boqUsecase.SetMaterialPriceCurrency(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Material price currency updated successfully",
+	})
+}
+
+func (h *BOQHandler) GetFXSensitivity(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.FXSensitivityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	sensitivity, err := h.boqUsecase.GetFXSensitivity(c.Context(), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "FX sensitivity calculated successfully",
+		"data":    sensitivity,
+	})
+}
+
+func (h *BOQHandler) GetOversizedBOQs(c *fiber.Ctx) error {
+	boqs, err := h.boqUsecase.GetOversizedBOQs(c.Context(), c.QueryInt("limit"))
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Oversized BOQs retrieved successfully",
+		"data":    boqs,
+	})
+}
+
+func (h *BOQHandler) AddBOQMilestone(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.BOQMilestoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	milestone, err := h.boqUsecase.AddBOQMilestone(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "BOQ milestone added successfully",
+		"data":    milestone,
+	})
+}
+
+func (h *BOQHandler) RemoveBOQMilestone(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	milestoneID, err := uuid.Parse(c.Params("milestoneId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid milestone ID",
+		})
+	}
+
+	err = h.boqUsecase.RemoveBOQMilestone(withRequestActor(c), boqID, milestoneID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "BOQ milestone removed successfully",
+	})
+}
+
+func (h *BOQHandler) GetInvoiceSchedule(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	schedule, err := h.boqUsecase.GetInvoiceSchedule(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Invoice schedule retrieved successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *BOQHandler) GetOrphanedPriceLogs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	orphans, err := h.boqUsecase.GetOrphanedPriceLogs(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Orphaned price logs retrieved successfully",
+		"data":    orphans,
+	})
+}
+
+func (h *BOQHandler) CleanOrphanedPriceLogs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	removed, err := h.boqUsecase.CleanOrphanedPriceLogs(withRequestActor(c), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Orphaned price logs removed successfully",
+		"data":    removed,
+	})
+}
+
+func (h *BOQHandler) PruneOrphanedPriceLogs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	removed, err := h.boqUsecase.PruneOrphanedPriceLogs(withRequestActor(c), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Orphaned price logs pruned successfully",
+		"data":    fiber.Map{"removed": removed},
+	})
+}
+
+// withRequestActor attaches the user verified by Authenticate to the request
+// context for auditing. Anonymous requests are audited without an actor.
+func withRequestActor(c *fiber.Ctx) context.Context {
+	var ctx context.Context = c.Context()
+	if userID, ok := authenticatedUser(c); ok {
+		ctx = repositories.WithActor(ctx, userID)
+	}
+	return ctx
+}
+
+func (h *BOQHandler) GetEstimateVsActual(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.EstimateVsActualRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if len(req.Jobs) == 0 && len(req.Materials) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "At least one job or material actual is required",
+		})
+	}
+
+	comparison, err := h.boqUsecase.GetEstimateVsActual(c.Context(), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Estimate vs actual retrieved successfully",
+		"data":    comparison,
+	})
+}
+
+func (h *BOQHandler) GetDirectCostFloor(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	floor, err := h.boqUsecase.GetDirectCostFloor(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Direct cost floor retrieved successfully",
+		"data":    floor,
+	})
+}
+
+func (h *BOQHandler) SubmitForApproval(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.SubmitBOQApprovalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err = h.boqUsecase.SubmitForApproval(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "BOQ submitted for approval successfully",
+	})
+}
+
+func (h *BOQHandler) DecideApproval(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req requests.BOQApprovalDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err = h.boqUsecase.DecideApproval(withRequestActor(c), boqID, userID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Approval decision recorded successfully",
+	})
+}
+
+func (h *BOQHandler) GetPendingApprovalsForUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	pending, err := h.boqUsecase.GetPendingApprovalsForUser(c.Context(), userID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Pending approvals retrieved successfully",
+		"data":    pending,
+	})
+}
+
+func (h *BOQHandler) PreviewRateRounding(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	roundTo, err := strconv.ParseFloat(c.Query("round_to"), 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid round_to",
+		})
+	}
+
+	preview, err := h.boqUsecase.PreviewRateRounding(c.Context(), boqID, roundTo)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Rate rounding preview retrieved successfully",
+		"data":    preview,
+	})
+}
+
+func (h *BOQHandler) ApplyRateRounding(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	roundTo, err := strconv.ParseFloat(c.Query("round_to"), 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid round_to",
+		})
+	}
+
+	result, err := h.boqUsecase.ApplyRateRounding(withRequestActor(c), boqID, roundTo)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Rate rounding applied successfully",
+		"data":    result,
+	})
+}
+
+func (h *BOQHandler) GetLinesExceedingMarkupCap(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	maxMarkup, err := strconv.ParseFloat(c.Query("cap"), 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid cap",
+		})
+	}
+
+	lines, err := h.boqUsecase.GetLinesExceedingMarkupCap(c.Context(), boqID, maxMarkup)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Lines exceeding markup cap retrieved successfully",
+		"data":    lines,
+	})
+}
+
+func (h *BOQHandler) GetSupplierMaterialRollup(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	rollup, err := h.boqUsecase.GetSupplierMaterialRollup(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Supplier material rollup retrieved successfully",
+		"data":    rollup,
+	})
+}
+
+func (h *BOQHandler) ExportPOWorkbook(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	workbook, err := h.boqUsecase.ExportPOWorkbook(c.Context(), boqID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrBOQNotExportable) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="boq-%s-purchase-orders.xlsx"`, boqID))
+	return c.Send(workbook)
+}
+
+func (h *BOQHandler) GetCostCompositionTrend(c *fiber.Ctx) error {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid from date, expected YYYY-MM-DD",
+		})
+	}
+
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid to date, expected YYYY-MM-DD",
+		})
+	}
+
+	trend, err := h.boqUsecase.GetCostCompositionTrend(c.Context(), from, to.AddDate(0, 0, 1), c.Query("bucket", "month"))
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Cost composition trend retrieved successfully",
+		"data":    trend,
+	})
+}
+
+func (h *BOQHandler) SetJobProvisional(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.JobProvisionalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.SetJobProvisional(withRequestActor(c), boqID, req, version)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Job provisional flag updated successfully",
+	})
+}
+
+func (h *BOQHandler) GetProvisionalShare(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	share, err := h.boqUsecase.GetProvisionalShare(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Provisional share retrieved successfully",
+		"data":    share,
+	})
+}
+
+func (h *BOQHandler) CompareBOQs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	otherID, err := uuid.Parse(c.Params("otherId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID to compare with",
+		})
+	}
+
+	diff, err := h.boqUsecase.CompareBOQs(c.Context(), boqID, otherID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ comparison retrieved successfully",
+		"data":    diff,
+	})
+}
+
+func (h *BOQHandler) CloneBOQScaled(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.CloneBOQScaledRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	newBOQID, err := h.boqUsecase.CloneBOQScaled(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "BOQ cloned successfully",
+		"data": fiber.Map{
+			"boq_id": newBOQID,
+		},
+	})
+}
+
+func (h *BOQHandler) ValidateBOQForExport(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	validation, err := h.boqUsecase.ValidateBOQForExport(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ export validation completed successfully",
+		"data":    validation,
+	})
+}
+
+func (h *BOQHandler) AddBOQJobs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.BOQJobsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	ctx := withRequestActor(c)
+	if c.QueryBool("override_limit") {
+		// Only a verified admin token may lift the job limit
+		if !isAdmin(c) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Only administrators can override the BOQ job limit",
+			})
+		}
+		ctx = repositories.WithBOQSizeOverride(ctx)
+	}
+
+	result, err := h.boqUsecase.AddBOQJobs(ctx, boqID, req.Jobs, version)
+	if err != nil {
+		if errors.Is(err, repositories.ErrBOQLocked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		var validationErr *requests.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":  err.Error(),
+				"fields": validationErr.Fields,
+			})
+		}
+
+		if errors.Is(err, repositories.ErrJobNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		if errors.Is(err, repositories.ErrJobAlreadyInBOQ) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Jobs added to BOQ successfully",
+		"data":    result,
+	})
+}
+
+func (h *BOQHandler) GetMixedCurrencyLines(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	lines, err := h.boqUsecase.GetMixedCurrencyLines(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Mixed currency lines retrieved successfully",
+		"data":    lines,
+	})
+}
+
+func (h *BOQHandler) SetPreliminariesPercent(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.PreliminariesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err = h.boqUsecase.SetPreliminariesPercent(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Preliminaries percentage updated successfully",
+	})
+}
+
+func (h *BOQHandler) GetPreliminaries(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	preliminaries, err := h.boqUsecase.GetPreliminaries(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Preliminaries retrieved successfully",
+		"data":    preliminaries,
+	})
+}
+
+func (h *BOQHandler) GetBOQLifecycle(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	lifecycle, err := h.boqUsecase.GetBOQLifecycle(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ lifecycle retrieved successfully",
+		"data":    lifecycle,
+	})
+}
+
+func (h *BOQHandler) UpdateBOQStatus(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.UpdateBOQStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.UpdateBOQStatus(withRequestActor(c), boqID, req.Status, version)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ status updated successfully",
+	})
+}
+
+func (h *BOQHandler) UpdateMaterialPrice(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.MaterialPriceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.UpdateMaterialPrice(withRequestActor(c), boqID, req, version)
+	if err != nil {
+		if errors.Is(err, repositories.ErrBOQLocked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		if errors.Is(err, repositories.ErrStaleBOQ) {
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Material price updated successfully",
+	})
+}
+
+func (h *BOQHandler) GetMaterialPriceLogs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	logs, err := h.boqUsecase.GetMaterialPriceLogs(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Material price logs retrieved successfully",
+		"data":    logs,
+	})
+}
+
+func (h *BOQHandler) GetBOQCostSummary(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	summary, err := h.boqUsecase.GetBOQCostSummary(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ summary retrieved successfully",
+		"data":    summary,
+	})
+}
+
+func (h *BOQHandler) RestoreBOQJob(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("jobId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.RestoreBOQJob(withRequestActor(c), boqID, jobID, version)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ job restored successfully",
+	})
+}
+
+func (h *BOQHandler) PurgeBOQJob(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("jobId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.PurgeBOQJob(withRequestActor(c), boqID, jobID, version)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ job purged successfully",
+	})
+}
+
+func (h *BOQHandler) ClearBOQJobs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	removed, err := h.boqUsecase.ClearBOQJobs(withRequestActor(c), boqID, version)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ jobs cleared successfully",
+		"data":    fiber.Map{"removed": removed},
+	})
+}
+
+func (h *BOQHandler) CreateBOQForProject(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("project_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid project ID format",
+		})
+	}
+
+	var opts requests.CreateBOQOptions
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&opts); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	boq, err := h.boqUsecase.CreateBOQForProject(withRequestActor(c), projectID, opts)
+	if err != nil {
+		var validationErr *requests.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":  err.Error(),
+				"fields": validationErr.Fields,
+			})
+		}
+
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "BOQ created successfully",
+		"data":    boq,
+	})
+}
+
+func (h *BOQHandler) ExportBOQCSV(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	// The export runs against a pipe that the response body reads from, so
+	// rows go out as they are written. Closing the body when the client goes
+	// away fails the next write and ends the export.
+	ctx := c.Context()
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(h.boqUsecase.ExportBOQCSVStream(ctx, boqID, pw))
+	}()
+
+	// Nothing is written for a BOQ that cannot be exported, so the first read
+	// tells whether an error response can still be sent.
+	first := make([]byte, 4096)
+	n, err := pr.Read(first)
+	if err != nil && err != io.EOF {
+		pr.Close()
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="boq-%s.csv"`, boqID))
+	c.Response().SetBodyStream(struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(first[:n]), pr), pr}, -1)
+	return nil
+}
+
+func (h *BOQHandler) ListBOQJobs(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.ListBOQJobsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid query parameters",
+		})
+	}
+
+	switch req.Sort {
+	case "", "name", "quantity", "labor_cost":
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid sort field, expected name, quantity or labor_cost",
+		})
+	}
+
+	jobs, err := h.boqUsecase.ListBOQJobs(c.Context(), boqID, req)
+	if err != nil {
+		if errors.Is(err, repositories.ErrBOQNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ jobs retrieved successfully",
+		"data":    jobs,
+	})
+}
+
+func (h *BOQHandler) ListBOQs(c *fiber.Ctx) error {
+	var req requests.ListBOQRequest
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid query parameters",
+		})
+	}
+
+	if ids := c.Query("project_ids"); ids != "" {
+		for _, raw := range strings.Split(ids, ",") {
+			projectID, err := uuid.Parse(strings.TrimSpace(raw))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid project ID",
+				})
+			}
+			req.ProjectIDs = append(req.ProjectIDs, projectID)
+		}
+	}
+
+	boqs, err := h.boqUsecase.ListBOQs(c.Context(), req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQs retrieved successfully",
+		"data":    boqs,
+	})
+}
+
+func (h *BOQHandler) ApplyGeneralCostPercentage(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.GeneralCostPercentageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	amount, err := h.boqUsecase.ApplyGeneralCostPercentage(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Selling general cost updated successfully",
+		"data":    fiber.Map{"selling_general_cost": amount},
+	})
+}
+
+func (h *BOQHandler) MoveBOQJob(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("jobId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	var req requests.MoveBOQJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	err = h.boqUsecase.MoveBOQJob(withRequestActor(c), boqID, jobID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Job moved successfully",
+	})
+}
+
+func (h *BOQHandler) GetBOQAudit(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	entries, err := h.boqUsecase.GetBOQAudit(c.Context(), boqID)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ audit retrieved successfully",
+		"data":    entries,
+	})
+}
+
+// boqErrorStatus maps the sentinel errors of the repositories package to an
+// HTTP status. Errors it does not recognise are reported as 500.
+func boqErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repositories.ErrBOQNotFound),
+		errors.Is(err, repositories.ErrProjectNotFound),
+		errors.Is(err, repositories.ErrJobNotFound),
+		errors.Is(err, repositories.ErrJobNotInBOQ),
+		errors.Is(err, repositories.ErrMaterialNotInBOQ),
+		errors.Is(err, repositories.ErrAttachmentNotFound),
+		errors.Is(err, repositories.ErrMilestoneNotFound),
+		errors.Is(err, repositories.ErrNoPriceLogs),
+		errors.Is(err, repositories.ErrNoPendingApproval):
+		return fiber.StatusNotFound
+	case errors.Is(err, repositories.ErrBOQNotDraft),
+		errors.Is(err, repositories.ErrBOQLocked),
+		errors.Is(err, repositories.ErrBOQExists),
+		errors.Is(err, repositories.ErrJobAlreadyInBOQ),
+		errors.Is(err, repositories.ErrApprovalRejected),
+		errors.As(err, new(*models.StatusTransitionError)):
+		return fiber.StatusConflict
+	case errors.Is(err, repositories.ErrStaleBOQ):
+		return fiber.StatusPreconditionFailed
+	case errors.Is(err, repositories.ErrMissingRequiredJobs),
+		errors.Is(err, repositories.ErrMarkupCapExceeded),
+		errors.Is(err, repositories.ErrNonCanonicalUnit),
+		errors.Is(err, repositories.ErrMilestonesUnbalanced),
+		errors.Is(err, repositories.ErrProvisionalExceedsCap),
+		errors.Is(err, repositories.ErrMixedCurrency),
+		errors.Is(err, repositories.ErrBOQTooLarge),
+		errors.Is(err, repositories.ErrBOQNotLockable),
+		errors.Is(err, repositories.ErrProjectClosed):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, repositories.ErrNotAwaitingApprover):
+		return fiber.StatusForbidden
+	case errors.Is(err, repositories.ErrInvalidInput):
+		return fiber.StatusBadRequest
+	case errors.Is(err, repositories.ErrQueryTimeout):
+		return fiber.StatusGatewayTimeout
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// boqVersion reads the BOQ version the client last read from the If-Match
+// header of a mutating request.
+func boqVersion(c *fiber.Ctx) (int, error) {
+	return strconv.Atoi(strings.Trim(c.Get(fiber.HeaderIfMatch), `"`))
+}
+
+func (h *BOQHandler) CloneBOQ(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	var req requests.CloneBOQRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	newBOQID, err := h.boqUsecase.CloneBOQ(withRequestActor(c), boqID, req)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "BOQ cloned successfully",
+		"data": fiber.Map{
+			"boq_id": newBOQID,
+		},
+	})
+}
+
+func (h *BOQHandler) UpdateBOQJobQuantity(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("jobId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	var req requests.UpdateBOQJobQuantityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Quantity <= 0 || req.LaborCost < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Quantity must be positive and labor cost must not be negative",
+		})
+	}
+
+	version, err := boqVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the BOQ version is required",
+		})
+	}
+
+	err = h.boqUsecase.UpdateBOQJobQuantity(withRequestActor(c), boqID, jobID, req, version)
+	if err != nil {
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ job quantity updated successfully",
+	})
+}
+
+func (h *BOQHandler) LockBOQ(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	err = h.boqUsecase.LockBOQ(withRequestActor(c), boqID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrBOQNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ locked successfully",
+	})
+}
+
+func (h *BOQHandler) GetBOQMaterialTotals(c *fiber.Ctx) error {
+	boqID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid BOQ ID",
+		})
+	}
+
+	totals, err := h.boqUsecase.GetBOQMaterialTotals(c.Context(), boqID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrBOQNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(boqErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "BOQ material totals retrieved successfully",
+		"data":    totals,
+	})
+}
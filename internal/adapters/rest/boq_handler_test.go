@@ -0,0 +1,199 @@
+package rest
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"boonkosang/internal/usecase"
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBOQUsecase implements only the BOQ usecase methods a test uses;
+// calling any other method panics on the nil embedded interface.
+type stubBOQUsecase struct {
+	usecase.BOQUsecase
+	approveErr error
+	statusErr  error
+	getErr     error
+}
+
+func (s *stubBOQUsecase) Approve(ctx context.Context, boqID uuid.UUID, req requests.ApproveBOQRequest, expectedVersion int) error {
+	return s.approveErr
+}
+
+func (s *stubBOQUsecase) UpdateBOQStatus(ctx context.Context, boqID uuid.UUID, newStatus models.BOQStatus, expectedVersion int) error {
+	return s.statusErr
+}
+
+func (s *stubBOQUsecase) GetBoqWithProject(ctx context.Context, projectID uuid.UUID) (*responses.BOQResponse, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	return &responses.BOQResponse{ProjectID: projectID}, nil
+}
+
+func (s *stubBOQUsecase) ListBOQs(ctx context.Context, req requests.ListBOQRequest) ([]responses.BOQListItemResponse, error) {
+	return []responses.BOQListItemResponse{{ID: uuid.New(), ProjectID: uuid.New(), Status: models.BOQStatusDraft, JobCount: 3}}, nil
+}
+
+func TestBOQHandlerApproveStatus(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "Approved",
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "Markup cap exceeded",
+			err:            fmt.Errorf("%w: 20.00%% cap exceeded by Wall (35.00%%)", repositories.ErrMarkupCapExceeded),
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Missing required jobs",
+			err:            fmt.Errorf("%w: Footing", repositories.ErrMissingRequiredJobs),
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Provisional sums over the cap",
+			err:            fmt.Errorf("%w: 25.00%% of the grand total", repositories.ErrProvisionalExceedsCap),
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Not a draft",
+			err:            &models.StatusTransitionError{From: models.BOQStatusLocked, To: models.BOQStatusApproved},
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name:           "Unknown BOQ",
+			err:            repositories.ErrBOQNotFound,
+			expectedStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			NewBOQHandler(&stubBOQUsecase{approveErr: tc.err}).BOQRoutes(app)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/boqs/"+uuid.New().String()+"/approve", strings.NewReader(`{"max_markup_percent":20}`))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			req.Header.Set(fiber.HeaderIfMatch, `"3"`)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+// Approving through PUT /status goes through the same checks as /approve and
+// must answer the same status for the same rejection.
+func TestBOQHandlerUpdateBOQStatusStatus(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "Updated",
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "Provisional sums over the cap",
+			err:            fmt.Errorf("%w: 25.00%% of the grand total", repositories.ErrProvisionalExceedsCap),
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Mixed currencies",
+			err:            fmt.Errorf("%w: USD, EUR", repositories.ErrMixedCurrency),
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid transition",
+			err:            &models.StatusTransitionError{From: models.BOQStatusDraft, To: models.BOQStatusLocked},
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name:           "Stale version",
+			err:            repositories.ErrStaleBOQ,
+			expectedStatus: fiber.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			NewBOQHandler(&stubBOQUsecase{statusErr: tc.err}).BOQRoutes(app)
+
+			req := httptest.NewRequest(fiber.MethodPut, "/boqs/"+uuid.New().String()+"/status", strings.NewReader(`{"status":"approved"}`))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			req.Header.Set(fiber.HeaderIfMatch, `"3"`)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestBOQHandlerGetBoqWithProjectStatus(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "Found",
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "Project without a BOQ",
+			err:            repositories.ErrBOQNotFound,
+			expectedStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			NewBOQHandler(&stubBOQUsecase{getErr: tc.err}).BOQRoutes(app)
+
+			req := httptest.NewRequest(fiber.MethodGet, "/boqs/project/"+uuid.New().String(), nil)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestBOQHandlerListBOQsHasNoNullFields(t *testing.T) {
+	app := fiber.New()
+	NewBOQHandler(&stubBOQUsecase{}).BOQRoutes(app)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/boqs/", nil)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"job_count":3`)
+	assert.NotContains(t, string(body), `"jobs"`)
+	assert.NotContains(t, string(body), "null")
+}
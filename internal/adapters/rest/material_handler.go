@@ -4,6 +4,7 @@ package rest
 import (
 	"boonkosang/internal/requests"
 	"boonkosang/internal/usecase"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -25,6 +26,7 @@ func (h *MaterialHandler) MaterialRoutes(app *fiber.App) {
 	material.Post("/", h.Create)
 	material.Get("/", h.List)
 
+	material.Get("/volatility", h.GetVolatileMaterials)
 	material.Get("/:projectId/prices", h.GetMaterialPrices)
 	material.Put("/:boqId/estimated-price", h.UpdateEstimatedPrice)
 	material.Put("/:boqId/actual-price", h.UpdateActualPrice)
@@ -279,3 +281,31 @@ func (h *MaterialHandler) UpdateActualPrice(c *fiber.Ctx) error {
 		"message": "Actual price updated successfully",
 	})
 }
+
+func (h *MaterialHandler) GetVolatileMaterials(c *fiber.Ctx) error {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid from date, expected YYYY-MM-DD",
+		})
+	}
+
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid to date, expected YYYY-MM-DD",
+		})
+	}
+
+	materials, err := h.materialUsecase.GetVolatileMaterials(c.Context(), from, to.AddDate(0, 0, 1), c.QueryInt("limit", 10))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Volatile materials retrieved successfully",
+		"data":    materials,
+	})
+}
@@ -23,6 +23,7 @@ func (h *ProjectHandler) ProjectRoutes(app *fiber.App) {
 
 	project.Post("/", h.Create)
 	project.Get("/", h.List)
+	project.Get("/benchmarks/:projectType", h.GetBenchmarks)
 	project.Get("/:projectId/summary", h.GetProjectSummary)
 	project.Get("/:projectId/overview", h.GetProjectOverview)
 	project.Get("/:id", h.GetByID)
@@ -260,3 +261,17 @@ func (h *ProjectHandler) GetProjectSummary(c *fiber.Ctx) error {
 		"data":    summary,
 	})
 }
+
+func (h *ProjectHandler) GetBenchmarks(c *fiber.Ctx) error {
+	benchmarks, err := h.projectUsecase.GetBenchmarks(c.Context(), c.Params("projectType"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Project benchmarks retrieved successfully",
+		"data":    benchmarks,
+	})
+}
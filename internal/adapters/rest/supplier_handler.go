@@ -1,8 +1,10 @@
 package rest
 
 import (
+	"boonkosang/internal/repositories"
 	"boonkosang/internal/requests"
 	"boonkosang/internal/usecase"
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -27,6 +29,7 @@ func (h *SupplierHandler) SupplierRoutes(app *fiber.App) {
 	supplier.Get("/:id", h.GetByID)
 	supplier.Put("/:id", h.Update)
 	supplier.Delete("/:id", h.Delete)
+	supplier.Post("/:id/price-list", h.ApplyPriceListToOpenBOQs)
 }
 
 func (h *SupplierHandler) Create(c *fiber.Ctx) error {
@@ -163,3 +166,41 @@ func (h *SupplierHandler) Delete(c *fiber.Ctx) error {
 		"message": "Supplier deleted successfully",
 	})
 }
+
+func (h *SupplierHandler) ApplyPriceListToOpenBOQs(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid supplier ID",
+		})
+	}
+
+	var req requests.SupplierPriceListRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	result, err := h.supplierUsecase.ApplyPriceListToOpenBOQs(c.Context(), id, req)
+	if err != nil {
+		if err.Error() == "supplier not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Supplier not found",
+			})
+		}
+		if errors.Is(err, repositories.ErrInvalidInput) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Price list applied successfully",
+		"data":    result,
+	})
+}
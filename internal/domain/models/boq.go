@@ -2,6 +2,8 @@ package models
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,28 +13,73 @@ type BOQStatus string
 const (
 	BOQStatusDraft    BOQStatus = "draft"
 	BOQStatusApproved BOQStatus = "approved"
+	BOQStatusLocked   BOQStatus = "locked"
 )
 
+// boqStatusTransitions lists the statuses each BOQ status may move to. An
+// approved BOQ can be reopened as a draft until it is locked.
+var boqStatusTransitions = map[BOQStatus][]BOQStatus{
+	BOQStatusDraft:    {BOQStatusApproved},
+	BOQStatusApproved: {BOQStatusDraft, BOQStatusLocked},
+}
+
+// StatusTransitionError is returned when a BOQ status change is not allowed.
+type StatusTransitionError struct {
+	From BOQStatus
+	To   BOQStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot change BOQ status from %s to %s", e.From, e.To)
+}
+
+// CanTransitionTo returns a *StatusTransitionError unless target is a valid
+// next status.
+func (s BOQStatus) CanTransitionTo(target BOQStatus) error {
+	for _, next := range boqStatusTransitions[s] {
+		if next == target {
+			return nil
+		}
+	}
+	return &StatusTransitionError{From: s, To: target}
+}
+
+// IsEditable reports whether jobs and prices may be changed in this status.
+func (s BOQStatus) IsEditable() bool {
+	return s == BOQStatusDraft
+}
+
 type BOQ struct {
-	BOQID              uuid.UUID       `db:"boq_id"`
-	ProjectID          uuid.UUID       `db:"project_id"`
-	Status             BOQStatus       `db:"status"`
-	SellingGeneralCost sql.NullFloat64 `db:"selling_general_cost"`
+	BOQID              uuid.UUID `db:"boq_id"`
+	ProjectID          uuid.UUID `db:"project_id"`
+	Status             BOQStatus `db:"status"`
+	SellingGeneralCost NullMoney `db:"selling_general_cost"`
+	TotalCost          NullMoney `db:"total_cost"`
+	Currency           string    `db:"currency"`
+	// PreliminariesPercent is applied to the direct-works subtotal when set.
+	PreliminariesPercent sql.NullFloat64 `db:"preliminaries_percent"`
+	// Version is incremented by every edit of the BOQ or its lines.
+	Version   int       `db:"version"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
 
+// DefaultCurrency is the currency of a BOQ or price that does not set one.
+const DefaultCurrency = "THB"
+
 type BOQDetails struct {
 	ProjectName         string          `db:"name"`
 	ProjectAddress      sql.NullString  `db:"address"`
 	JobID               uuid.UUID       `db:"job_id"`
 	JobName             string          `db:"job_name"`
 	Description         sql.NullString  `db:"description"`
-	Quantity            int             `db:"quantity"`
+	Quantity            sql.NullFloat64 `db:"quantity"`
 	Unit                string          `db:"unit"`
-	LaborCost           float64         `db:"labor_cost"`
-	EstimatedPrice      sql.NullFloat64 `db:"estimated_price"`
-	TotalEstimatedPrice sql.NullFloat64 `db:"total_estimated_price"`
-	TotalLaborCost      float64         `db:"total_labour_cost"`
-	Total               sql.NullFloat64 `db:"total"`
+	LaborCost           NullMoney       `db:"labor_cost"`
+	EstimatedPrice      NullMoney       `db:"estimated_price"`
+	TotalEstimatedPrice NullMoney       `db:"total_estimated_price"`
+	TotalLaborCost      Money           `db:"total_labour_cost"`
+	Total               NullMoney       `db:"total"`
 }
 
 type BOQMaterialDetails struct {
@@ -41,12 +88,12 @@ type BOQMaterialDetails struct {
 	MaterialName   string          `db:"material_name"`
 	Quantity       sql.NullFloat64 `db:"quantity"` // Changed to handle NULL
 	Unit           string          `db:"unit"`
-	EstimatedPrice sql.NullFloat64 `db:"estimated_price"` // Changed to handle NULL
-	Total          sql.NullFloat64 `db:"total"`           // Changed to handle NULL
+	EstimatedPrice NullMoney       `db:"estimated_price"` // Changed to handle NULL
+	Total          NullMoney       `db:"total"`           // Changed to handle NULL
 }
 
 type BOQGeneralCost struct {
 	BOQID         uuid.UUID `db:"boq_id"`
 	TypeName      string    `db:"type_name"`
-	EstimatedCost float64   `db:"estimated_cost"`
+	EstimatedCost Money     `db:"estimated_cost"`
 }
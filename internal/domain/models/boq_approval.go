@@ -0,0 +1,27 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BOQApprovalStatus string
+
+const (
+	BOQApprovalPending  BOQApprovalStatus = "pending"
+	BOQApprovalApproved BOQApprovalStatus = "approved"
+	BOQApprovalRejected BOQApprovalStatus = "rejected"
+)
+
+type BOQApprovalRequest struct {
+	ApprovalID  uuid.UUID         `db:"approval_id"`
+	BOQID       uuid.UUID         `db:"boq_id"`
+	ApproverID  uuid.UUID         `db:"approver_id"`
+	Step        int               `db:"step"`
+	Status      BOQApprovalStatus `db:"status"`
+	Comment     sql.NullString    `db:"comment"`
+	SubmittedAt time.Time         `db:"submitted_at"`
+	DecidedAt   sql.NullTime      `db:"decided_at"`
+}
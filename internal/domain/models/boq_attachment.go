@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BOQAttachmentType string
+
+const (
+	BOQAttachmentTypeQuote   BOQAttachmentType = "quote"
+	BOQAttachmentTypeDrawing BOQAttachmentType = "drawing"
+	BOQAttachmentTypeOther   BOQAttachmentType = "other"
+)
+
+func (t BOQAttachmentType) IsValid() bool {
+	switch t {
+	case BOQAttachmentTypeQuote, BOQAttachmentTypeDrawing, BOQAttachmentTypeOther:
+		return true
+	}
+	return false
+}
+
+type BOQAttachment struct {
+	AttachmentID   uuid.UUID         `db:"attachment_id"`
+	BOQID          uuid.UUID         `db:"boq_id"`
+	JobID          uuid.NullUUID     `db:"job_id"`
+	AttachmentType BOQAttachmentType `db:"attachment_type"`
+	Reference      string            `db:"reference"`
+	CreatedAt      time.Time         `db:"created_at"`
+}
@@ -1,13 +1,29 @@
 package models
 
 import (
+	"database/sql"
+
 	"github.com/google/uuid"
 )
 
+// BOQJob is a job line on a BOQ. Quantity and LaborCost are NULL until the
+// estimator fills them in.
 type BOQJob struct {
-	BOQID        uuid.UUID `db:"boq_id"`
-	JobID        uuid.UUID `db:"job_id"`
-	Quantity     int       `db:"quantity"`
-	LaborCost    float64   `db:"labor_cost"`
-	SellingPrice float64   `db:"selling_price"`
+	BOQID           uuid.UUID       `db:"boq_id"`
+	JobID           uuid.UUID       `db:"job_id"`
+	Quantity        sql.NullFloat64 `db:"quantity"`
+	LaborCost       NullMoney       `db:"labor_cost"`
+	SellingPrice    float64         `db:"selling_price"`
+	StartOffsetDays sql.NullInt32   `db:"start_offset_days"`
+	DurationDays    sql.NullInt32   `db:"duration_days"`
+	IsProvisional   bool            `db:"is_provisional"`
 }
+
+// CashFlowUnscheduledMode controls how jobs without a planned schedule are
+// placed on a cash-flow curve.
+type CashFlowUnscheduledMode string
+
+const (
+	CashFlowUnscheduledSpread CashFlowUnscheduledMode = "spread"
+	CashFlowUnscheduledLump   CashFlowUnscheduledMode = "lump"
+)
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BOQMilestone struct {
+	MilestoneID uuid.UUID       `db:"milestone_id"`
+	BOQID       uuid.UUID       `db:"boq_id"`
+	Name        string          `db:"name"`
+	Percentage  sql.NullFloat64 `db:"percentage"`
+	FixedAmount NullMoney       `db:"fixed_amount"`
+	TriggerDate time.Time       `db:"trigger_date"`
+}
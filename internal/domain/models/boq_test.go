@@ -0,0 +1,43 @@
+package models_test
+
+import (
+	"boonkosang/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBOQStatusCanTransitionTo(t *testing.T) {
+	testCases := []struct {
+		name    string
+		from    models.BOQStatus
+		to      models.BOQStatus
+		allowed bool
+	}{
+		{name: "Draft to approved", from: models.BOQStatusDraft, to: models.BOQStatusApproved, allowed: true},
+		{name: "Approved back to draft", from: models.BOQStatusApproved, to: models.BOQStatusDraft, allowed: true},
+		{name: "Approved to locked", from: models.BOQStatusApproved, to: models.BOQStatusLocked, allowed: true},
+		{name: "Draft straight to locked", from: models.BOQStatusDraft, to: models.BOQStatusLocked},
+		{name: "Draft to draft", from: models.BOQStatusDraft, to: models.BOQStatusDraft},
+		{name: "Approved to approved", from: models.BOQStatusApproved, to: models.BOQStatusApproved},
+		{name: "Locked to draft", from: models.BOQStatusLocked, to: models.BOQStatusDraft},
+		{name: "Locked to approved", from: models.BOQStatusLocked, to: models.BOQStatusApproved},
+		{name: "Unknown status", from: models.BOQStatusDraft, to: models.BOQStatus("archived")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.from.CanTransitionTo(tc.to)
+			if tc.allowed {
+				assert.NoError(t, err)
+				return
+			}
+
+			var transitionErr *models.StatusTransitionError
+			require.ErrorAs(t, err, &transitionErr)
+			assert.Equal(t, tc.from, transitionErr.From)
+			assert.Equal(t, tc.to, transitionErr.To)
+		})
+	}
+}
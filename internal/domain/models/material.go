@@ -19,3 +19,15 @@ type MaterialPriceInfo struct {
 	SupplierID     sql.NullString  `db:"supplier_id"`
 	SupplierName   sql.NullString  `db:"supplier_name"`
 }
+
+type MaterialVolatility struct {
+	MaterialID   string  `db:"material_id"`
+	Name         string  `db:"name"`
+	Unit         string  `db:"unit"`
+	Observations int     `db:"observations"`
+	BOQCount     int     `db:"boq_count"`
+	MinPrice     float64 `db:"min_price"`
+	MaxPrice     float64 `db:"max_price"`
+	AvgPrice     float64 `db:"avg_price"`
+	StdDev       float64 `db:"std_dev"`
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// moneyScale is the number of minor units (satang) in one baht.
+const moneyScale = 100
+
+// Money is an amount in minor units. Amounts are added as integers, so
+// totals of many line items do not pick up float rounding errors. It scans
+// from numeric columns and marshals to JSON as a plain number with two
+// decimal places.
+type Money int64
+
+// MoneyFromFloat rounds f to the nearest minor unit.
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * moneyScale))
+}
+
+// ParseMoney parses a decimal string such as "1234.5" exactly, rounding half
+// away from zero beyond two decimal places.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimLeft(s, "+-")
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid money amount %q", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid money amount %q", s)
+	}
+
+	if strings.Trim(frac, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid money amount %q", s)
+	}
+
+	frac += "000"
+	minor, _ := strconv.ParseInt(frac[:2], 10, 64)
+	if frac[2] >= '5' {
+		minor++
+	}
+
+	m := Money(units*moneyScale + minor)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// Float64 returns the amount in baht, for code that has not moved to Money.
+func (m Money) Float64() float64 {
+	return float64(m) / moneyScale
+}
+
+// MulQuantity returns the amount for quantity units priced at m, rounded to
+// the nearest minor unit.
+func (m Money) MulQuantity(quantity float64) Money {
+	return Money(math.Round(float64(m) * quantity))
+}
+
+// Percent returns percent of m, rounded to the nearest minor unit.
+func (m Money) Percent(percent float64) Money {
+	return Money(math.Round(float64(m) * percent / 100))
+}
+
+func (m Money) String() string {
+	sign := ""
+	units := int64(m)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/moneyScale, units%moneyScale)
+}
+
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case float64:
+		*m = MoneyFromFloat(v)
+	case int64:
+		*m = Money(v * moneyScale)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON leaves m unchanged for a JSON null, as encoding/json does for
+// plain numbers.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	parsed, err := ParseMoney(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// NullMoney is a Money that may be NULL in the database.
+type NullMoney struct {
+	Money Money
+	Valid bool
+}
+
+func (n *NullMoney) Scan(src interface{}) error {
+	if src == nil {
+		n.Money, n.Valid = 0, false
+		return nil
+	}
+	n.Valid = true
+	return n.Money.Scan(src)
+}
+
+func (n NullMoney) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Money.Value()
+}
+
+func (n NullMoney) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.Money.MarshalJSON()
+}
+
+func (n *NullMoney) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		n.Money, n.Valid = 0, false
+		return nil
+	}
+	if err := n.Money.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"boonkosang/internal/domain/models"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoneyUnmarshalJSONNull(t *testing.T) {
+	var body struct {
+		Amount models.Money     `json:"amount"`
+		Price  models.NullMoney `json:"price"`
+	}
+	body.Amount = models.MoneyFromFloat(12.5)
+	body.Price = models.NullMoney{Money: models.MoneyFromFloat(3), Valid: true}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": null, "price": null}`), &body))
+	assert.Equal(t, models.MoneyFromFloat(12.5), body.Amount)
+	assert.False(t, body.Price.Valid)
+	assert.Zero(t, body.Price.Money)
+}
+
+func TestNullMoneyJSONRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		json string
+		want models.NullMoney
+	}{
+		{name: "Number", json: `1234.56`, want: models.NullMoney{Money: 123456, Valid: true}},
+		{name: "Quoted number", json: `"0.10"`, want: models.NullMoney{Money: 10, Valid: true}},
+		{name: "Null", json: `null`, want: models.NullMoney{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got models.NullMoney
+			require.NoError(t, json.Unmarshal([]byte(tc.json), &got))
+			assert.Equal(t, tc.want, got)
+
+			encoded, err := json.Marshal(got)
+			require.NoError(t, err)
+			var again models.NullMoney
+			require.NoError(t, json.Unmarshal(encoded, &again))
+			assert.Equal(t, tc.want, again)
+		})
+	}
+}
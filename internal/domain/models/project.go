@@ -26,6 +26,10 @@ type Project struct {
 	ClientID    uuid.UUID       `db:"client_id"`
 	CreatedAt   time.Time       `db:"created_at"`
 	UpdatedAt   sql.NullTime    `db:"updated_at"`
+	ProjectType sql.NullString  `db:"project_type"`
+	GFA         sql.NullFloat64 `db:"gross_floor_area"`
+	// DefaultGeneralCost seeds selling_general_cost of the project's new BOQs.
+	DefaultGeneralCost sql.NullFloat64 `db:"default_general_cost"`
 }
 
 type ProjectStatusCheck struct {
@@ -47,3 +51,19 @@ type ProjectSummary struct {
 	ProjectOverview
 	Jobs []JobSummary
 }
+
+// ProjectBenchmark holds the BOQ ratios of a completed project. Shares are
+// fractions of the total cost.
+type ProjectBenchmark struct {
+	ProjectID     uuid.UUID       `db:"project_id"`
+	BOQID         uuid.UUID       `db:"boq_id"`
+	ProjectType   sql.NullString  `db:"project_type"`
+	GFA           sql.NullFloat64 `db:"gross_floor_area"`
+	TotalCost     float64         `db:"total_cost"`
+	CostPerArea   sql.NullFloat64 `db:"cost_per_area"`
+	LaborShare    float64         `db:"labor_share"`
+	MaterialShare float64         `db:"material_share"`
+	OverheadShare float64         `db:"overhead_share"`
+	JobCount      int             `db:"job_count"`
+	RecordedAt    time.Time       `db:"recorded_at"`
+}
@@ -7,9 +7,10 @@ import (
 )
 
 type Supplier struct {
-	SupplierID uuid.UUID       `db:"supplier_id"`
-	Name       string          `db:"name"`
-	Email      string          `db:"email"`
-	Tel        string          `db:"tel"`
-	Address    json.RawMessage `db:"address"`
+	SupplierID  uuid.UUID       `db:"supplier_id"`
+	Name        string          `db:"name"`
+	Email       string          `db:"email"`
+	Tel         string          `db:"tel"`
+	Address     json.RawMessage `db:"address"`
+	IsPreferred bool            `db:"is_preferred"`
 }
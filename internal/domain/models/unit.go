@@ -0,0 +1,58 @@
+package models
+
+import "strings"
+
+// CanonicalUnits is the set of unit strings stored on jobs and materials.
+var CanonicalUnits = map[string]bool{
+	"m":     true,
+	"m2":    true,
+	"m3":    true,
+	"kg":    true,
+	"ton":   true,
+	"l":     true,
+	"pcs":   true,
+	"set":   true,
+	"lot":   true,
+	"point": true,
+	"bag":   true,
+	"sheet": true,
+	"day":   true,
+}
+
+// unitAliases maps common spellings (English and Thai) onto a canonical unit.
+var unitAliases = map[string]string{
+	"meter": "m", "meters": "m", "metre": "m", "เมตร": "m", "ม.": "m",
+	"m²": "m2", "sqm": "m2", "sq.m": "m2", "sq.m.": "m2", "ตร.ม.": "m2", "ตารางเมตร": "m2",
+	"m³": "m3", "cum": "m3", "cu.m": "m3", "cu.m.": "m3", "ลบ.ม.": "m3", "ลูกบาศก์เมตร": "m3",
+	"kgs": "kg", "kilogram": "kg", "กก.": "kg", "กิโลกรัม": "kg",
+	"tons": "ton", "tonne": "ton", "ตัน": "ton",
+	"liter": "l", "litre": "l", "ลิตร": "l",
+	"pc": "pcs", "piece": "pcs", "pieces": "pcs", "ea": "pcs", "ชิ้น": "pcs", "อัน": "pcs", "ตัว": "pcs",
+	"sets": "set", "ชุด": "set",
+	"ls": "lot", "lump sum": "lot", "เหมา": "lot",
+	"points": "point", "จุด": "point",
+	"bags": "bag", "ถุง": "bag",
+	"sheets": "sheet", "แผ่น": "sheet",
+	"days": "day", "วัน": "day",
+}
+
+// NormalizeUnit returns the canonical form of unit and whether it could be
+// mapped onto the canonical set.
+func NormalizeUnit(unit string) (string, bool) {
+	key := strings.ToLower(strings.TrimSpace(unit))
+	if CanonicalUnits[key] {
+		return key, true
+	}
+	if canonical, ok := unitAliases[key]; ok {
+		return canonical, true
+	}
+	if canonical, ok := unitAliases[strings.ReplaceAll(key, " ", "")]; ok {
+		return canonical, true
+	}
+	return unit, false
+}
+
+// IsCanonicalUnit reports whether unit is stored exactly in canonical form.
+func IsCanonicalUnit(unit string) bool {
+	return CanonicalUnits[unit]
+}
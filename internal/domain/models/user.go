@@ -15,4 +15,8 @@ type User struct {
 	Email     sql.NullString `db:"email"`
 	Tel       sql.NullString `db:"tel"`
 	CompanyID *uuid.UUID     `db:"company_id"`
+	Role      string         `db:"role"`
 }
+
+// UserRoleAdmin is the role allowed to override BOQ guardrails.
+const UserRoleAdmin = "admin"
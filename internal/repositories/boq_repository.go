@@ -5,6 +5,8 @@ import (
 	"boonkosang/internal/requests"
 	"boonkosang/internal/responses"
 	"context"
+	"io"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,13 +14,82 @@ import (
 type BOQRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.BOQ, error)
 	GetByProjectID(ctx context.Context, projectID uuid.UUID) (*models.BOQ, error)
-	Approve(ctx context.Context, boqID uuid.UUID) error
+	Approve(ctx context.Context, boqID uuid.UUID, req requests.ApproveBOQRequest, expectedVersion int) error
 	GetBoqWithProject(ctx context.Context, projectID uuid.UUID) (*responses.BOQResponse, error)
-	AddBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest) error
-	UpdateBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest) error
-	DeleteBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID) error
+	AddBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) error
+	UpdateBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) error
+	DeleteBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error
 
 	GetBOQGeneralCosts(ctx context.Context, boqID uuid.UUID) ([]models.BOQGeneralCost, error)
 	GetBOQDetails(ctx context.Context, projectID uuid.UUID) ([]models.BOQDetails, error)
 	GetBOQMaterialDetails(ctx context.Context, projectID uuid.UUID) ([]models.BOQMaterialDetails, error)
+
+	GetRequiredJobs(ctx context.Context, projectType string) ([]responses.RequiredJobResponse, error)
+	AddRequiredJob(ctx context.Context, projectType string, jobID uuid.UUID) error
+	RemoveRequiredJob(ctx context.Context, projectType string, jobID uuid.UUID) error
+	ValidateRequiredJobs(ctx context.Context, boqID uuid.UUID) (*responses.RequiredJobCheckResponse, error)
+
+	GetPreferredSupplierShare(ctx context.Context, boqID uuid.UUID) (*responses.PreferredSupplierShareResponse, error)
+	GetJobProfitability(ctx context.Context, boqID uuid.UUID) (*responses.BOQProfitabilityResponse, error)
+	SetJobSellingPrice(ctx context.Context, boqID uuid.UUID, req requests.JobSellingPrice, expectedVersion int) error
+	RecalculateBOQTotal(ctx context.Context, boqID uuid.UUID) (models.Money, error)
+	RecalculateProjectBOQTotals(ctx context.Context, projectID uuid.UUID) (*responses.RecalculateTotalsResponse, error)
+	GetHighLaborShareBOQs(ctx context.Context, threshold float64) ([]responses.BOQLaborShareResponse, error)
+	AddBOQAttachment(ctx context.Context, boqID uuid.UUID, req requests.BOQAttachmentRequest) (*responses.BOQAttachmentResponse, error)
+	RemoveBOQAttachment(ctx context.Context, boqID uuid.UUID, attachmentID uuid.UUID) error
+	ListBOQAttachments(ctx context.Context, boqID uuid.UUID) ([]responses.BOQAttachmentResponse, error)
+	SetBOQJobSchedule(ctx context.Context, boqID uuid.UUID, req requests.BOQJobScheduleRequest) error
+	GetBOQCashFlowCurve(ctx context.Context, boqID uuid.UUID, req requests.CashFlowCurveRequest) (*responses.CashFlowCurveResponse, error)
+	GetNonCanonicalUnits(ctx context.Context) ([]responses.NonCanonicalUnitResponse, error)
+	NormalizeUnits(ctx context.Context) (*responses.NormalizeUnitsResponse, error)
+	SetMaterialPriceCurrency(ctx context.Context, boqID uuid.UUID, req requests.MaterialPriceCurrencyRequest) error
+	GetFXSensitivity(ctx context.Context, boqID uuid.UUID, rates map[string]float64) (*responses.FXSensitivityResponse, error)
+	GetOversizedBOQs(ctx context.Context, limit int) ([]responses.BOQJobCountResponse, error)
+	AddBOQMilestone(ctx context.Context, boqID uuid.UUID, req requests.BOQMilestoneRequest) (*responses.BOQMilestoneResponse, error)
+	RemoveBOQMilestone(ctx context.Context, boqID uuid.UUID, milestoneID uuid.UUID) error
+	GetInvoiceSchedule(ctx context.Context, boqID uuid.UUID) (*responses.InvoiceScheduleResponse, error)
+	GetOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error)
+	CleanOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error)
+	PruneOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) (int, error)
+	GetEstimateVsActual(ctx context.Context, boqID uuid.UUID, req requests.EstimateVsActualRequest) (*responses.EstimateVsActualResponse, error)
+	GetDirectCostFloor(ctx context.Context, boqID uuid.UUID) (*responses.BOQDirectCostFloorResponse, error)
+	SubmitForApproval(ctx context.Context, boqID uuid.UUID, req requests.SubmitBOQApprovalRequest) error
+	DecideApproval(ctx context.Context, boqID uuid.UUID, userID uuid.UUID, req requests.BOQApprovalDecisionRequest) error
+	GetPendingApprovalsForUser(ctx context.Context, userID uuid.UUID) ([]responses.PendingApprovalResponse, error)
+	PreviewRateRounding(ctx context.Context, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error)
+	ApplyRateRounding(ctx context.Context, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error)
+	GetLinesExceedingMarkupCap(ctx context.Context, boqID uuid.UUID, maxMarkupPercent float64) ([]responses.JobProfitabilityResponse, error)
+	GetSupplierMaterialRollup(ctx context.Context, boqID uuid.UUID) ([]responses.SupplierMaterialRollupResponse, error)
+	GetCostCompositionTrend(ctx context.Context, from, to time.Time, bucket string) ([]responses.CostCompositionPointResponse, error)
+	SetJobProvisional(ctx context.Context, boqID uuid.UUID, req requests.JobProvisionalRequest, expectedVersion int) error
+	GetProvisionalShare(ctx context.Context, boqID uuid.UUID) (*responses.ProvisionalShareResponse, error)
+	CompareBOQs(ctx context.Context, aID, bID uuid.UUID) (*responses.BOQDiffResponse, error)
+	CloneBOQScaled(ctx context.Context, sourceBOQID, targetProjectID uuid.UUID, factor float64, resetPrices bool) (uuid.UUID, error)
+	ValidateBOQForExport(ctx context.Context, boqID uuid.UUID) (*responses.BOQExportValidationResponse, error)
+	AddBOQJobs(ctx context.Context, boqID uuid.UUID, reqs []requests.BOQJobRequest, expectedVersion int) (*responses.BOQJobsImportResponse, error)
+	GetMixedCurrencyLines(ctx context.Context, boqID uuid.UUID) ([]responses.MixedCurrencyLineResponse, error)
+	SetPreliminariesPercent(ctx context.Context, boqID uuid.UUID, req requests.PreliminariesRequest) error
+	GetPreliminaries(ctx context.Context, boqID uuid.UUID) (*responses.PreliminariesResponse, error)
+	GetBOQLifecycle(ctx context.Context, boqID uuid.UUID) (*responses.BOQLifecycleResponse, error)
+	UpdateBOQStatus(ctx context.Context, boqID uuid.UUID, newStatus models.BOQStatus, expectedVersion int) error
+	UpdateMaterialPrice(ctx context.Context, boqID, jobID uuid.UUID, materialID string, price models.Money, expectedVersion int) error
+	GetMaterialPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.MaterialPriceLogResponse, error)
+	GetBOQSummary(ctx context.Context, boqID uuid.UUID) (*responses.BOQSummaryResponse, error)
+	RestoreBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error
+	PurgeBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error
+	ClearBOQJobs(ctx context.Context, boqID uuid.UUID, expectedVersion int) (int, error)
+	CreateBOQForProject(ctx context.Context, projectID uuid.UUID, opts requests.CreateBOQOptions) (*models.BOQ, error)
+	ExportBOQ(ctx context.Context, boqID uuid.UUID) ([]byte, error)
+	ExportBOQStream(ctx context.Context, boqID uuid.UUID, w io.Writer) error
+	ListBOQJobs(ctx context.Context, boqID uuid.UUID, req requests.ListBOQJobsRequest) (*responses.BOQJobListResponse, error)
+	CloneBOQ(ctx context.Context, sourceBOQID, targetProjectID uuid.UUID, overwrite bool) (uuid.UUID, error)
+	UpdateBOQJobQuantity(ctx context.Context, boqID, jobID uuid.UUID, quantity float64, laborCost float64, expectedVersion int) error
+	LockBOQ(ctx context.Context, boqID uuid.UUID) error
+	GetBOQMaterialTotals(ctx context.Context, boqID uuid.UUID) ([]responses.MaterialTotalResponse, error)
+	Ping(ctx context.Context) error
+	ListBOQs(ctx context.Context, req requests.ListBOQRequest) ([]responses.BOQListItemResponse, error)
+	ApplyGeneralCostPercentage(ctx context.Context, boqID uuid.UUID, percent float64) (float64, error)
+	MoveBOQJob(ctx context.Context, fromBOQID, toBOQID, jobID uuid.UUID) error
+	GetBOQAudit(ctx context.Context, boqID uuid.UUID) ([]responses.BOQAuditEntry, error)
+	AddBOQJobResult(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) (*responses.BOQJobResponse, error)
 }
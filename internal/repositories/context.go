@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const boqSizeOverrideKey contextKey = "boq_size_override"
+
+// WithBOQSizeOverride marks ctx as allowed to add jobs beyond the configured
+// per-BOQ job limit. It is meant for administrative imports only.
+func WithBOQSizeOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, boqSizeOverrideKey, true)
+}
+
+// BOQSizeOverride reports whether ctx carries the job limit override.
+func BOQSizeOverride(ctx context.Context) bool {
+	override, _ := ctx.Value(boqSizeOverrideKey).(bool)
+	return override
+}
+
+const actorKey contextKey = "actor"
+
+// WithActor records the user performing a mutation so it can be audited.
+func WithActor(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorKey, userID)
+}
+
+// ActorFromContext returns the user set by WithActor, if any.
+func ActorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Value(actorKey).(uuid.UUID)
+	return userID, ok
+}
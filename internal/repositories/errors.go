@@ -0,0 +1,34 @@
+package repositories
+
+import "errors"
+
+var (
+	ErrBOQTooLarge           = errors.New("BOQ exceeds the maximum number of jobs")
+	ErrProvisionalExceedsCap = errors.New("provisional sums exceed the allowed share of the BOQ total")
+	ErrBOQNotExportable      = errors.New("BOQ has consistency issues that block export")
+	ErrMixedCurrency         = errors.New("BOQ has prices in another currency without a conversion")
+	ErrJobAlreadyInBOQ       = errors.New("job already exists in this BOQ")
+	ErrJobNotFound           = errors.New("job not found")
+	ErrJobNotInBOQ           = errors.New("job not found in BOQ")
+	ErrMaterialNotInBOQ      = errors.New("material not found in BOQ job")
+	ErrBOQNotFound           = errors.New("boq not found")
+	ErrProjectNotFound       = errors.New("project not found")
+	ErrProjectClosed         = errors.New("project is completed or cancelled")
+	ErrStaleBOQ              = errors.New("BOQ was modified by someone else")
+	ErrQueryTimeout          = errors.New("database query timed out")
+	ErrBOQLocked             = errors.New("BOQ is locked")
+	ErrBOQNotLockable        = errors.New("BOQ is not ready to be locked")
+	ErrBOQNotDraft           = errors.New("BOQ is not in draft status")
+	ErrBOQExists             = errors.New("project already has a BOQ")
+	ErrInvalidInput          = errors.New("invalid input")
+	ErrAttachmentNotFound    = errors.New("attachment not found")
+	ErrMilestoneNotFound     = errors.New("milestone not found")
+	ErrNoPriceLogs           = errors.New("no material price records found to update")
+	ErrNoPendingApproval     = errors.New("no pending approval for this BOQ")
+	ErrNotAwaitingApprover   = errors.New("BOQ is not awaiting this user's approval")
+	ErrApprovalRejected      = errors.New("approval chain has been rejected")
+	ErrMissingRequiredJobs   = errors.New("BOQ is missing required jobs")
+	ErrMarkupCapExceeded     = errors.New("BOQ lines exceed the markup cap")
+	ErrNonCanonicalUnit      = errors.New("job unit is not a canonical unit")
+	ErrMilestonesUnbalanced  = errors.New("milestones do not add up to the BOQ grand total")
+)
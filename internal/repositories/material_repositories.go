@@ -4,6 +4,7 @@ import (
 	"boonkosang/internal/domain/models"
 	"boonkosang/internal/requests"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -21,4 +22,5 @@ type MaterialRepository interface {
 	UpdateActualPrice(ctx context.Context, boqID uuid.UUID, req requests.UpdateMaterialActualPriceRequest) error
 	GetProjectStatus(ctx context.Context, projectID uuid.UUID) (string, error)
 	GetQuotationStatus(ctx context.Context, projectID uuid.UUID) (string, error)
+	GetMaterialVolatility(ctx context.Context, from, to time.Time, limit int) ([]models.MaterialVolatility, error)
 }
@@ -126,3 +126,12 @@ func (m *MockProjectRepository) GetProjectSummary(ctx context.Context, projectID
 	}
 	return args.Get(0).(*models.ProjectSummary), args.Error(1)
 }
+
+// GetBenchmarks mocks the GetBenchmarks method
+func (m *MockProjectRepository) GetBenchmarks(ctx context.Context, projectType string) ([]models.ProjectBenchmark, error) {
+	args := m.Called(ctx, projectType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ProjectBenchmark), args.Error(1)
+}
@@ -26,4 +26,5 @@ type ProjectRepository interface {
 
 	ValidateProjectStatus(ctx context.Context, projectID uuid.UUID) error
 	GetProjectSummary(ctx context.Context, projectID uuid.UUID) (*models.ProjectSummary, error)
+	GetBenchmarks(ctx context.Context, projectType string) ([]models.ProjectBenchmark, error)
 }
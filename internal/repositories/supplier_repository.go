@@ -3,6 +3,7 @@ package repositories
 import (
 	"boonkosang/internal/domain/models"
 	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
 	"context"
 
 	"github.com/google/uuid"
@@ -15,4 +16,5 @@ type SupplierRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Supplier, error)
 	List(ctx context.Context, limit, offset int) ([]models.Supplier, int64, error)
 	GetByEmail(ctx context.Context, email string) (*models.Supplier, error)
+	ApplyPriceListToOpenBOQs(ctx context.Context, supplierID uuid.UUID, req requests.SupplierPriceListRequest) (*responses.PriceListApplyResponse, error)
 }
@@ -1,6 +1,9 @@
 package requests
 
 import (
+	"boonkosang/internal/domain/models"
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -8,6 +11,25 @@ type CreateBOQRequest struct {
 	ProjectID uuid.UUID `json:"project_id" validate:"required"`
 }
 
+// CreateBOQOptions seeds a new BOQ. The zero value creates a draft with the
+// project's default general cost.
+type CreateBOQOptions struct {
+	Status             models.BOQStatus `json:"status"`
+	SellingGeneralCost *float64         `json:"selling_general_cost"`
+}
+
+// Validate reports every invalid field of the options as a *ValidationError.
+func (o CreateBOQOptions) Validate() error {
+	var verr ValidationError
+	if o.Status != "" && o.Status != models.BOQStatusDraft {
+		verr.add("status", "must be draft")
+	}
+	if o.SellingGeneralCost != nil && *o.SellingGeneralCost < 0 {
+		verr.add("selling_general_cost", "must not be negative")
+	}
+	return verr.err()
+}
+
 type UpdateBOQRequest struct {
 	Status             string  `json:"status" validate:"required,oneof=draft approved"`
 	SellingGeneralCost float64 `json:"selling_general_cost" validate:"required"`
@@ -15,5 +37,155 @@ type UpdateBOQRequest struct {
 type BOQJobRequest struct {
 	JobID     uuid.UUID `json:"job_id" validate:"required"`
 	Quantity  float64   `json:"quantity" validate:"required,gt=0"`
-	LaborCost float64   `json:"labor_cost" validate:"required,gt=0"`
+	LaborCost float64   `json:"labor_cost" validate:"gte=0"`
+	// Upsert updates quantity and labor cost when the job is already in the
+	// BOQ instead of failing with ErrJobAlreadyInBOQ.
+	Upsert bool `json:"upsert"`
+}
+
+// Validate reports every invalid field of the request as a *ValidationError.
+func (r BOQJobRequest) Validate() error {
+	var verr ValidationError
+	if r.JobID == uuid.Nil {
+		verr.add("job_id", "is required")
+	}
+	if r.Quantity <= 0 {
+		verr.add("quantity", "must be greater than zero")
+	}
+	if r.LaborCost < 0 {
+		verr.add("labor_cost", "must not be negative")
+	}
+	return verr.err()
+}
+
+type ApproveBOQRequest struct {
+	EnforceRequiredJobs   bool     `json:"enforce_required_jobs"`
+	MaxMarkupPercent      *float64 `json:"max_markup_percentage"`
+	MaxProvisionalPercent *float64 `json:"max_provisional_percentage"`
+}
+
+type UpdateBOQStatusRequest struct {
+	Status models.BOQStatus `json:"status" validate:"required"`
+}
+
+type MaterialPriceRequest struct {
+	JobID      uuid.UUID `json:"job_id" validate:"required"`
+	MaterialID string    `json:"material_id" validate:"required"`
+	Price      float64   `json:"price" validate:"gte=0"`
+}
+
+type JobProvisionalRequest struct {
+	JobID         uuid.UUID `json:"job_id" validate:"required"`
+	IsProvisional bool      `json:"is_provisional"`
+}
+
+// PreliminariesRequest sets the preliminaries percentage of a BOQ. A nil
+// percentage removes the preliminaries section.
+type PreliminariesRequest struct {
+	Percent *float64 `json:"percent"`
+}
+
+// GeneralCostPercentageRequest sets the selling general cost of a BOQ as a
+// percentage of its labor and material cost.
+type GeneralCostPercentageRequest struct {
+	Percent float64 `json:"percent" validate:"gte=0"`
+}
+
+type RequiredJobRequest struct {
+	JobID uuid.UUID `json:"job_id" validate:"required"`
+}
+
+type BOQAttachmentRequest struct {
+	JobID     *uuid.UUID               `json:"job_id"`
+	Type      models.BOQAttachmentType `json:"type" validate:"required,oneof=quote drawing other"`
+	Reference string                   `json:"reference" validate:"required"`
+}
+
+type BOQJobScheduleRequest struct {
+	JobID           uuid.UUID `json:"job_id" validate:"required"`
+	StartOffsetDays int       `json:"start_offset_days" validate:"gte=0"`
+	DurationDays    int       `json:"duration_days" validate:"required,gt=0"`
+}
+
+type CashFlowCurveRequest struct {
+	StartDate       time.Time                      `json:"start_date" validate:"required"`
+	UnscheduledMode models.CashFlowUnscheduledMode `json:"unscheduled_mode" validate:"omitempty,oneof=spread lump"`
+}
+
+type MaterialPriceCurrencyRequest struct {
+	MaterialID string  `json:"material_id" validate:"required"`
+	Currency   string  `json:"currency" validate:"required,len=3"`
+	FxRate     float64 `json:"fx_rate" validate:"required,gt=0"`
+}
+
+type FXSensitivityRequest struct {
+	Rates map[string]float64 `json:"rates" validate:"required"`
+}
+
+type BOQMilestoneRequest struct {
+	Name        string    `json:"name" validate:"required"`
+	Percentage  *float64  `json:"percentage" validate:"omitempty,gt=0,lte=100"`
+	FixedAmount *float64  `json:"fixed_amount" validate:"omitempty,gt=0"`
+	TriggerDate time.Time `json:"trigger_date" validate:"required"`
+}
+
+// EstimateVsActualRequest carries the actual costs keyed by job ID and by
+// material ID. Either map may be omitted.
+type EstimateVsActualRequest struct {
+	Jobs      map[uuid.UUID]float64 `json:"jobs"`
+	Materials map[string]float64    `json:"materials"`
+}
+
+type SubmitBOQApprovalRequest struct {
+	ApproverIDs []uuid.UUID `json:"approver_ids" validate:"required,min=1"`
+}
+
+type BOQApprovalDecisionRequest struct {
+	Approved bool   `json:"approved"`
+	Comment  string `json:"comment"`
+}
+
+type CloneBOQRequest struct {
+	TargetProjectID uuid.UUID `json:"target_project_id" validate:"required"`
+	// Overwrite replaces the target project's existing BOQ contents.
+	Overwrite bool `json:"overwrite"`
+}
+
+// MoveBOQJobRequest names the draft BOQ a job is moved to.
+type MoveBOQJobRequest struct {
+	TargetBOQID uuid.UUID `json:"target_boq_id" validate:"required"`
+}
+
+type CloneBOQScaledRequest struct {
+	TargetProjectID uuid.UUID `json:"target_project_id" validate:"required"`
+	Factor          float64   `json:"factor" validate:"required,gt=0"`
+	ResetPrices     bool      `json:"reset_prices"`
+}
+
+type BOQJobsRequest struct {
+	Jobs []BOQJobRequest `json:"jobs" validate:"required,min=1,dive"`
+}
+
+type ListBOQJobsRequest struct {
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+	Name   string `query:"name"`
+	// Sort is one of name, quantity or labor_cost; name when empty.
+	Sort string `query:"sort"`
+}
+
+// ListBOQRequest filters the BOQ listing. Zero values do not filter.
+type ListBOQRequest struct {
+	Status models.BOQStatus `query:"status"`
+	// ProjectIDs is read from a comma-separated project_ids parameter.
+	ProjectIDs     []uuid.UUID `query:"-"`
+	MinGeneralCost *float64    `query:"min_general_cost"`
+	MaxGeneralCost *float64    `query:"max_general_cost"`
+	Limit          int         `query:"limit"`
+	Offset         int         `query:"offset"`
+}
+
+type UpdateBOQJobQuantityRequest struct {
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+	LaborCost float64 `json:"labor_cost" validate:"gte=0"`
 }
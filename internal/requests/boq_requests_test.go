@@ -0,0 +1,123 @@
+package requests_test
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/requests"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBOQJobRequestValidate(t *testing.T) {
+	jobID := uuid.New()
+
+	testCases := []struct {
+		name           string
+		req            requests.BOQJobRequest
+		expectedFields []string
+	}{
+		{
+			name: "Success - Valid request",
+			req:  requests.BOQJobRequest{JobID: jobID, Quantity: 12.5, LaborCost: 450},
+		},
+		{
+			name: "Success - Zero labor cost",
+			req:  requests.BOQJobRequest{JobID: jobID, Quantity: 1, LaborCost: 0},
+		},
+		{
+			name:           "Failure - Nil job ID",
+			req:            requests.BOQJobRequest{JobID: uuid.Nil, Quantity: 1, LaborCost: 450},
+			expectedFields: []string{"job_id"},
+		},
+		{
+			name:           "Failure - Zero quantity",
+			req:            requests.BOQJobRequest{JobID: jobID, Quantity: 0, LaborCost: 450},
+			expectedFields: []string{"quantity"},
+		},
+		{
+			name:           "Failure - Negative quantity",
+			req:            requests.BOQJobRequest{JobID: jobID, Quantity: -3, LaborCost: 450},
+			expectedFields: []string{"quantity"},
+		},
+		{
+			name:           "Failure - Negative labor cost",
+			req:            requests.BOQJobRequest{JobID: jobID, Quantity: 1, LaborCost: -0.01},
+			expectedFields: []string{"labor_cost"},
+		},
+		{
+			name:           "Failure - Every field invalid",
+			req:            requests.BOQJobRequest{JobID: uuid.Nil, Quantity: -1, LaborCost: -1},
+			expectedFields: []string{"job_id", "quantity", "labor_cost"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if tc.expectedFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *requests.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+
+			fields := make([]string, len(validationErr.Fields))
+			for i, field := range validationErr.Fields {
+				fields[i] = field.Field
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+		})
+	}
+}
+
+func TestCreateBOQOptionsValidate(t *testing.T) {
+	generalCost := 1500.0
+	negative := -0.01
+
+	testCases := []struct {
+		name           string
+		opts           requests.CreateBOQOptions
+		expectedFields []string
+	}{
+		{
+			name: "Success - Zero value",
+			opts: requests.CreateBOQOptions{},
+		},
+		{
+			name: "Success - Draft with general cost",
+			opts: requests.CreateBOQOptions{Status: models.BOQStatusDraft, SellingGeneralCost: &generalCost},
+		},
+		{
+			name:           "Failure - Approved status",
+			opts:           requests.CreateBOQOptions{Status: models.BOQStatusApproved},
+			expectedFields: []string{"status"},
+		},
+		{
+			name:           "Failure - Negative general cost",
+			opts:           requests.CreateBOQOptions{SellingGeneralCost: &negative},
+			expectedFields: []string{"selling_general_cost"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.expectedFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *requests.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+
+			fields := make([]string, len(validationErr.Fields))
+			for i, field := range validationErr.Fields {
+				fields[i] = field.Field
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+		})
+	}
+}
@@ -12,6 +12,8 @@ type CreateProjectRequest struct {
 	Description string          `json:"description" validate:"required"`
 	Address     json.RawMessage `json:"address" validate:"required"`
 	ClientID    uuid.UUID       `json:"client_id" validate:"required"`
+	ProjectType string          `json:"project_type"`
+	GFA         *float64        `json:"gross_floor_area" validate:"omitempty,gt=0"`
 }
 
 type UpdateProjectRequest struct {
@@ -19,6 +21,8 @@ type UpdateProjectRequest struct {
 	Description string          `json:"description" validate:"required"`
 	Address     json.RawMessage `json:"address" validate:"required"`
 	ClientID    uuid.UUID       `json:"client_id" validate:"required"`
+	ProjectType string          `json:"project_type"`
+	GFA         *float64        `json:"gross_floor_area" validate:"omitempty,gt=0"`
 }
 
 type UpdateProjectStatusRequest struct {
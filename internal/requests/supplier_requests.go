@@ -3,15 +3,26 @@ package requests
 import "encoding/json"
 
 type CreateSupplierRequest struct {
-	Name    string          `json:"name" validate:"required"`
-	Email   string          `json:"email" validate:"required"`
-	Tel     string          `json:"tel" validate:"required"`
-	Address json.RawMessage `json:"address" validate:"required"`
+	Name        string          `json:"name" validate:"required"`
+	Email       string          `json:"email" validate:"required"`
+	Tel         string          `json:"tel" validate:"required"`
+	Address     json.RawMessage `json:"address" validate:"required"`
+	IsPreferred bool            `json:"is_preferred"`
 }
 
 type UpdateSupplierRequest struct {
-	Name    string          `json:"name" validate:"required"`
-	Email   string          `json:"email" validate:"required"`
-	Tel     string          `json:"tel" validate:"required"`
-	Address json.RawMessage `json:"address" validate:"required"`
+	Name        string          `json:"name" validate:"required"`
+	Email       string          `json:"email" validate:"required"`
+	Tel         string          `json:"tel" validate:"required"`
+	Address     json.RawMessage `json:"address" validate:"required"`
+	IsPreferred bool            `json:"is_preferred"`
+}
+
+type SupplierPriceListItem struct {
+	MaterialID string  `json:"material_id" validate:"required"`
+	Price      float64 `json:"price" validate:"gte=0"`
+}
+
+type SupplierPriceListRequest struct {
+	Items []SupplierPriceListItem `json:"items" validate:"required,dive"`
 }
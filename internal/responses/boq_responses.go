@@ -3,6 +3,7 @@ package responses
 import (
 	"boonkosang/internal/domain/models"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,8 +12,25 @@ type BOQResponse struct {
 	ID                 uuid.UUID        `json:"id"`
 	ProjectID          uuid.UUID        `json:"project_id"`
 	Status             models.BOQStatus `json:"status"`
-	SellingGeneralCost float64          `json:"selling_general_cost"`
+	SellingGeneralCost models.Money     `json:"selling_general_cost"`
+	Version            int              `json:"version"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
 	Jobs               []JobResponse    `json:"jobs"`
+	Attachments        BOQAttachments   `json:"attachments"`
+}
+
+// BOQListItemResponse is one BOQ in a listing. It carries the number of jobs
+// instead of the jobs themselves.
+type BOQListItemResponse struct {
+	ID                 uuid.UUID        `json:"id"`
+	ProjectID          uuid.UUID        `json:"project_id"`
+	Status             models.BOQStatus `json:"status"`
+	SellingGeneralCost models.Money     `json:"selling_general_cost"`
+	Version            int              `json:"version"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+	JobCount           int              `json:"job_count"`
 }
 
 type BOQListResponse struct {
@@ -21,10 +39,18 @@ type BOQListResponse struct {
 }
 
 type BOQSummaryResponse struct {
-	ProjectInfo    ProjectInfo      `json:"project_info"`
-	GeneralCosts   []GeneralCostDTO `json:"general_costs"`
-	Details        []BOQDetailDTO   `json:"jobs"`
-	SummaryMetrics SummaryMetrics   `json:"summary_metrics"`
+	ProjectInfo    ProjectInfo       `json:"project_info"`
+	GeneralCosts   []GeneralCostDTO  `json:"general_costs"`
+	Details        []BOQDetailDTO    `json:"jobs"`
+	Preliminaries  *PreliminariesDTO `json:"preliminaries,omitempty"`
+	SummaryMetrics SummaryMetrics    `json:"summary_metrics"`
+}
+
+// PreliminariesDTO is the derived preliminaries section of a BOQ.
+type PreliminariesDTO struct {
+	Percent             float64      `json:"percent"`
+	DirectWorksSubtotal models.Money `json:"direct_works_subtotal"`
+	Amount              models.Money `json:"amount"`
 }
 
 type ProjectInfo struct {
@@ -33,39 +59,482 @@ type ProjectInfo struct {
 }
 
 type GeneralCostDTO struct {
-	TypeName      string  `json:"type_name"`
-	EstimatedCost float64 `json:"estimated_cost"`
+	TypeName      string       `json:"type_name"`
+	EstimatedCost models.Money `json:"estimated_cost"`
 }
 
 type BOQDetailDTO struct {
 	JobID               uuid.UUID     `json:"job_id"`
 	JobName             string        `json:"job_name"`
 	Description         string        `json:"description"`
-	Quantity            int           `json:"quantity"`
+	Quantity            float64       `json:"quantity"`
 	Unit                string        `json:"unit"`
-	LaborCost           float64       `json:"labor_cost"`
-	EstimatedPrice      float64       `json:"estimated_price"`
-	TotalEstimatedPrice float64       `json:"total_estimated_price"`
-	TotalLaborCost      float64       `json:"total_labor_cost"`
-	Total               float64       `json:"total"`
+	LaborCost           models.Money  `json:"labor_cost"`
+	EstimatedPrice      models.Money  `json:"estimated_price"`
+	TotalEstimatedPrice models.Money  `json:"total_estimated_price"`
+	TotalLaborCost      models.Money  `json:"total_labor_cost"`
+	Total               models.Money  `json:"total"`
 	Materials           []MaterialDTO `json:"materials"`
+	// Incomplete is set when a material on the job has no price yet or the
+	// line has no quantity or labor cost.
+	Incomplete bool `json:"incomplete"`
 }
 
 type MaterialDTO struct {
-	JobID          uuid.UUID `json:"job_id"`
-	JobName        string    `json:"job_name"`
-	MaterialName   string    `json:"material_name"`
-	Quantity       float64   `json:"quantity"`
-	Unit           string    `json:"unit"`
-	EstimatedPrice float64   `json:"estimated_price"`
-	Total          float64   `json:"total"`
+	JobID          uuid.UUID    `json:"job_id"`
+	JobName        string       `json:"job_name"`
+	MaterialName   string       `json:"material_name"`
+	Quantity       float64      `json:"quantity"`
+	Unit           string       `json:"unit"`
+	EstimatedPrice models.Money `json:"estimated_price"`
+	Total          models.Money `json:"total"`
 }
 
+// SummaryMetrics are summed in minor units so they match the job lines to
+// the satang.
 type SummaryMetrics struct {
-	TotalGeneralCost    float64 `json:"total_general_cost"`
-	TotalMaterialCost   float64 `json:"total_material_cost"`
-	TotalLaborCost      float64 `json:"total_labor_cost"`
-	TotalEstimatedPrice float64 `json:"total_estimated_price"`
-	TotalAmount         float64 `json:"total_amount"`
-	GrandTotal          float64 `json:"grand_total"`
+	TotalGeneralCost    models.Money `json:"total_general_cost"`
+	TotalMaterialCost   models.Money `json:"total_material_cost"`
+	TotalLaborCost      models.Money `json:"total_labor_cost"`
+	TotalEstimatedPrice models.Money `json:"total_estimated_price"`
+	TotalAmount         models.Money `json:"total_amount"`
+	TotalPreliminaries  models.Money `json:"total_preliminaries"`
+	IncompleteJobs      int          `json:"incomplete_jobs"`
+	GrandTotal          models.Money `json:"grand_total"`
+}
+
+type RequiredJobResponse struct {
+	JobID uuid.UUID `json:"job_id" db:"job_id"`
+	Name  string    `json:"name" db:"name"`
+	Unit  string    `json:"unit" db:"unit"`
+}
+
+type RequiredJobCheckResponse struct {
+	BOQID       uuid.UUID             `json:"boq_id"`
+	ProjectType string                `json:"project_type"`
+	Complete    bool                  `json:"complete"`
+	MissingJobs []RequiredJobResponse `json:"missing_jobs"`
+}
+
+type SupplierSpendResponse struct {
+	SupplierID   uuid.UUID    `json:"supplier_id" db:"supplier_id"`
+	SupplierName string       `json:"supplier_name" db:"supplier_name"`
+	MaterialCost models.Money `json:"material_cost" db:"material_cost"`
+}
+
+type PreferredSupplierShareResponse struct {
+	BOQID             uuid.UUID               `json:"boq_id"`
+	TotalMaterialCost models.Money            `json:"total_material_cost"`
+	PreferredCost     models.Money            `json:"preferred_cost"`
+	NonPreferredCost  models.Money            `json:"non_preferred_cost"`
+	UnassignedCost    models.Money            `json:"unassigned_cost"`
+	PreferredShare    float64                 `json:"preferred_share"`
+	NonPreferredSpend []SupplierSpendResponse `json:"non_preferred_spend"`
+}
+
+type JobProfitabilityResponse struct {
+	JobID         uuid.UUID    `json:"job_id"`
+	Name          string       `json:"name"`
+	Quantity      float64      `json:"quantity"`
+	Cost          models.Money `json:"cost"`
+	SellingPrice  models.Money `json:"selling_price"`
+	MarginAmount  models.Money `json:"margin_amount"`
+	MarginPercent float64      `json:"margin_percentage"`
+	MarkupPercent float64      `json:"markup_percentage"`
+	Priced        bool         `json:"priced"`
+}
+
+type BOQProfitabilityResponse struct {
+	BOQID         uuid.UUID                  `json:"boq_id"`
+	Jobs          []JobProfitabilityResponse `json:"jobs"`
+	TotalCost     models.Money               `json:"total_cost"`
+	TotalSelling  models.Money               `json:"total_selling_price"`
+	MarginAmount  models.Money               `json:"margin_amount"`
+	MarginPercent float64                    `json:"margin_percentage"`
+	MarkupPercent float64                    `json:"markup_percentage"`
+}
+
+type JobDirectCostFloorResponse struct {
+	JobID             uuid.UUID    `json:"job_id"`
+	Name              string       `json:"name"`
+	Unit              string       `json:"unit"`
+	Quantity          float64      `json:"quantity"`
+	LaborCost         models.Money `json:"labor_cost"`
+	MaterialCost      models.Money `json:"material_cost"`
+	FloorPrice        models.Money `json:"floor_price"`
+	FloorUnitRate     models.Money `json:"floor_unit_rate"`
+	UnpricedMaterials int          `json:"unpriced_materials"`
+	Uncertain         bool         `json:"uncertain"`
+}
+
+type BOQDirectCostFloorResponse struct {
+	BOQID      uuid.UUID                    `json:"boq_id"`
+	Jobs       []JobDirectCostFloorResponse `json:"jobs"`
+	FloorPrice models.Money                 `json:"floor_price"`
+	Uncertain  bool                         `json:"uncertain"`
+}
+
+type RollupMaterialResponse struct {
+	MaterialID string       `json:"material_id"`
+	Name       string       `json:"name"`
+	Unit       string       `json:"unit"`
+	Quantity   float64      `json:"quantity"`
+	UnitPrice  models.Money `json:"unit_price"`
+	Cost       models.Money `json:"cost"`
+}
+
+type SupplierMaterialRollupResponse struct {
+	SupplierID   uuid.NullUUID            `json:"supplier_id"`
+	SupplierName string                   `json:"supplier_name"`
+	Materials    []RollupMaterialResponse `json:"materials"`
+	TotalCost    models.Money             `json:"total_cost"`
+}
+
+type ProvisionalItemResponse struct {
+	JobID uuid.UUID    `json:"job_id"`
+	Name  string       `json:"name"`
+	Total models.Money `json:"total"`
+}
+
+type ProvisionalShareResponse struct {
+	BOQID            uuid.UUID                 `json:"boq_id"`
+	Items            []ProvisionalItemResponse `json:"items"`
+	ProvisionalTotal models.Money              `json:"provisional_total"`
+	GrandTotal       models.Money              `json:"grand_total"`
+	SharePercent     float64                   `json:"share_percentage"`
+}
+
+type BOQDiffJobResponse struct {
+	JobID     uuid.UUID    `json:"job_id"`
+	Name      string       `json:"name"`
+	Quantity  float64      `json:"quantity"`
+	LaborCost models.Money `json:"labor_cost"`
+	Total     models.Money `json:"total"`
+}
+
+type BOQJobChangeResponse struct {
+	JobID        uuid.UUID    `json:"job_id"`
+	Name         string       `json:"name"`
+	OldQuantity  float64      `json:"old_quantity"`
+	NewQuantity  float64      `json:"new_quantity"`
+	OldLaborCost models.Money `json:"old_labor_cost"`
+	NewLaborCost models.Money `json:"new_labor_cost"`
+	TotalDelta   models.Money `json:"total_delta"`
+}
+
+type BOQDiffResponse struct {
+	BOQAID     uuid.UUID              `json:"boq_a_id"`
+	BOQBID     uuid.UUID              `json:"boq_b_id"`
+	OnlyInA    []BOQDiffJobResponse   `json:"only_in_a"`
+	OnlyInB    []BOQDiffJobResponse   `json:"only_in_b"`
+	Changed    []BOQJobChangeResponse `json:"changed"`
+	TotalDelta models.Money           `json:"total_delta"`
+}
+
+type PreliminariesResponse struct {
+	BOQID uuid.UUID `json:"boq_id"`
+	PreliminariesDTO
+}
+
+type BOQLifecycleEventResponse struct {
+	Type       string          `json:"type"`
+	Action     string          `json:"action"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty"`
+	Diff       json.RawMessage `json:"diff,omitempty"`
+	SnapshotID *uuid.UUID      `json:"snapshot_id,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// BOQIntegrityResponse compares the latest approval snapshot with the cost
+// recomputed from the current BOQ lines.
+type BOQIntegrityResponse struct {
+	SnapshotID    uuid.UUID    `json:"snapshot_id"`
+	SnapshotTotal models.Money `json:"snapshot_total"`
+	CurrentTotal  models.Money `json:"current_total"`
+	Difference    models.Money `json:"difference"`
+	Verified      bool         `json:"verified"`
+}
+
+// BOQAuditEntry is one recorded mutation of a BOQ.
+type BOQAuditEntry struct {
+	AuditID   uuid.UUID       `json:"audit_id" db:"audit_id"`
+	Action    string          `json:"action" db:"action"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty" db:"actor_id"`
+	Diff      json.RawMessage `json:"diff" db:"diff"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+type BOQLifecycleResponse struct {
+	BOQID     uuid.UUID                   `json:"boq_id"`
+	Status    models.BOQStatus            `json:"status"`
+	Events    []BOQLifecycleEventResponse `json:"events"`
+	Integrity *BOQIntegrityResponse       `json:"integrity,omitempty"`
+}
+
+type MaterialPriceLogResponse struct {
+	JobID        uuid.UUID        `json:"job_id" db:"job_id"`
+	JobName      string           `json:"job_name" db:"job_name"`
+	MaterialID   string           `json:"material_id" db:"material_id"`
+	MaterialName string           `json:"material_name" db:"material_name"`
+	Unit         string           `json:"unit" db:"unit"`
+	Quantity     float64          `json:"quantity" db:"quantity"`
+	UnitPrice    models.NullMoney `json:"unit_price" db:"unit_price"`
+	Total        models.Money     `json:"total" db:"total"`
+	UpdatedAt    *time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+type BOQTotalChangeResponse struct {
+	BOQID         uuid.UUID    `json:"boq_id" db:"boq_id"`
+	PreviousTotal models.Money `json:"previous_total" db:"previous_total"`
+	NewTotal      models.Money `json:"new_total" db:"new_total"`
+	Difference    models.Money `json:"difference"`
+}
+
+type RecalculateTotalsResponse struct {
+	ProjectID uuid.UUID                `json:"project_id"`
+	Updated   int                      `json:"updated"`
+	Changed   []BOQTotalChangeResponse `json:"changed"`
+}
+
+type BOQLaborShareResponse struct {
+	BOQID        uuid.UUID        `json:"boq_id" db:"boq_id"`
+	ProjectID    uuid.UUID        `json:"project_id" db:"project_id"`
+	ProjectName  string           `json:"project_name" db:"project_name"`
+	Status       models.BOQStatus `json:"status" db:"status"`
+	LaborCost    models.Money     `json:"labor_cost" db:"labor_cost"`
+	MaterialCost models.Money     `json:"material_cost" db:"material_cost"`
+	LaborShare   float64          `json:"labor_share" db:"labor_share"`
+}
+
+type BOQAttachmentResponse struct {
+	AttachmentID uuid.UUID                `json:"attachment_id"`
+	BOQID        uuid.UUID                `json:"boq_id"`
+	JobID        *uuid.UUID               `json:"job_id,omitempty"`
+	Type         models.BOQAttachmentType `json:"type"`
+	Reference    string                   `json:"reference"`
+	CreatedAt    time.Time                `json:"created_at"`
+}
+
+// BOQAttachments groups attachment references by the BOQ itself and by job.
+type BOQAttachments struct {
+	BOQ  []BOQAttachmentResponse               `json:"boq"`
+	Jobs map[uuid.UUID][]BOQAttachmentResponse `json:"jobs"`
+}
+
+type CashFlowPointResponse struct {
+	Month           string       `json:"month"`
+	Spend           models.Money `json:"spend"`
+	CumulativeSpend models.Money `json:"cumulative_spend"`
+}
+
+type CashFlowCurveResponse struct {
+	BOQID           uuid.UUID                      `json:"boq_id"`
+	StartDate       time.Time                      `json:"start_date"`
+	UnscheduledMode models.CashFlowUnscheduledMode `json:"unscheduled_mode"`
+	TotalCost       models.Money                   `json:"total_cost"`
+	UnscheduledJobs int                            `json:"unscheduled_jobs"`
+	Points          []CashFlowPointResponse        `json:"points"`
+}
+
+type NonCanonicalUnitResponse struct {
+	Source        string `json:"source" db:"source"`
+	ID            string `json:"id" db:"id"`
+	Name          string `json:"name" db:"name"`
+	Unit          string `json:"unit" db:"unit"`
+	CanonicalUnit string `json:"canonical_unit,omitempty"`
+	Mappable      bool   `json:"mappable"`
+}
+
+type NormalizeUnitsResponse struct {
+	JobsUpdated      int                        `json:"jobs_updated"`
+	MaterialsUpdated int                        `json:"materials_updated"`
+	Unmapped         []NonCanonicalUnitResponse `json:"unmapped"`
+}
+
+type FXCurrencyImpactResponse struct {
+	Currency      string       `json:"currency"`
+	ForeignCost   models.Money `json:"foreign_cost"`
+	CurrentCost   models.Money `json:"current_cost"`
+	ProjectedRate float64      `json:"projected_rate"`
+	ProjectedCost models.Money `json:"projected_cost"`
+	Delta         models.Money `json:"delta"`
+}
+
+type FXSensitivityResponse struct {
+	BOQID          uuid.UUID                  `json:"boq_id"`
+	BaseCurrency   string                     `json:"base_currency"`
+	CurrentTotal   models.Money               `json:"current_total"`
+	ProjectedTotal models.Money               `json:"projected_total"`
+	Delta          models.Money               `json:"delta"`
+	Currencies     []FXCurrencyImpactResponse `json:"currencies"`
+}
+
+type BOQJobCountResponse struct {
+	BOQID       uuid.UUID        `json:"boq_id" db:"boq_id"`
+	ProjectID   uuid.UUID        `json:"project_id" db:"project_id"`
+	ProjectName string           `json:"project_name" db:"project_name"`
+	Status      models.BOQStatus `json:"status" db:"status"`
+	JobCount    int              `json:"job_count" db:"job_count"`
+}
+
+type BOQMilestoneResponse struct {
+	MilestoneID   uuid.UUID     `json:"milestone_id"`
+	Name          string        `json:"name"`
+	Percentage    *float64      `json:"percentage,omitempty"`
+	FixedAmount   *models.Money `json:"fixed_amount,omitempty"`
+	TriggerDate   time.Time     `json:"trigger_date"`
+	AmountDue     models.Money  `json:"amount_due"`
+	CumulativeDue models.Money  `json:"cumulative_due"`
+}
+
+type InvoiceScheduleResponse struct {
+	BOQID      uuid.UUID              `json:"boq_id"`
+	GrandTotal models.Money           `json:"grand_total"`
+	Milestones []BOQMilestoneResponse `json:"milestones"`
+}
+
+type OrphanedPriceLogResponse struct {
+	MplID          uuid.UUID        `json:"mpl_id" db:"mpl_id"`
+	JobID          uuid.UUID        `json:"job_id" db:"job_id"`
+	JobName        string           `json:"job_name" db:"job_name"`
+	MaterialID     string           `json:"material_id" db:"material_id"`
+	MaterialName   string           `json:"material_name" db:"material_name"`
+	Quantity       float64          `json:"quantity" db:"quantity"`
+	EstimatedPrice models.NullMoney `json:"estimated_price" db:"estimated_price"`
+}
+
+type EstimateVarianceLineResponse struct {
+	ID              string       `json:"id"`
+	Name            string       `json:"name"`
+	Estimated       models.Money `json:"estimated"`
+	Actual          models.Money `json:"actual"`
+	Variance        models.Money `json:"variance"`
+	VariancePercent float64      `json:"variance_percent"`
+	AccuracyPercent float64      `json:"accuracy_percent"`
+	InEstimate      bool         `json:"in_estimate"`
+	InActuals       bool         `json:"in_actuals"`
+}
+
+type EstimateVarianceResponse struct {
+	Lines           []EstimateVarianceLineResponse `json:"lines"`
+	EstimatedTotal  models.Money                   `json:"estimated_total"`
+	ActualTotal     models.Money                   `json:"actual_total"`
+	Variance        models.Money                   `json:"variance"`
+	VariancePercent float64                        `json:"variance_percent"`
+	AccuracyPercent float64                        `json:"accuracy_percent"`
+	UnmatchedLines  int                            `json:"unmatched_lines"`
+}
+
+type EstimateVsActualResponse struct {
+	BOQID     uuid.UUID                 `json:"boq_id"`
+	Jobs      *EstimateVarianceResponse `json:"jobs,omitempty"`
+	Materials *EstimateVarianceResponse `json:"materials,omitempty"`
+}
+
+type PendingApprovalResponse struct {
+	ApprovalID  uuid.UUID    `json:"approval_id" db:"approval_id"`
+	BOQID       uuid.UUID    `json:"boq_id" db:"boq_id"`
+	ProjectID   uuid.UUID    `json:"project_id" db:"project_id"`
+	ProjectName string       `json:"project_name" db:"project_name"`
+	TotalCost   models.Money `json:"total_cost" db:"total_cost"`
+	Step        int          `json:"step" db:"step"`
+	SubmittedAt time.Time    `json:"submitted_at" db:"submitted_at"`
+}
+
+type JobRateRoundingResponse struct {
+	JobID       uuid.UUID    `json:"job_id"`
+	Name        string       `json:"name"`
+	Quantity    float64      `json:"quantity"`
+	UnitRate    models.Money `json:"unit_rate"`
+	RoundedRate models.Money `json:"rounded_rate"`
+	LineTotal   models.Money `json:"line_total"`
+	RoundedLine models.Money `json:"rounded_line_total"`
+	Delta       models.Money `json:"delta"`
+}
+
+type RateRoundingResponse struct {
+	BOQID             uuid.UUID                 `json:"boq_id"`
+	RoundTo           models.Money              `json:"round_to"`
+	Jobs              []JobRateRoundingResponse `json:"jobs"`
+	GrandTotal        models.Money              `json:"grand_total"`
+	RoundedGrandTotal models.Money              `json:"rounded_grand_total"`
+	Delta             models.Money              `json:"delta"`
+}
+
+type CostCompositionPointResponse struct {
+	BucketStart     time.Time `json:"bucket_start" db:"bucket_start"`
+	BOQCount        int       `json:"boq_count" db:"boq_count"`
+	LaborPercent    float64   `json:"labor_percentage" db:"labor_percent"`
+	MaterialPercent float64   `json:"material_percentage" db:"material_percent"`
+	OverheadPercent float64   `json:"overhead_percentage" db:"overhead_percent"`
+	// PreliminariesPercent is the preliminaries share of the grand total.
+	PreliminariesPercent float64 `json:"preliminaries_percentage" db:"preliminaries_percent"`
+}
+
+type BOQExportIssueResponse struct {
+	Code       string     `json:"code"`
+	Severity   string     `json:"severity"`
+	Message    string     `json:"message"`
+	JobID      *uuid.UUID `json:"job_id,omitempty"`
+	MaterialID string     `json:"material_id,omitempty"`
+}
+
+type BOQExportValidationResponse struct {
+	BOQID        uuid.UUID                `json:"boq_id"`
+	Exportable   bool                     `json:"exportable"`
+	ErrorCount   int                      `json:"error_count"`
+	WarningCount int                      `json:"warning_count"`
+	Issues       []BOQExportIssueResponse `json:"issues"`
+}
+
+type BOQJobContributionResponse struct {
+	JobID        uuid.UUID    `json:"job_id"`
+	Name         string       `json:"name"`
+	Contribution models.Money `json:"contribution"`
+	RunningTotal models.Money `json:"running_total"`
+}
+
+type BOQJobsImportResponse struct {
+	BOQID         uuid.UUID                    `json:"boq_id"`
+	StartingTotal models.Money                 `json:"starting_total"`
+	Jobs          []BOQJobContributionResponse `json:"jobs"`
+	FinalTotal    models.Money                 `json:"final_total"`
+}
+
+// BOQJobResponse is the boq_job line created by AddBOQJobResult.
+type BOQJobResponse struct {
+	BOQID            uuid.UUID    `json:"boq_id"`
+	JobID            uuid.UUID    `json:"job_id"`
+	Quantity         float64      `json:"quantity"`
+	LaborCost        models.Money `json:"labor_cost"`
+	PriceLogsCreated int          `json:"price_logs_created"`
+}
+
+type MixedCurrencyLineResponse struct {
+	MplID        uuid.UUID `json:"mpl_id" db:"mpl_id"`
+	JobID        uuid.UUID `json:"job_id" db:"job_id"`
+	JobName      string    `json:"job_name" db:"job_name"`
+	MaterialID   string    `json:"material_id" db:"material_id"`
+	MaterialName string    `json:"material_name" db:"material_name"`
+	Currency     string    `json:"currency" db:"currency"`
+	BOQCurrency  string    `json:"boq_currency" db:"boq_currency"`
+	FxRate       float64   `json:"fx_rate" db:"fx_rate"`
+	Converted    bool      `json:"converted" db:"converted"`
+}
+
+type BOQJobListResponse struct {
+	Jobs   []JobResponse `json:"jobs"`
+	Total  int64         `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+type MaterialTotalResponse struct {
+	MaterialID string  `json:"material_id" db:"material_id"`
+	Name       string  `json:"name" db:"name"`
+	Unit       string  `json:"unit" db:"unit"`
+	Quantity   float64 `json:"quantity" db:"quantity"`
+	// UnitPrice is the most recently recorded price, nil if never priced.
+	UnitPrice models.NullMoney `json:"unit_price" db:"unit_price"`
+	Total     models.Money     `json:"total" db:"-"`
 }
@@ -1,16 +1,20 @@
 package responses
 
 import (
+	"boonkosang/internal/domain/models"
+
 	"github.com/google/uuid"
 )
 
 type JobResponse struct {
-	JobID       uuid.UUID `json:"job_id" db:"job_id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	Unit        string    `json:"unit" db:"unit"`
-	Quantity    float64   `json:"quantity" db:"quantity"`
-	LaborCost   float64   `json:"labor_cost" db:"labor_cost"`
+	JobID       uuid.UUID    `json:"job_id" db:"job_id"`
+	Name        string       `json:"name" db:"name"`
+	Description string       `json:"description" db:"description"`
+	Unit        string       `json:"unit" db:"unit"`
+	Quantity    float64      `json:"quantity" db:"quantity"`
+	LaborCost   models.Money `json:"labor_cost" db:"labor_cost"`
+	// Materials is filled in by GetBoqWithProject only.
+	Materials []JobMaterialItem `json:"materials"`
 }
 
 type JobMaterialResponse struct {
@@ -33,3 +33,16 @@ type MaterialActualPriceResponse struct {
 	ActualPrice float64   `json:"actual_price"`
 	SupplierID  uuid.UUID `json:"supplier_id"`
 }
+
+type MaterialVolatilityResponse struct {
+	MaterialID             string  `json:"material_id"`
+	Name                   string  `json:"name"`
+	Unit                   string  `json:"unit"`
+	Observations           int     `json:"observations"`
+	BOQCount               int     `json:"boq_count"`
+	MinPrice               float64 `json:"min_price"`
+	MaxPrice               float64 `json:"max_price"`
+	AvgPrice               float64 `json:"avg_price"`
+	CoefficientOfVariation float64 `json:"coefficient_of_variation"`
+	SpreadPercent          float64 `json:"spread_percentage"`
+}
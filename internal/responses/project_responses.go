@@ -15,6 +15,8 @@ type ProjectResponse struct {
 	Address     json.RawMessage      `json:"address"`
 	Status      models.ProjectStatus `json:"status"`
 	ClientID    uuid.UUID            `json:"client_id"`
+	ProjectType string               `json:"project_type,omitempty"`
+	GFA         *float64             `json:"gross_floor_area,omitempty"`
 	Client      *ClientResponse      `json:"client,omitempty"`
 	CreatedAt   time.Time            `json:"created_at"`
 	UpdatedAt   time.Time            `json:"updated_at"`
@@ -76,3 +78,19 @@ type TotalStatsResponse struct {
 	CostVariance         float64 `json:"cost_variance"`
 	CostVariancePercent  float64 `json:"cost_variance_percentage"`
 }
+
+type BenchmarkRangeResponse struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+}
+
+type ProjectBenchmarksResponse struct {
+	ProjectType   string                  `json:"project_type"`
+	ProjectCount  int                     `json:"project_count"`
+	CostPerArea   *BenchmarkRangeResponse `json:"cost_per_area,omitempty"`
+	TotalCost     BenchmarkRangeResponse  `json:"total_cost"`
+	LaborShare    BenchmarkRangeResponse  `json:"labor_share"`
+	MaterialShare BenchmarkRangeResponse  `json:"material_share"`
+	OverheadShare BenchmarkRangeResponse  `json:"overhead_share"`
+}
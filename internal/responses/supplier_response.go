@@ -7,14 +7,21 @@ import (
 )
 
 type SupplierResponse struct {
-	ID      uuid.UUID       `json:"id"`
-	Name    string          `json:"name"`
-	Email   string          `json:"email"`
-	Tel     string          `json:"tel"`
-	Address json.RawMessage `json:"address"`
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Email       string          `json:"email"`
+	Tel         string          `json:"tel"`
+	Address     json.RawMessage `json:"address"`
+	IsPreferred bool            `json:"is_preferred"`
 }
 
 type SupplierListResponse struct {
 	Suppliers []SupplierResponse `json:"suppliers"`
 	Total     int64              `json:"total"`
 }
+
+type PriceListApplyResponse struct {
+	SupplierID  uuid.UUID `json:"supplier_id"`
+	RowsUpdated int       `json:"rows_updated"`
+	BOQsUpdated int       `json:"boqs_updated"`
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"boonkosang/internal/domain/models"
+	"boonkosang/internal/infrastructure/spreadsheet"
 	"boonkosang/internal/repositories"
 	"boonkosang/internal/requests"
 	"boonkosang/internal/responses"
@@ -9,17 +10,90 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type BOQUsecase interface {
-	Approve(ctx context.Context, boqID uuid.UUID) error
+	Approve(ctx context.Context, boqID uuid.UUID, req requests.ApproveBOQRequest, expectedVersion int) error
 	GetBoqWithProject(ctx context.Context, project_id uuid.UUID) (*responses.BOQResponse, error)
-	AddBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest) error
-	UpdateBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest) error
-	DeleteBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID) error
+	AddBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) error
+	AddBOQJobResult(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) (*responses.BOQJobResponse, error)
+	UpdateBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) error
+	DeleteBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error
 	GetBOQSummary(ctx context.Context, projectID uuid.UUID) (*responses.BOQSummaryResponse, error)
+
+	GetRequiredJobs(ctx context.Context, projectType string) ([]responses.RequiredJobResponse, error)
+	AddRequiredJob(ctx context.Context, projectType string, req requests.RequiredJobRequest) error
+	RemoveRequiredJob(ctx context.Context, projectType string, jobID uuid.UUID) error
+	ValidateRequiredJobs(ctx context.Context, boqID uuid.UUID) (*responses.RequiredJobCheckResponse, error)
+
+	GetPreferredSupplierShare(ctx context.Context, boqID uuid.UUID) (*responses.PreferredSupplierShareResponse, error)
+	GetJobProfitability(ctx context.Context, boqID uuid.UUID) (*responses.BOQProfitabilityResponse, error)
+	SetJobSellingPrice(ctx context.Context, boqID uuid.UUID, req requests.JobSellingPrice, expectedVersion int) error
+	RecalculateBOQTotal(ctx context.Context, boqID uuid.UUID) (models.Money, error)
+	RecalculateProjectBOQTotals(ctx context.Context, projectID uuid.UUID) (*responses.RecalculateTotalsResponse, error)
+	GetHighLaborShareBOQs(ctx context.Context, threshold float64) ([]responses.BOQLaborShareResponse, error)
+	AddBOQAttachment(ctx context.Context, boqID uuid.UUID, req requests.BOQAttachmentRequest) (*responses.BOQAttachmentResponse, error)
+	RemoveBOQAttachment(ctx context.Context, boqID uuid.UUID, attachmentID uuid.UUID) error
+	ListBOQAttachments(ctx context.Context, boqID uuid.UUID) ([]responses.BOQAttachmentResponse, error)
+	SetBOQJobSchedule(ctx context.Context, boqID uuid.UUID, req requests.BOQJobScheduleRequest) error
+	GetBOQCashFlowCurve(ctx context.Context, boqID uuid.UUID, req requests.CashFlowCurveRequest) (*responses.CashFlowCurveResponse, error)
+	GetNonCanonicalUnits(ctx context.Context) ([]responses.NonCanonicalUnitResponse, error)
+	NormalizeUnits(ctx context.Context) (*responses.NormalizeUnitsResponse, error)
+	SetMaterialPriceCurrency(ctx context.Context, boqID uuid.UUID, req requests.MaterialPriceCurrencyRequest) error
+	GetFXSensitivity(ctx context.Context, boqID uuid.UUID, req requests.FXSensitivityRequest) (*responses.FXSensitivityResponse, error)
+	GetOversizedBOQs(ctx context.Context, limit int) ([]responses.BOQJobCountResponse, error)
+	AddBOQMilestone(ctx context.Context, boqID uuid.UUID, req requests.BOQMilestoneRequest) (*responses.BOQMilestoneResponse, error)
+	RemoveBOQMilestone(ctx context.Context, boqID uuid.UUID, milestoneID uuid.UUID) error
+	GetInvoiceSchedule(ctx context.Context, boqID uuid.UUID) (*responses.InvoiceScheduleResponse, error)
+	GetOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error)
+	CleanOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error)
+	PruneOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) (int, error)
+	GetEstimateVsActual(ctx context.Context, boqID uuid.UUID, req requests.EstimateVsActualRequest) (*responses.EstimateVsActualResponse, error)
+	GetDirectCostFloor(ctx context.Context, boqID uuid.UUID) (*responses.BOQDirectCostFloorResponse, error)
+	SubmitForApproval(ctx context.Context, boqID uuid.UUID, req requests.SubmitBOQApprovalRequest) error
+	DecideApproval(ctx context.Context, boqID uuid.UUID, userID uuid.UUID, req requests.BOQApprovalDecisionRequest) error
+	GetPendingApprovalsForUser(ctx context.Context, userID uuid.UUID) ([]responses.PendingApprovalResponse, error)
+	PreviewRateRounding(ctx context.Context, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error)
+	ApplyRateRounding(ctx context.Context, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error)
+	GetLinesExceedingMarkupCap(ctx context.Context, boqID uuid.UUID, maxMarkupPercent float64) ([]responses.JobProfitabilityResponse, error)
+	GetSupplierMaterialRollup(ctx context.Context, boqID uuid.UUID) ([]responses.SupplierMaterialRollupResponse, error)
+	ExportPOWorkbook(ctx context.Context, boqID uuid.UUID) ([]byte, error)
+	GetCostCompositionTrend(ctx context.Context, from, to time.Time, bucket string) ([]responses.CostCompositionPointResponse, error)
+	SetJobProvisional(ctx context.Context, boqID uuid.UUID, req requests.JobProvisionalRequest, expectedVersion int) error
+	GetProvisionalShare(ctx context.Context, boqID uuid.UUID) (*responses.ProvisionalShareResponse, error)
+	CompareBOQs(ctx context.Context, aID, bID uuid.UUID) (*responses.BOQDiffResponse, error)
+	CloneBOQScaled(ctx context.Context, sourceBOQID uuid.UUID, req requests.CloneBOQScaledRequest) (uuid.UUID, error)
+	ValidateBOQForExport(ctx context.Context, boqID uuid.UUID) (*responses.BOQExportValidationResponse, error)
+	ExportBOQ(ctx context.Context, projectID uuid.UUID) (*responses.BOQSummaryResponse, error)
+	AddBOQJobs(ctx context.Context, boqID uuid.UUID, reqs []requests.BOQJobRequest, expectedVersion int) (*responses.BOQJobsImportResponse, error)
+	GetMixedCurrencyLines(ctx context.Context, boqID uuid.UUID) ([]responses.MixedCurrencyLineResponse, error)
+	SetPreliminariesPercent(ctx context.Context, boqID uuid.UUID, req requests.PreliminariesRequest) error
+	GetPreliminaries(ctx context.Context, boqID uuid.UUID) (*responses.PreliminariesResponse, error)
+	GetBOQLifecycle(ctx context.Context, boqID uuid.UUID) (*responses.BOQLifecycleResponse, error)
+	UpdateBOQStatus(ctx context.Context, boqID uuid.UUID, newStatus models.BOQStatus, expectedVersion int) error
+	UpdateMaterialPrice(ctx context.Context, boqID uuid.UUID, req requests.MaterialPriceRequest, expectedVersion int) error
+	GetMaterialPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.MaterialPriceLogResponse, error)
+	GetBOQCostSummary(ctx context.Context, boqID uuid.UUID) (*responses.BOQSummaryResponse, error)
+	RestoreBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error
+	PurgeBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error
+	ClearBOQJobs(ctx context.Context, boqID uuid.UUID, expectedVersion int) (int, error)
+	CreateBOQForProject(ctx context.Context, projectID uuid.UUID, opts requests.CreateBOQOptions) (*responses.BOQResponse, error)
+	ExportBOQCSV(ctx context.Context, boqID uuid.UUID) ([]byte, error)
+	ExportBOQCSVStream(ctx context.Context, boqID uuid.UUID, w io.Writer) error
+	ListBOQJobs(ctx context.Context, boqID uuid.UUID, req requests.ListBOQJobsRequest) (*responses.BOQJobListResponse, error)
+	CloneBOQ(ctx context.Context, sourceBOQID uuid.UUID, req requests.CloneBOQRequest) (uuid.UUID, error)
+	UpdateBOQJobQuantity(ctx context.Context, boqID, jobID uuid.UUID, req requests.UpdateBOQJobQuantityRequest, expectedVersion int) error
+	LockBOQ(ctx context.Context, boqID uuid.UUID) error
+	GetBOQMaterialTotals(ctx context.Context, boqID uuid.UUID) ([]responses.MaterialTotalResponse, error)
+	ListBOQs(ctx context.Context, req requests.ListBOQRequest) ([]responses.BOQListItemResponse, error)
+	ApplyGeneralCostPercentage(ctx context.Context, boqID uuid.UUID, req requests.GeneralCostPercentageRequest) (float64, error)
+	MoveBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, req requests.MoveBOQJobRequest) error
+	GetBOQAudit(ctx context.Context, boqID uuid.UUID) ([]responses.BOQAuditEntry, error)
 }
 
 type boqUsecase struct {
@@ -34,23 +108,30 @@ func NewBOQUsecase(boqRepo repositories.BOQRepository, projectRepo repositories.
 	}
 }
 
-func (u *boqUsecase) Approve(ctx context.Context, boqID uuid.UUID) error {
-	return u.boqRepo.Approve(ctx, boqID)
+func (u *boqUsecase) Approve(ctx context.Context, boqID uuid.UUID, req requests.ApproveBOQRequest, expectedVersion int) error {
+	return u.boqRepo.Approve(ctx, boqID, req, expectedVersion)
 }
+
+// GetBoqWithProject returns the project's BOQ, or ErrBOQNotFound when it has
+// none yet. BOQs are only created through CreateBOQForProject.
 func (u *boqUsecase) GetBoqWithProject(ctx context.Context, project_id uuid.UUID) (*responses.BOQResponse, error) {
 	return u.boqRepo.GetBoqWithProject(ctx, project_id)
 }
 
-func (u *boqUsecase) AddBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest) error {
-	return u.boqRepo.AddBOQJob(ctx, boqID, req)
+func (u *boqUsecase) AddBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) error {
+	return u.boqRepo.AddBOQJob(ctx, boqID, req, expectedVersion)
+}
+
+func (u *boqUsecase) AddBOQJobResult(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) (*responses.BOQJobResponse, error) {
+	return u.boqRepo.AddBOQJobResult(ctx, boqID, req, expectedVersion)
 }
 
-func (u *boqUsecase) UpdateBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest) error {
-	return u.boqRepo.UpdateBOQJob(ctx, boqID, req)
+func (u *boqUsecase) UpdateBOQJob(ctx context.Context, boqID uuid.UUID, req requests.BOQJobRequest, expectedVersion int) error {
+	return u.boqRepo.UpdateBOQJob(ctx, boqID, req, expectedVersion)
 }
 
-func (u *boqUsecase) DeleteBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID) error {
-	return u.boqRepo.DeleteBOQJob(ctx, boqID, jobID)
+func (u *boqUsecase) DeleteBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error {
+	return u.boqRepo.DeleteBOQJob(ctx, boqID, jobID, expectedVersion)
 }
 
 func (u *boqUsecase) GetBOQSummary(ctx context.Context, projectID uuid.UUID) (*responses.BOQSummaryResponse, error) {
@@ -80,7 +161,354 @@ func (u *boqUsecase) GetBOQSummary(ctx context.Context, projectID uuid.UUID) (*r
 	}
 
 	// Transform data to DTOs
-	return transformToResponse(details[0], generalCosts, details, materials), nil
+	response := transformToResponse(details[0], generalCosts, details, materials)
+	if boq.PreliminariesPercent.Valid {
+		applyPreliminaries(response, boq.PreliminariesPercent.Float64)
+	}
+
+	return response, nil
+}
+
+func (u *boqUsecase) GetRequiredJobs(ctx context.Context, projectType string) ([]responses.RequiredJobResponse, error) {
+	return u.boqRepo.GetRequiredJobs(ctx, projectType)
+}
+
+func (u *boqUsecase) AddRequiredJob(ctx context.Context, projectType string, req requests.RequiredJobRequest) error {
+	return u.boqRepo.AddRequiredJob(ctx, projectType, req.JobID)
+}
+
+func (u *boqUsecase) RemoveRequiredJob(ctx context.Context, projectType string, jobID uuid.UUID) error {
+	return u.boqRepo.RemoveRequiredJob(ctx, projectType, jobID)
+}
+
+func (u *boqUsecase) ValidateRequiredJobs(ctx context.Context, boqID uuid.UUID) (*responses.RequiredJobCheckResponse, error) {
+	return u.boqRepo.ValidateRequiredJobs(ctx, boqID)
+}
+
+func (u *boqUsecase) GetPreferredSupplierShare(ctx context.Context, boqID uuid.UUID) (*responses.PreferredSupplierShareResponse, error) {
+	return u.boqRepo.GetPreferredSupplierShare(ctx, boqID)
+}
+
+func (u *boqUsecase) GetJobProfitability(ctx context.Context, boqID uuid.UUID) (*responses.BOQProfitabilityResponse, error) {
+	return u.boqRepo.GetJobProfitability(ctx, boqID)
+}
+
+func (u *boqUsecase) SetJobSellingPrice(ctx context.Context, boqID uuid.UUID, req requests.JobSellingPrice, expectedVersion int) error {
+	return u.boqRepo.SetJobSellingPrice(ctx, boqID, req, expectedVersion)
+}
+
+func (u *boqUsecase) RecalculateBOQTotal(ctx context.Context, boqID uuid.UUID) (models.Money, error) {
+	return u.boqRepo.RecalculateBOQTotal(ctx, boqID)
+}
+
+func (u *boqUsecase) RecalculateProjectBOQTotals(ctx context.Context, projectID uuid.UUID) (*responses.RecalculateTotalsResponse, error) {
+	return u.boqRepo.RecalculateProjectBOQTotals(ctx, projectID)
+}
+
+func (u *boqUsecase) GetHighLaborShareBOQs(ctx context.Context, threshold float64) ([]responses.BOQLaborShareResponse, error) {
+	return u.boqRepo.GetHighLaborShareBOQs(ctx, threshold)
+}
+
+func (u *boqUsecase) AddBOQAttachment(ctx context.Context, boqID uuid.UUID, req requests.BOQAttachmentRequest) (*responses.BOQAttachmentResponse, error) {
+	return u.boqRepo.AddBOQAttachment(ctx, boqID, req)
+}
+
+func (u *boqUsecase) RemoveBOQAttachment(ctx context.Context, boqID uuid.UUID, attachmentID uuid.UUID) error {
+	return u.boqRepo.RemoveBOQAttachment(ctx, boqID, attachmentID)
+}
+
+func (u *boqUsecase) ListBOQAttachments(ctx context.Context, boqID uuid.UUID) ([]responses.BOQAttachmentResponse, error) {
+	return u.boqRepo.ListBOQAttachments(ctx, boqID)
+}
+
+func (u *boqUsecase) SetBOQJobSchedule(ctx context.Context, boqID uuid.UUID, req requests.BOQJobScheduleRequest) error {
+	return u.boqRepo.SetBOQJobSchedule(ctx, boqID, req)
+}
+
+func (u *boqUsecase) GetBOQCashFlowCurve(ctx context.Context, boqID uuid.UUID, req requests.CashFlowCurveRequest) (*responses.CashFlowCurveResponse, error) {
+	return u.boqRepo.GetBOQCashFlowCurve(ctx, boqID, req)
+}
+
+func (u *boqUsecase) GetNonCanonicalUnits(ctx context.Context) ([]responses.NonCanonicalUnitResponse, error) {
+	return u.boqRepo.GetNonCanonicalUnits(ctx)
+}
+
+func (u *boqUsecase) NormalizeUnits(ctx context.Context) (*responses.NormalizeUnitsResponse, error) {
+	return u.boqRepo.NormalizeUnits(ctx)
+}
+
+func (u *boqUsecase) SetMaterialPriceCurrency(ctx context.Context, boqID uuid.UUID, req requests.MaterialPriceCurrencyRequest) error {
+	return u.boqRepo.SetMaterialPriceCurrency(ctx, boqID, req)
+}
+
+func (u *boqUsecase) GetFXSensitivity(ctx context.Context, boqID uuid.UUID, req requests.FXSensitivityRequest) (*responses.FXSensitivityResponse, error) {
+	return u.boqRepo.GetFXSensitivity(ctx, boqID, req.Rates)
+}
+
+func (u *boqUsecase) GetOversizedBOQs(ctx context.Context, limit int) ([]responses.BOQJobCountResponse, error) {
+	return u.boqRepo.GetOversizedBOQs(ctx, limit)
+}
+
+func (u *boqUsecase) AddBOQMilestone(ctx context.Context, boqID uuid.UUID, req requests.BOQMilestoneRequest) (*responses.BOQMilestoneResponse, error) {
+	return u.boqRepo.AddBOQMilestone(ctx, boqID, req)
+}
+
+func (u *boqUsecase) RemoveBOQMilestone(ctx context.Context, boqID uuid.UUID, milestoneID uuid.UUID) error {
+	return u.boqRepo.RemoveBOQMilestone(ctx, boqID, milestoneID)
+}
+
+func (u *boqUsecase) GetInvoiceSchedule(ctx context.Context, boqID uuid.UUID) (*responses.InvoiceScheduleResponse, error) {
+	return u.boqRepo.GetInvoiceSchedule(ctx, boqID)
+}
+
+func (u *boqUsecase) GetOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error) {
+	return u.boqRepo.GetOrphanedPriceLogs(ctx, boqID)
+}
+
+func (u *boqUsecase) CleanOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.OrphanedPriceLogResponse, error) {
+	return u.boqRepo.CleanOrphanedPriceLogs(ctx, boqID)
+}
+
+func (u *boqUsecase) PruneOrphanedPriceLogs(ctx context.Context, boqID uuid.UUID) (int, error) {
+	return u.boqRepo.PruneOrphanedPriceLogs(ctx, boqID)
+}
+
+func (u *boqUsecase) GetEstimateVsActual(ctx context.Context, boqID uuid.UUID, req requests.EstimateVsActualRequest) (*responses.EstimateVsActualResponse, error) {
+	return u.boqRepo.GetEstimateVsActual(ctx, boqID, req)
+}
+
+func (u *boqUsecase) GetDirectCostFloor(ctx context.Context, boqID uuid.UUID) (*responses.BOQDirectCostFloorResponse, error) {
+	return u.boqRepo.GetDirectCostFloor(ctx, boqID)
+}
+
+func (u *boqUsecase) SubmitForApproval(ctx context.Context, boqID uuid.UUID, req requests.SubmitBOQApprovalRequest) error {
+	return u.boqRepo.SubmitForApproval(ctx, boqID, req)
+}
+
+func (u *boqUsecase) DecideApproval(ctx context.Context, boqID uuid.UUID, userID uuid.UUID, req requests.BOQApprovalDecisionRequest) error {
+	return u.boqRepo.DecideApproval(ctx, boqID, userID, req)
+}
+
+func (u *boqUsecase) GetPendingApprovalsForUser(ctx context.Context, userID uuid.UUID) ([]responses.PendingApprovalResponse, error) {
+	return u.boqRepo.GetPendingApprovalsForUser(ctx, userID)
+}
+
+func (u *boqUsecase) PreviewRateRounding(ctx context.Context, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error) {
+	return u.boqRepo.PreviewRateRounding(ctx, boqID, roundTo)
+}
+
+func (u *boqUsecase) ApplyRateRounding(ctx context.Context, boqID uuid.UUID, roundTo float64) (*responses.RateRoundingResponse, error) {
+	return u.boqRepo.ApplyRateRounding(ctx, boqID, roundTo)
+}
+
+func (u *boqUsecase) GetLinesExceedingMarkupCap(ctx context.Context, boqID uuid.UUID, maxMarkupPercent float64) ([]responses.JobProfitabilityResponse, error) {
+	return u.boqRepo.GetLinesExceedingMarkupCap(ctx, boqID, maxMarkupPercent)
+}
+
+func (u *boqUsecase) GetSupplierMaterialRollup(ctx context.Context, boqID uuid.UUID) ([]responses.SupplierMaterialRollupResponse, error) {
+	return u.boqRepo.GetSupplierMaterialRollup(ctx, boqID)
+}
+
+func (u *boqUsecase) ExportPOWorkbook(ctx context.Context, boqID uuid.UUID) ([]byte, error) {
+	if err := u.requireExportable(ctx, boqID); err != nil {
+		return nil, err
+	}
+
+	rollup, err := u.boqRepo.GetSupplierMaterialRollup(ctx, boqID)
+	if err != nil {
+		return nil, err
+	}
+
+	workbook := spreadsheet.NewWorkbook()
+	for _, supplier := range rollup {
+		name := supplier.SupplierName
+		if !supplier.SupplierID.Valid {
+			name = "Unassigned"
+		}
+
+		rows := [][]interface{}{
+			{"Material ID", "Material", "Unit", "Quantity", "Unit Price", "Cost"},
+		}
+		for _, material := range supplier.Materials {
+			rows = append(rows, []interface{}{
+				material.MaterialID,
+				material.Name,
+				material.Unit,
+				material.Quantity,
+				material.UnitPrice,
+				material.Cost,
+			})
+		}
+		rows = append(rows, []interface{}{nil, nil, nil, nil, "Total", supplier.TotalCost})
+
+		workbook.AddSheet(name, rows)
+	}
+
+	if len(rollup) == 0 {
+		workbook.AddSheet("Unassigned", [][]interface{}{
+			{"Material ID", "Material", "Unit", "Quantity", "Unit Price", "Cost"},
+		})
+	}
+
+	return workbook.Bytes()
+}
+
+func (u *boqUsecase) GetCostCompositionTrend(ctx context.Context, from, to time.Time, bucket string) ([]responses.CostCompositionPointResponse, error) {
+	return u.boqRepo.GetCostCompositionTrend(ctx, from, to, bucket)
+}
+
+func (u *boqUsecase) SetJobProvisional(ctx context.Context, boqID uuid.UUID, req requests.JobProvisionalRequest, expectedVersion int) error {
+	return u.boqRepo.SetJobProvisional(ctx, boqID, req, expectedVersion)
+}
+
+func (u *boqUsecase) GetProvisionalShare(ctx context.Context, boqID uuid.UUID) (*responses.ProvisionalShareResponse, error) {
+	return u.boqRepo.GetProvisionalShare(ctx, boqID)
+}
+
+func (u *boqUsecase) CompareBOQs(ctx context.Context, aID, bID uuid.UUID) (*responses.BOQDiffResponse, error) {
+	return u.boqRepo.CompareBOQs(ctx, aID, bID)
+}
+
+func (u *boqUsecase) CloneBOQScaled(ctx context.Context, sourceBOQID uuid.UUID, req requests.CloneBOQScaledRequest) (uuid.UUID, error) {
+	return u.boqRepo.CloneBOQScaled(ctx, sourceBOQID, req.TargetProjectID, req.Factor, req.ResetPrices)
+}
+
+func (u *boqUsecase) ValidateBOQForExport(ctx context.Context, boqID uuid.UUID) (*responses.BOQExportValidationResponse, error) {
+	return u.boqRepo.ValidateBOQForExport(ctx, boqID)
+}
+
+func (u *boqUsecase) ExportBOQ(ctx context.Context, projectID uuid.UUID) (*responses.BOQSummaryResponse, error) {
+	boq, err := u.boqRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting BOQ: %w", err)
+	}
+
+	if err := u.requireExportable(ctx, boq.BOQID); err != nil {
+		return nil, err
+	}
+
+	return u.GetBOQSummary(ctx, projectID)
+}
+
+// requireExportable fails with ErrBOQNotExportable when the BOQ has
+// consistency errors. Warnings do not block exports.
+func (u *boqUsecase) requireExportable(ctx context.Context, boqID uuid.UUID) error {
+	validation, err := u.boqRepo.ValidateBOQForExport(ctx, boqID)
+	if err != nil {
+		return err
+	}
+
+	if !validation.Exportable {
+		messages := make([]string, 0, validation.ErrorCount)
+		for _, issue := range validation.Issues {
+			if issue.Severity == "error" {
+				messages = append(messages, issue.Message)
+			}
+		}
+		return fmt.Errorf("%w: %s", repositories.ErrBOQNotExportable, strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func (u *boqUsecase) AddBOQJobs(ctx context.Context, boqID uuid.UUID, reqs []requests.BOQJobRequest, expectedVersion int) (*responses.BOQJobsImportResponse, error) {
+	return u.boqRepo.AddBOQJobs(ctx, boqID, reqs, expectedVersion)
+}
+
+func (u *boqUsecase) GetMixedCurrencyLines(ctx context.Context, boqID uuid.UUID) ([]responses.MixedCurrencyLineResponse, error) {
+	return u.boqRepo.GetMixedCurrencyLines(ctx, boqID)
+}
+
+func (u *boqUsecase) SetPreliminariesPercent(ctx context.Context, boqID uuid.UUID, req requests.PreliminariesRequest) error {
+	return u.boqRepo.SetPreliminariesPercent(ctx, boqID, req)
+}
+
+func (u *boqUsecase) GetPreliminaries(ctx context.Context, boqID uuid.UUID) (*responses.PreliminariesResponse, error) {
+	return u.boqRepo.GetPreliminaries(ctx, boqID)
+}
+
+func (u *boqUsecase) GetBOQLifecycle(ctx context.Context, boqID uuid.UUID) (*responses.BOQLifecycleResponse, error) {
+	return u.boqRepo.GetBOQLifecycle(ctx, boqID)
+}
+
+func (u *boqUsecase) UpdateBOQStatus(ctx context.Context, boqID uuid.UUID, newStatus models.BOQStatus, expectedVersion int) error {
+	return u.boqRepo.UpdateBOQStatus(ctx, boqID, newStatus, expectedVersion)
+}
+
+func (u *boqUsecase) UpdateMaterialPrice(ctx context.Context, boqID uuid.UUID, req requests.MaterialPriceRequest, expectedVersion int) error {
+	return u.boqRepo.UpdateMaterialPrice(ctx, boqID, req.JobID, req.MaterialID, models.MoneyFromFloat(req.Price), expectedVersion)
+}
+
+func (u *boqUsecase) GetMaterialPriceLogs(ctx context.Context, boqID uuid.UUID) ([]responses.MaterialPriceLogResponse, error) {
+	return u.boqRepo.GetMaterialPriceLogs(ctx, boqID)
+}
+
+func (u *boqUsecase) GetBOQCostSummary(ctx context.Context, boqID uuid.UUID) (*responses.BOQSummaryResponse, error) {
+	return u.boqRepo.GetBOQSummary(ctx, boqID)
+}
+
+func (u *boqUsecase) RestoreBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error {
+	return u.boqRepo.RestoreBOQJob(ctx, boqID, jobID, expectedVersion)
+}
+
+func (u *boqUsecase) PurgeBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, expectedVersion int) error {
+	return u.boqRepo.PurgeBOQJob(ctx, boqID, jobID, expectedVersion)
+}
+
+func (u *boqUsecase) ClearBOQJobs(ctx context.Context, boqID uuid.UUID, expectedVersion int) (int, error) {
+	return u.boqRepo.ClearBOQJobs(ctx, boqID, expectedVersion)
+}
+
+func (u *boqUsecase) CreateBOQForProject(ctx context.Context, projectID uuid.UUID, opts requests.CreateBOQOptions) (*responses.BOQResponse, error) {
+	if _, err := u.boqRepo.CreateBOQForProject(ctx, projectID, opts); err != nil {
+		return nil, err
+	}
+
+	return u.boqRepo.GetBoqWithProject(ctx, projectID)
+}
+
+func (u *boqUsecase) ExportBOQCSV(ctx context.Context, boqID uuid.UUID) ([]byte, error) {
+	return u.boqRepo.ExportBOQ(ctx, boqID)
+}
+
+func (u *boqUsecase) ExportBOQCSVStream(ctx context.Context, boqID uuid.UUID, w io.Writer) error {
+	return u.boqRepo.ExportBOQStream(ctx, boqID, w)
+}
+
+func (u *boqUsecase) ListBOQJobs(ctx context.Context, boqID uuid.UUID, req requests.ListBOQJobsRequest) (*responses.BOQJobListResponse, error) {
+	return u.boqRepo.ListBOQJobs(ctx, boqID, req)
+}
+
+func (u *boqUsecase) CloneBOQ(ctx context.Context, sourceBOQID uuid.UUID, req requests.CloneBOQRequest) (uuid.UUID, error) {
+	return u.boqRepo.CloneBOQ(ctx, sourceBOQID, req.TargetProjectID, req.Overwrite)
+}
+
+func (u *boqUsecase) UpdateBOQJobQuantity(ctx context.Context, boqID, jobID uuid.UUID, req requests.UpdateBOQJobQuantityRequest, expectedVersion int) error {
+	return u.boqRepo.UpdateBOQJobQuantity(ctx, boqID, jobID, req.Quantity, req.LaborCost, expectedVersion)
+}
+
+func (u *boqUsecase) LockBOQ(ctx context.Context, boqID uuid.UUID) error {
+	return u.boqRepo.LockBOQ(ctx, boqID)
+}
+
+func (u *boqUsecase) GetBOQMaterialTotals(ctx context.Context, boqID uuid.UUID) ([]responses.MaterialTotalResponse, error) {
+	return u.boqRepo.GetBOQMaterialTotals(ctx, boqID)
+}
+
+func (u *boqUsecase) ListBOQs(ctx context.Context, req requests.ListBOQRequest) ([]responses.BOQListItemResponse, error) {
+	return u.boqRepo.ListBOQs(ctx, req)
+}
+
+func (u *boqUsecase) ApplyGeneralCostPercentage(ctx context.Context, boqID uuid.UUID, req requests.GeneralCostPercentageRequest) (float64, error) {
+	return u.boqRepo.ApplyGeneralCostPercentage(ctx, boqID, req.Percent)
+}
+
+func (u *boqUsecase) MoveBOQJob(ctx context.Context, boqID uuid.UUID, jobID uuid.UUID, req requests.MoveBOQJobRequest) error {
+	return u.boqRepo.MoveBOQJob(ctx, boqID, req.TargetBOQID, jobID)
+}
+
+func (u *boqUsecase) GetBOQAudit(ctx context.Context, boqID uuid.UUID) ([]responses.BOQAuditEntry, error) {
+	return u.boqRepo.GetBOQAudit(ctx, boqID)
 }
 
 func transformGeneralCosts(costs []models.BOQGeneralCost) []responses.GeneralCostDTO {
@@ -110,6 +538,21 @@ func transformToResponse(firstDetail models.BOQDetails, generalCosts []models.BO
 	return response
 }
 
+// applyPreliminaries adds the preliminaries section derived from the
+// direct-works (labor + material) subtotal and includes it in the grand total.
+func applyPreliminaries(response *responses.BOQSummaryResponse, percent float64) {
+	subtotal := response.SummaryMetrics.TotalLaborCost + response.SummaryMetrics.TotalMaterialCost
+	amount := subtotal.Percent(percent)
+
+	response.Preliminaries = &responses.PreliminariesDTO{
+		Percent:             percent,
+		DirectWorksSubtotal: subtotal,
+		Amount:              amount,
+	}
+	response.SummaryMetrics.TotalPreliminaries = amount
+	response.SummaryMetrics.GrandTotal += amount
+}
+
 func transformBOQDetailsWithMaterials(details []models.BOQDetails, materials []models.BOQMaterialDetails) []responses.BOQDetailDTO {
 	// Create a map to group materials by JobID
 	materialsByJob := make(map[uuid.UUID][]models.BOQMaterialDetails)
@@ -119,8 +562,8 @@ func transformBOQDetailsWithMaterials(details []models.BOQDetails, materials []m
 
 	dtos := make([]responses.BOQDetailDTO, len(details))
 	for i, detail := range details {
-		totalEstimatedPrice := detail.EstimatedPrice.Float64 * float64(detail.Quantity)
-		totalLaborCost := detail.LaborCost * float64(detail.Quantity)
+		totalEstimatedPrice := detail.EstimatedPrice.Money.MulQuantity(detail.Quantity.Float64)
+		totalLaborCost := detail.LaborCost.Money.MulQuantity(detail.Quantity.Float64)
 
 		// Transform materials for this job
 		jobMaterials := transformMaterials(materialsByJob[detail.JobID])
@@ -129,14 +572,15 @@ func transformBOQDetailsWithMaterials(details []models.BOQDetails, materials []m
 			JobID:               detail.JobID,
 			JobName:             detail.JobName,
 			Description:         detail.Description.String,
-			Quantity:            detail.Quantity,
+			Quantity:            detail.Quantity.Float64,
 			Unit:                detail.Unit,
-			LaborCost:           detail.LaborCost,
-			EstimatedPrice:      detail.EstimatedPrice.Float64,
+			LaborCost:           detail.LaborCost.Money,
+			EstimatedPrice:      detail.EstimatedPrice.Money,
 			TotalEstimatedPrice: totalEstimatedPrice,
 			TotalLaborCost:      totalLaborCost,
-			Total:               detail.Total.Float64,
+			Total:               detail.Total.Money,
 			Materials:           jobMaterials,
+			Incomplete:          !detail.Quantity.Valid || !detail.LaborCost.Valid,
 		}
 	}
 	return dtos
@@ -150,7 +594,7 @@ func transformMaterials(materials []models.BOQMaterialDetails) []responses.Mater
 	dtos := make([]responses.MaterialDTO, len(materials))
 	for i, material := range materials {
 		quantity := material.Quantity.Float64
-		estimatedPrice := material.EstimatedPrice.Float64
+		estimatedPrice := material.EstimatedPrice.Money
 
 		dtos[i] = responses.MaterialDTO{
 			JobID:          material.JobID,
@@ -159,7 +603,7 @@ func transformMaterials(materials []models.BOQMaterialDetails) []responses.Mater
 			Quantity:       quantity,
 			Unit:           material.Unit,
 			EstimatedPrice: estimatedPrice,
-			Total:          material.Total.Float64,
+			Total:          material.Total.Money,
 		}
 	}
 	return dtos
@@ -176,6 +620,9 @@ func calculateSummaryMetrics(generalCosts []responses.GeneralCostDTO, details []
 		metrics.TotalLaborCost += detail.TotalLaborCost
 		metrics.TotalEstimatedPrice += detail.TotalEstimatedPrice
 		metrics.TotalAmount += detail.Total
+		if detail.Incomplete {
+			metrics.IncompleteJobs++
+		}
 
 		// Calculate material costs for this job
 		for _, material := range detail.Materials {
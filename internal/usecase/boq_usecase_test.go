@@ -0,0 +1,67 @@
+package usecase_test
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/requests"
+	"boonkosang/internal/responses"
+	"boonkosang/internal/usecase"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubBOQRepository implements only the BOQ repository methods a test uses;
+// calling any other method panics on the nil embedded interface.
+type stubBOQRepository struct {
+	repositories.BOQRepository
+	boq     *responses.BOQResponse
+	err     error
+	created int
+
+	validation *responses.BOQExportValidationResponse
+}
+
+func (s *stubBOQRepository) GetBoqWithProject(ctx context.Context, projectID uuid.UUID) (*responses.BOQResponse, error) {
+	return s.boq, s.err
+}
+
+func (s *stubBOQRepository) CreateBOQForProject(ctx context.Context, projectID uuid.UUID, opts requests.CreateBOQOptions) (*models.BOQ, error) {
+	s.created++
+	return &models.BOQ{ProjectID: projectID}, nil
+}
+
+func (s *stubBOQRepository) ValidateBOQForExport(ctx context.Context, boqID uuid.UUID) (*responses.BOQExportValidationResponse, error) {
+	return s.validation, nil
+}
+
+func TestBOQUsecaseGetBoqWithProjectDoesNotCreate(t *testing.T) {
+	repo := &stubBOQRepository{err: repositories.ErrBOQNotFound}
+	uc := usecase.NewBOQUsecase(repo, nil)
+
+	boq, err := uc.GetBoqWithProject(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, repositories.ErrBOQNotFound)
+	assert.Nil(t, boq)
+	assert.Zero(t, repo.created)
+}
+
+func TestBOQUsecaseExportPOWorkbookRequiresExportable(t *testing.T) {
+	repo := &stubBOQRepository{validation: &responses.BOQExportValidationResponse{
+		Issues: []responses.BOQExportIssueResponse{
+			{Severity: "warning", Message: "material CEM-01 of job Wall has no estimated price"},
+			{Severity: "error", Message: "material X-99 of job Wall is not in the catalog"},
+		},
+		ErrorCount:   1,
+		WarningCount: 1,
+	}}
+	uc := usecase.NewBOQUsecase(repo, nil)
+
+	// The rollup is never fetched: the stub would panic if it were.
+	workbook, err := uc.ExportPOWorkbook(context.Background(), uuid.New())
+	assert.Nil(t, workbook)
+	assert.ErrorIs(t, err, repositories.ErrBOQNotExportable)
+	assert.Contains(t, err.Error(), "X-99")
+	assert.NotContains(t, err.Error(), "CEM-01")
+}
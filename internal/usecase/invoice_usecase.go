@@ -59,7 +59,7 @@ func (u *invoiceUseCase) GetProjectInvoices(ctx context.Context, projectID uuid.
 		return nil, fmt.Errorf("failed to get project invoices: %w", err)
 	}
 
-	var responseList []responses.InvoiceResponse
+	responseList := []responses.InvoiceResponse{}
 	for _, invoice := range invoices {
 		response := responses.InvoiceResponse{
 			InvoiceID: invoice.InvoiceID,
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"boonkosang/internal/domain/models"
 	"boonkosang/internal/repositories"
 	"boonkosang/internal/requests"
 	"boonkosang/internal/responses"
@@ -34,6 +35,12 @@ func NewJobUseCase(jobRepo repositories.JobRepository) JobUseCase {
 }
 
 func (u *jobUseCase) Create(ctx context.Context, req requests.CreateJobRequest) (*responses.JobResponse, error) {
+	unit, ok := models.NormalizeUnit(req.Unit)
+	if !ok {
+		return nil, fmt.Errorf("unit %q is not a recognised unit", req.Unit)
+	}
+	req.Unit = unit
+
 	job, err := u.jobRepo.Create(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create job: %w", err)
@@ -50,6 +57,12 @@ func (u *jobUseCase) Update(ctx context.Context, id uuid.UUID, req requests.Upda
 		return errors.New("job not found")
 	}
 
+	unit, ok := models.NormalizeUnit(req.Unit)
+	if !ok {
+		return fmt.Errorf("unit %q is not a recognised unit", req.Unit)
+	}
+	req.Unit = unit
+
 	return u.jobRepo.Update(ctx, id, req)
 }
 
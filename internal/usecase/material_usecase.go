@@ -9,6 +9,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -23,6 +24,7 @@ type MaterialUsecase interface {
 	GetMaterialPrices(ctx context.Context, projectID uuid.UUID) (*responses.MaterialPriceListResponse, error)
 	UpdateEstimatedPrice(ctx context.Context, boqID uuid.UUID, req requests.UpdateMaterialEstimatedPriceRequest) error
 	UpdateActualPrice(ctx context.Context, boqID uuid.UUID, req requests.UpdateMaterialActualPriceRequest) error
+	GetVolatileMaterials(ctx context.Context, from, to time.Time, limit int) ([]responses.MaterialVolatilityResponse, error)
 }
 
 type materialUsecase struct {
@@ -41,6 +43,11 @@ func NewMaterialUsecase(
 }
 
 func (u *materialUsecase) Create(ctx context.Context, req requests.CreateMaterialRequest) (*responses.MaterialResponse, error) {
+	unit, ok := models.NormalizeUnit(req.Unit)
+	if !ok {
+		return nil, fmt.Errorf("unit %q is not a recognised unit", req.Unit)
+	}
+	req.Unit = unit
 
 	material, err := u.materialRepo.Create(ctx, req)
 	if err != nil {
@@ -59,6 +66,12 @@ func (u *materialUsecase) Update(ctx context.Context, materialID string, req req
 		return errors.New("material not found")
 	}
 
+	unit, ok := models.NormalizeUnit(req.Unit)
+	if !ok {
+		return fmt.Errorf("unit %q is not a recognised unit", req.Unit)
+	}
+	req.Unit = unit
+
 	return u.materialRepo.Update(ctx, materialID, req)
 }
 
@@ -127,7 +140,7 @@ func (u *materialUsecase) GetMaterialPrices(ctx context.Context, projectID uuid.
 		return nil, err
 	}
 
-	var response []responses.MaterialPriceDetail
+	response := []responses.MaterialPriceDetail{}
 
 	for _, m := range materials {
 		detail := responses.MaterialPriceDetail{
@@ -207,3 +220,36 @@ func (u *materialUsecase) UpdateActualPrice(ctx context.Context, boqID uuid.UUID
 
 	return u.materialRepo.UpdateActualPrice(ctx, boqID, req)
 }
+
+func (u *materialUsecase) GetVolatileMaterials(ctx context.Context, from, to time.Time, limit int) ([]responses.MaterialVolatilityResponse, error) {
+	if !to.After(from) {
+		return nil, errors.New("to must be after from")
+	}
+
+	if limit < 1 {
+		limit = 10
+	}
+
+	materials, err := u.materialRepo.GetMaterialVolatility(ctx, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]responses.MaterialVolatilityResponse, len(materials))
+	for i, m := range materials {
+		response[i] = responses.MaterialVolatilityResponse{
+			MaterialID:             m.MaterialID,
+			Name:                   m.Name,
+			Unit:                   m.Unit,
+			Observations:           m.Observations,
+			BOQCount:               m.BOQCount,
+			MinPrice:               m.MinPrice,
+			MaxPrice:               m.MaxPrice,
+			AvgPrice:               m.AvgPrice,
+			CoefficientOfVariation: m.StdDev / m.AvgPrice,
+			SpreadPercent:          (m.MaxPrice - m.MinPrice) / m.AvgPrice * 100,
+		}
+	}
+
+	return response, nil
+}
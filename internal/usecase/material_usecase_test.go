@@ -0,0 +1,58 @@
+package usecase_test
+
+import (
+	"boonkosang/internal/domain/models"
+	"boonkosang/internal/repositories"
+	"boonkosang/internal/usecase"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMaterialRepository implements only the material repository methods a
+// test uses; calling any other method panics on the nil embedded interface.
+type stubMaterialRepository struct {
+	repositories.MaterialRepository
+	volatility []models.MaterialVolatility
+	limit      int
+}
+
+func (s *stubMaterialRepository) GetMaterialVolatility(ctx context.Context, from, to time.Time, limit int) ([]models.MaterialVolatility, error) {
+	s.limit = limit
+	return s.volatility, nil
+}
+
+func TestMaterialUsecaseGetVolatileMaterials(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("derives the spread and coefficient of variation", func(t *testing.T) {
+		repo := &stubMaterialRepository{volatility: []models.MaterialVolatility{{
+			MaterialID:   "CEM-01",
+			Name:         "Cement",
+			Observations: 4,
+			MinPrice:     90,
+			MaxPrice:     110,
+			AvgPrice:     100,
+			StdDev:       8,
+		}}}
+		uc := usecase.NewMaterialUsecase(repo, nil)
+
+		materials, err := uc.GetVolatileMaterials(context.Background(), from, to, 0)
+		require.NoError(t, err)
+		require.Len(t, materials, 1)
+		assert.InDelta(t, 0.08, materials[0].CoefficientOfVariation, 0.0001)
+		assert.InDelta(t, 20.0, materials[0].SpreadPercent, 0.0001)
+		assert.Equal(t, 10, repo.limit)
+	})
+
+	t.Run("rejects an empty range", func(t *testing.T) {
+		uc := usecase.NewMaterialUsecase(&stubMaterialRepository{}, nil)
+
+		_, err := uc.GetVolatileMaterials(context.Background(), to, from, 5)
+		assert.Error(t, err)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/google/uuid"
 )
@@ -26,6 +27,7 @@ type ProjectUsecase interface {
 	GetProjectOverview(ctx context.Context, projectID uuid.UUID) (*responses.ProjectOverviewResponse, error)
 
 	GetProjectSummary(ctx context.Context, projectID uuid.UUID) (*responses.ProjectSummaryResponse, error)
+	GetBenchmarks(ctx context.Context, projectType string) (*responses.ProjectBenchmarksResponse, error)
 }
 
 type projectUsecase struct {
@@ -58,6 +60,8 @@ func (u *projectUsecase) Create(ctx context.Context, req requests.CreateProjectR
 		Address:     project.Address,
 		Status:      project.Status,
 		ClientID:    project.ClientID,
+		ProjectType: project.ProjectType.String,
+		GFA:         nullFloat64Ptr(project.GFA),
 		Client: &responses.ClientResponse{
 			ID:      client.ClientID,
 			Name:    client.Name,
@@ -97,6 +101,8 @@ func (u *projectUsecase) GetByID(ctx context.Context, id uuid.UUID) (*responses.
 		Address:     project.Address,
 		Status:      project.Status,
 		ClientID:    project.ClientID,
+		ProjectType: project.ProjectType.String,
+		GFA:         nullFloat64Ptr(project.GFA),
 		Client: &responses.ClientResponse{
 			ID:      client.ClientID,
 			Name:    client.Name,
@@ -236,7 +242,7 @@ func (u *projectUsecase) GetProjectSummary(ctx context.Context, projectID uuid.U
 	}
 
 	// Process job summaries
-	var jobResponses []responses.JobSummaryResponse
+	jobResponses := []responses.JobSummaryResponse{}
 	var totalStats responses.TotalStatsResponse
 
 	for _, job := range summary.Jobs {
@@ -306,3 +312,69 @@ func (u *projectUsecase) GetProjectSummary(ctx context.Context, projectID uuid.U
 		TotalStats:  totalStats,
 	}, nil
 }
+
+// GetBenchmarks summarizes the benchmark ratios of completed projects of the
+// given type as min/avg/max ranges.
+func (u *projectUsecase) GetBenchmarks(ctx context.Context, projectType string) (*responses.ProjectBenchmarksResponse, error) {
+	if projectType == "" {
+		return nil, errors.New("project type is required")
+	}
+
+	benchmarks, err := u.projectRepo.GetBenchmarks(ctx, projectType)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &responses.ProjectBenchmarksResponse{
+		ProjectType:  projectType,
+		ProjectCount: len(benchmarks),
+	}
+	if len(benchmarks) == 0 {
+		return response, nil
+	}
+
+	var costPerArea []float64
+	totals := make([]float64, len(benchmarks))
+	labor := make([]float64, len(benchmarks))
+	material := make([]float64, len(benchmarks))
+	overhead := make([]float64, len(benchmarks))
+	for i, b := range benchmarks {
+		totals[i] = b.TotalCost
+		labor[i] = b.LaborShare
+		material[i] = b.MaterialShare
+		overhead[i] = b.OverheadShare
+		if b.CostPerArea.Valid {
+			costPerArea = append(costPerArea, b.CostPerArea.Float64)
+		}
+	}
+
+	response.TotalCost = benchmarkRange(totals)
+	response.LaborShare = benchmarkRange(labor)
+	response.MaterialShare = benchmarkRange(material)
+	response.OverheadShare = benchmarkRange(overhead)
+	if len(costPerArea) > 0 {
+		r := benchmarkRange(costPerArea)
+		response.CostPerArea = &r
+	}
+
+	return response, nil
+}
+
+func benchmarkRange(values []float64) responses.BenchmarkRangeResponse {
+	r := responses.BenchmarkRangeResponse{Min: values[0], Max: values[0]}
+	var sum float64
+	for _, v := range values {
+		r.Min = math.Min(r.Min, v)
+		r.Max = math.Max(r.Max, v)
+		sum += v
+	}
+	r.Avg = sum / float64(len(values))
+	return r
+}
+
+func nullFloat64Ptr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
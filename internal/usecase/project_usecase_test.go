@@ -0,0 +1,57 @@
+package usecase_test
+
+import (
+	"boonkosang/internal/domain/models"
+	mocks "boonkosang/internal/repositories/mock"
+	"boonkosang/internal/usecase"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectUsecaseGetBenchmarks(t *testing.T) {
+	t.Run("summarizes ratios as ranges", func(t *testing.T) {
+		repo := new(mocks.MockProjectRepository)
+		repo.On("GetBenchmarks", mock.Anything, "house").Return([]models.ProjectBenchmark{
+			{TotalCost: 1000, LaborShare: 0.4, MaterialShare: 0.5, OverheadShare: 0.1,
+				CostPerArea: sql.NullFloat64{Float64: 10, Valid: true}},
+			{TotalCost: 3000, LaborShare: 0.2, MaterialShare: 0.7, OverheadShare: 0.1},
+		}, nil)
+		uc := usecase.NewProjectUsecase(repo, nil)
+
+		benchmarks, err := uc.GetBenchmarks(context.Background(), "house")
+		require.NoError(t, err)
+		assert.Equal(t, 2, benchmarks.ProjectCount)
+		assert.Equal(t, 1000.0, benchmarks.TotalCost.Min)
+		assert.Equal(t, 2000.0, benchmarks.TotalCost.Avg)
+		assert.Equal(t, 3000.0, benchmarks.TotalCost.Max)
+		assert.InDelta(t, 0.3, benchmarks.LaborShare.Avg, 0.0001)
+
+		// Only projects with a floor area count towards the cost per area.
+		require.NotNil(t, benchmarks.CostPerArea)
+		assert.Equal(t, 10.0, benchmarks.CostPerArea.Avg)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("returns an empty summary without benchmarks", func(t *testing.T) {
+		repo := new(mocks.MockProjectRepository)
+		repo.On("GetBenchmarks", mock.Anything, "tower").Return([]models.ProjectBenchmark{}, nil)
+		uc := usecase.NewProjectUsecase(repo, nil)
+
+		benchmarks, err := uc.GetBenchmarks(context.Background(), "tower")
+		require.NoError(t, err)
+		assert.Zero(t, benchmarks.ProjectCount)
+		assert.Nil(t, benchmarks.CostPerArea)
+	})
+
+	t.Run("requires a project type", func(t *testing.T) {
+		uc := usecase.NewProjectUsecase(new(mocks.MockProjectRepository), nil)
+
+		_, err := uc.GetBenchmarks(context.Background(), "")
+		assert.Error(t, err)
+	})
+}
@@ -16,6 +16,7 @@ type SupplierUsecase interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByID(ctx context.Context, id uuid.UUID) (*responses.SupplierResponse, error)
 	List(ctx context.Context, page, pageSize int) (*responses.SupplierListResponse, error)
+	ApplyPriceListToOpenBOQs(ctx context.Context, supplierID uuid.UUID, req requests.SupplierPriceListRequest) (*responses.PriceListApplyResponse, error)
 }
 
 type supplierUsecase struct {
@@ -40,11 +41,12 @@ func (u *supplierUsecase) Create(ctx context.Context, req requests.CreateSupplie
 	}
 
 	return &responses.SupplierResponse{
-		ID:      supplier.SupplierID,
-		Name:    supplier.Name,
-		Email:   supplier.Email,
-		Tel:     supplier.Tel,
-		Address: supplier.Address,
+		ID:          supplier.SupplierID,
+		Name:        supplier.Name,
+		Email:       supplier.Email,
+		Tel:         supplier.Tel,
+		Address:     supplier.Address,
+		IsPreferred: supplier.IsPreferred,
 	}, nil
 }
 
@@ -75,11 +77,12 @@ func (u *supplierUsecase) GetByID(ctx context.Context, id uuid.UUID) (*responses
 	}
 
 	return &responses.SupplierResponse{
-		ID:      supplier.SupplierID,
-		Name:    supplier.Name,
-		Email:   supplier.Email,
-		Tel:     supplier.Tel,
-		Address: supplier.Address,
+		ID:          supplier.SupplierID,
+		Name:        supplier.Name,
+		Email:       supplier.Email,
+		Tel:         supplier.Tel,
+		Address:     supplier.Address,
+		IsPreferred: supplier.IsPreferred,
 	}, nil
 }
 
@@ -101,11 +104,12 @@ func (u *supplierUsecase) List(ctx context.Context, page, pageSize int) (*respon
 	supplierResponses := make([]responses.SupplierResponse, len(suppliers))
 	for i, supplier := range suppliers {
 		supplierResponses[i] = responses.SupplierResponse{
-			ID:      supplier.SupplierID,
-			Name:    supplier.Name,
-			Email:   supplier.Email,
-			Tel:     supplier.Tel,
-			Address: supplier.Address,
+			ID:          supplier.SupplierID,
+			Name:        supplier.Name,
+			Email:       supplier.Email,
+			Tel:         supplier.Tel,
+			Address:     supplier.Address,
+			IsPreferred: supplier.IsPreferred,
 		}
 	}
 
@@ -114,3 +118,7 @@ func (u *supplierUsecase) List(ctx context.Context, page, pageSize int) (*respon
 		Total:     total,
 	}, nil
 }
+
+func (u *supplierUsecase) ApplyPriceListToOpenBOQs(ctx context.Context, supplierID uuid.UUID, req requests.SupplierPriceListRequest) (*responses.PriceListApplyResponse, error) {
+	return u.supplierRepo.ApplyPriceListToOpenBOQs(ctx, supplierID, req)
+}
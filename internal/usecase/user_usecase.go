@@ -37,6 +37,7 @@ func (uu *userUsecase) generateToken(user *models.User) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id":  user.UserID,
 		"username": user.Username,
+		"role":     user.Role,
 		"exp":      time.Now().Add(uu.jwtDuration).Unix(),
 	})
 
@@ -0,0 +1,8 @@
+-- Project types and the jobs every BOQ of that type must contain.
+ALTER TABLE project ADD COLUMN IF NOT EXISTS project_type VARCHAR(100);
+
+CREATE TABLE IF NOT EXISTS project_type_required_job (
+    project_type VARCHAR(100) NOT NULL,
+    job_id       UUID         NOT NULL REFERENCES job (job_id) ON DELETE CASCADE,
+    PRIMARY KEY (project_type, job_id)
+);
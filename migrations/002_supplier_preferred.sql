@@ -0,0 +1,2 @@
+-- Preferred suppliers are tracked for procurement compliance.
+ALTER TABLE supplier ADD COLUMN IF NOT EXISTS is_preferred BOOLEAN NOT NULL DEFAULT FALSE;
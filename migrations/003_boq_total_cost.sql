@@ -0,0 +1,2 @@
+-- Cached BOQ grand total (labor + materials + general costs) for dashboards.
+ALTER TABLE boq ADD COLUMN IF NOT EXISTS total_cost NUMERIC(15, 2);
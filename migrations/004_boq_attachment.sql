@@ -0,0 +1,11 @@
+-- References (URLs, document ids) to supporting files for a BOQ or one of its jobs.
+CREATE TABLE IF NOT EXISTS boq_attachment (
+    attachment_id   UUID         PRIMARY KEY,
+    boq_id          UUID         NOT NULL REFERENCES boq (boq_id) ON DELETE CASCADE,
+    job_id          UUID         REFERENCES job (job_id) ON DELETE CASCADE,
+    attachment_type VARCHAR(50)  NOT NULL,
+    reference       TEXT         NOT NULL,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_boq_attachment_boq_id ON boq_attachment (boq_id);
@@ -0,0 +1,3 @@
+-- Optional planned phasing for a BOQ job, relative to the project start.
+ALTER TABLE boq_job ADD COLUMN IF NOT EXISTS start_offset_days INTEGER;
+ALTER TABLE boq_job ADD COLUMN IF NOT EXISTS duration_days INTEGER;
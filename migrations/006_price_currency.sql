@@ -0,0 +1,5 @@
+-- Per-price currency support. Prices are converted into the BOQ currency
+-- with the rate captured when the price was entered.
+ALTER TABLE boq ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'THB';
+ALTER TABLE material_price_log ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'THB';
+ALTER TABLE material_price_log ADD COLUMN IF NOT EXISTS fx_rate NUMERIC(18, 8) NOT NULL DEFAULT 1;
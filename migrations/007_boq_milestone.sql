@@ -0,0 +1,10 @@
+-- Payment milestones mapping portions of a BOQ total to billing dates.
+CREATE TABLE IF NOT EXISTS boq_milestone (
+    milestone_id UUID           PRIMARY KEY,
+    boq_id       UUID           NOT NULL REFERENCES boq (boq_id) ON DELETE CASCADE,
+    name         VARCHAR(255)   NOT NULL,
+    percentage   NUMERIC(5, 2),
+    fixed_amount NUMERIC(15, 2),
+    trigger_date DATE           NOT NULL,
+    CHECK ((percentage IS NULL) <> (fixed_amount IS NULL))
+);
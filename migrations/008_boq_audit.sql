@@ -0,0 +1,11 @@
+-- Audit trail of BOQ mutations.
+CREATE TABLE IF NOT EXISTS boq_audit (
+    audit_id   UUID         PRIMARY KEY,
+    boq_id     UUID         NOT NULL REFERENCES boq (boq_id) ON DELETE CASCADE,
+    action     VARCHAR(50)  NOT NULL,
+    actor_id   UUID,
+    diff       JSONB        NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_boq_audit_boq_id ON boq_audit (boq_id, created_at);
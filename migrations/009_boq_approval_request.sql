@@ -0,0 +1,15 @@
+-- Ordered approval chain for a BOQ. The row with the lowest pending step is
+-- the one awaiting action.
+CREATE TABLE IF NOT EXISTS boq_approval_request (
+    approval_id  UUID         PRIMARY KEY,
+    boq_id       UUID         NOT NULL REFERENCES boq (boq_id) ON DELETE CASCADE,
+    approver_id  UUID         NOT NULL REFERENCES "User" (user_id),
+    step         INTEGER      NOT NULL,
+    status       VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    comment      TEXT,
+    submitted_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    decided_at   TIMESTAMPTZ,
+    UNIQUE (boq_id, step)
+);
+
+CREATE INDEX IF NOT EXISTS idx_boq_approval_request_approver ON boq_approval_request (approver_id, status);
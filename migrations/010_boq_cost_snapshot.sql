@@ -0,0 +1,12 @@
+-- Cost breakdown frozen when a BOQ is approved, for stable historical reporting.
+CREATE TABLE IF NOT EXISTS boq_cost_snapshot (
+    snapshot_id   UUID           PRIMARY KEY,
+    boq_id        UUID           NOT NULL REFERENCES boq (boq_id) ON DELETE CASCADE,
+    labor_cost    NUMERIC(15, 2) NOT NULL,
+    material_cost NUMERIC(15, 2) NOT NULL,
+    overhead_cost NUMERIC(15, 2) NOT NULL,
+    total_cost    NUMERIC(15, 2) NOT NULL,
+    approved_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_boq_cost_snapshot_approved_at ON boq_cost_snapshot (approved_at);
@@ -0,0 +1,2 @@
+-- Provisional sums: allowances priced now but settled against actual work.
+ALTER TABLE boq_job ADD COLUMN IF NOT EXISTS is_provisional BOOLEAN NOT NULL DEFAULT FALSE;
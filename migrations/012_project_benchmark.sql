@@ -0,0 +1,19 @@
+-- Gross floor area for cost-per-area ratios.
+ALTER TABLE project ADD COLUMN IF NOT EXISTS gross_floor_area NUMERIC(12, 2);
+
+-- Key BOQ ratios of completed projects, used as estimating benchmarks.
+CREATE TABLE IF NOT EXISTS project_benchmark (
+    project_id       UUID           PRIMARY KEY REFERENCES project (project_id) ON DELETE CASCADE,
+    boq_id           UUID           NOT NULL REFERENCES boq (boq_id) ON DELETE CASCADE,
+    project_type     VARCHAR(100),
+    gross_floor_area NUMERIC(12, 2),
+    total_cost       NUMERIC(15, 2) NOT NULL,
+    cost_per_area    NUMERIC(15, 2),
+    labor_share      NUMERIC(7, 4)  NOT NULL,
+    material_share   NUMERIC(7, 4)  NOT NULL,
+    overhead_share   NUMERIC(7, 4)  NOT NULL,
+    job_count        INTEGER        NOT NULL,
+    recorded_at      TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_benchmark_type ON project_benchmark (project_type);
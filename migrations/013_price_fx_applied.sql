@@ -0,0 +1,2 @@
+-- Whether a price's fx_rate was set explicitly rather than left at the default.
+ALTER TABLE material_price_log ADD COLUMN IF NOT EXISTS fx_applied BOOLEAN NOT NULL DEFAULT FALSE;
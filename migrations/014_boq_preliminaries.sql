@@ -0,0 +1,2 @@
+-- Preliminaries charged as a percentage of the direct-works subtotal.
+ALTER TABLE boq ADD COLUMN IF NOT EXISTS preliminaries_percent NUMERIC(5,2);
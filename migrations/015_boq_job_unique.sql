@@ -0,0 +1,19 @@
+-- A job appears at most once per BOQ. Each duplicate boq_job row also logged
+-- the job's materials again, so those price-log rows are removed first,
+-- keeping the most recently priced row per material, before the duplicate
+-- boq_job rows are deleted and the constraint is added.
+DELETE FROM material_price_log a
+USING material_price_log b
+WHERE a.boq_id = b.boq_id
+AND a.job_id = b.job_id
+AND a.material_id = b.material_id
+AND (COALESCE(a.updated_at, '-infinity'), a.ctid) < (COALESCE(b.updated_at, '-infinity'), b.ctid);
+
+-- The first boq_job row of each job is kept and the later ones deleted.
+DELETE FROM boq_job a
+USING boq_job b
+WHERE a.boq_id = b.boq_id
+AND a.job_id = b.job_id
+AND a.ctid > b.ctid;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_boq_job_boq_id_job_id ON boq_job (boq_id, job_id);
@@ -0,0 +1,2 @@
+-- Removed BOQ jobs are kept, with their price logs, until restored.
+ALTER TABLE boq_job ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
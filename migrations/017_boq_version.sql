@@ -0,0 +1,2 @@
+-- Incremented by every checked BOQ edit so clients can detect lost updates.
+ALTER TABLE boq ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
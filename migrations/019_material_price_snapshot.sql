@@ -0,0 +1,12 @@
+-- Material prices frozen when a BOQ is approved, so later price changes do not
+-- alter the summary of an approved or locked BOQ.
+CREATE TABLE IF NOT EXISTS material_price_snapshot (
+    boq_id          UUID           NOT NULL REFERENCES boq (boq_id) ON DELETE CASCADE,
+    job_id          UUID           NOT NULL REFERENCES job (job_id) ON DELETE CASCADE,
+    material_id     TEXT           NOT NULL,
+    quantity        NUMERIC,
+    estimated_price NUMERIC,
+    fx_rate         NUMERIC(18, 8) NOT NULL DEFAULT 1,
+    snapshot_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (boq_id, snapshot_at, job_id, material_id)
+);
@@ -0,0 +1,2 @@
+-- General cost new BOQs of the project start with when none is given.
+ALTER TABLE project ADD COLUMN IF NOT EXISTS default_general_cost NUMERIC CHECK (default_general_cost >= 0);
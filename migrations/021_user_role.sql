@@ -0,0 +1,2 @@
+-- Role carried in the login token; admins may bypass BOQ guardrails.
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
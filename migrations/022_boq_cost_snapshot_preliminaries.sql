@@ -0,0 +1,2 @@
+-- Preliminaries frozen with the approval snapshot; total_cost is the grand total.
+ALTER TABLE boq_cost_snapshot ADD COLUMN IF NOT EXISTS preliminaries_cost NUMERIC(15, 2) NOT NULL DEFAULT 0;